@@ -1,13 +1,16 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
+	"multilang-learner/internal/anki"
+	"multilang-learner/internal/models"
 	"multilang-learner/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -15,39 +18,114 @@ import (
 
 // ===== 檔案管理 Handlers =====
 
-func createListFilesHandler(fs *services.FileService) gin.HandlerFunc {
+func createListFilesHandler(fs *services.FileService, cs *services.CollectionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		files, err := fs.List()
+		query := services.ListQuery{
+			Search:   c.Query("q"),
+			Status:   c.Query("status"),
+			Language: c.Query("language"),
+			Tag:      c.Query("tag"),
+			Sort:     c.Query("sort"),
+			Order:    c.Query("order"),
+			Cursor:   c.Query("cursor"),
+		}
+		if collectionID := c.Query("collection"); collectionID != "" {
+			collection, err := cs.Get(collectionID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			query.FileIDs = append([]string{}, collection.FileIDs...)
+		}
+		if limit := c.Query("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 limit"})
+				return
+			}
+			query.Limit = n
+		}
+
+		result, err := fs.List(query)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"files": files})
+		c.JSON(http.StatusOK, result)
 	}
 }
 
-func createUploadHandler(fs *services.FileService) gin.HandlerFunc {
+func createUploadHandler(fs *services.FileService, maxSize int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		file, header, err := c.Request.FormFile("file")
+		// 重複時的處理方式：link、replace、keep，未指定則回傳 409
+		policy, err := services.ParseDuplicatePolicy(c.Query("onDuplicate"))
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "無法讀取上傳檔案"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		defer file.Close()
 
-		data, err := io.ReadAll(file)
+		// 預留 1MB 給 multipart 標頭與其他欄位
+		if maxSize > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+		}
+
+		reader, err := c.Request.MultipartReader()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "無法讀取檔案內容"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無法讀取上傳檔案"})
 			return
 		}
 
-		result, err := fs.Upload(header.Filename, data)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// 直接串流 file 欄位，不整檔讀入記憶體
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				writeUploadError(c, err)
+				return
+			}
+			if part.FormName() != "file" {
+				part.Close()
+				continue
+			}
+
+			file, err := fs.UploadStream(part.FileName(), part, maxSize, policy)
+			part.Close()
+			if err != nil {
+				writeUploadError(c, err)
+				return
+			}
+			c.JSON(http.StatusOK, file)
 			return
 		}
 
-		c.JSON(http.StatusOK, result)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "無法讀取上傳檔案"})
+	}
+}
+
+// writeUploadError 依錯誤類型回傳對應的狀態碼
+func writeUploadError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	var unsupported *services.UnsupportedFormatError
+	var duplicate *services.DuplicateFileError
+	switch {
+	case errors.Is(err, services.ErrFileTooLarge), errors.As(err, &maxBytesErr):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": services.ErrFileTooLarge.Error()})
+	case errors.Is(err, services.ErrFileBusy):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &unsupported):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": unsupported.Error()})
+	case errors.As(err, &duplicate):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      duplicate.Error(),
+			"existingId": duplicate.Match.Existing.ID,
+			"existing":   duplicate.Match.Existing.ToListItem(),
+			"reason":     duplicate.Match.Reason,
+			"score":      duplicate.Match.Score,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
 
@@ -63,13 +141,18 @@ func createGetFileHandler(fs *services.FileService) gin.HandlerFunc {
 	}
 }
 
-func createDeleteFileHandler(fs *services.FileService) gin.HandlerFunc {
+func createDeleteFileHandler(fs *services.FileService, cs *services.CollectionService, es *services.ExportService, rs *services.ReviewService, xs *services.ExerciseService, vs *services.VocabularyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		if err := fs.Delete(id); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		cs.RemoveFileEverywhere(id)
+		es.RemoveFile(id)
+		rs.RemoveFile(id)
+		xs.RemoveFile(id)
+		vs.RemoveFile(id)
 		c.JSON(http.StatusOK, gin.H{"message": "刪除成功"})
 	}
 }
@@ -90,6 +173,228 @@ func createUpdateSettingsHandler(fs *services.FileService) gin.HandlerFunc {
 	}
 }
 
+func createMarkPracticedHandler(fs *services.FileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if err := fs.MarkPracticed(id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "已記錄練習時間"})
+	}
+}
+
+func createGetCoverHandler(fs *services.FileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		coverPath, err := fs.GetCoverPath(id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.File(coverPath)
+	}
+}
+
+// ===== 批次匯入 Handlers =====
+
+func createStartImportHandler(is *services.ImportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := is.StartScan(); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "匯入已開始"})
+	}
+}
+
+func createImportStatusHandler(is *services.ImportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, is.Status())
+	}
+}
+
+// ===== 標籤與集合 Handlers =====
+
+func createSetTagsHandler(fs *services.FileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var req struct {
+			Tags []string `json:"tags"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的標籤資料"})
+			return
+		}
+		tags, err := fs.SetTags(id, req.Tags)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"tags": tags})
+	}
+}
+
+func createListTagsHandler(fs *services.FileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tags": fs.ListTags()})
+	}
+}
+
+type collectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func createListCollectionsHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"collections": cs.List()})
+	}
+}
+
+func createCreateCollectionHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req collectionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的集合資料"})
+			return
+		}
+		collection, err := cs.Create(req.Name, req.Description)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, collection)
+	}
+}
+
+func createGetCollectionHandler(fs *services.FileService, cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		collection, err := cs.Get(c.Param("cid"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		files, err := fs.List(services.ListQuery{FileIDs: append([]string{}, collection.FileIDs...), Sort: "title"})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"collection": collection, "files": files.Files})
+	}
+}
+
+func createUpdateCollectionHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req collectionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的集合資料"})
+			return
+		}
+		collection, err := cs.Update(c.Param("cid"), req.Name, req.Description)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, collection)
+	}
+}
+
+func createDeleteCollectionHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := cs.Delete(c.Param("cid")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "刪除成功"})
+	}
+}
+
+func createAddCollectionFilesHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			FileIDs []string `json:"fileIds"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || len(req.FileIDs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "請提供 fileIds"})
+			return
+		}
+		collection, err := cs.AddFiles(c.Param("cid"), req.FileIDs)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, collection)
+	}
+}
+
+func createRemoveCollectionFileHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		collection, err := cs.RemoveFile(c.Param("cid"), c.Param("fileId"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, collection)
+	}
+}
+
+func createProcessCollectionHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started, failed, err := cs.ProcessCollection(c.Param("cid"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"started": started, "failed": failed})
+	}
+}
+
+// createExportCollectionHandler 開始在背景導出集合，回傳工作狀態
+func createExportCollectionHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := cs.StartExport(c.Param("cid"))
+		switch {
+		case errors.Is(err, services.ErrCollectionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrEmptyCollection), errors.Is(err, services.ErrCollectionExportBusy):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusAccepted, job)
+		}
+	}
+}
+
+// createGetCollectionExportHandler 集合最近一次導出工作的狀態
+func createGetCollectionExportHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := cs.GetExport(c.Param("cid"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+func createDownloadCollectionExportHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("cid")
+		exportPath, err := cs.GetExportPath(id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		name := id
+		if collection, err := cs.Get(id); err == nil {
+			name = collection.Name
+		}
+		c.FileAttachment(exportPath, name+".zip")
+	}
+}
+
 // ===== 歌詞 Handlers =====
 
 func createGetLyricsHandler(ls *services.LyricService) gin.HandlerFunc {
@@ -125,7 +430,11 @@ func createStartProcessHandler(ps *services.ProcessService) gin.HandlerFunc {
 		c.ShouldBindJSON(&settings)
 
 		if err := ps.StartProcess(id, settings); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			status := http.StatusInternalServerError
+			if errors.Is(err, services.ErrFileBusy) {
+				status = http.StatusConflict
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"message": "處理已開始"})
@@ -170,33 +479,171 @@ func createGetAudioHandler(fs *services.FileService) gin.HandlerFunc {
 	}
 }
 
-func createGetSegmentAudioHandler(dataDir string) gin.HandlerFunc {
+// createGetSegmentAudioHandler 取得段落音訊（原曲、TTS 或伴奏）
+// 可用 ?speed=0.75 指定速度，未指定時依段落與檔案的速度設定
+func createGetSegmentAudioHandler(ps *services.ProcessService, kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		segIdx, err := strconv.Atoi(c.Param("segIdx"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的段落索引"})
+			return
+		}
+
+		var speed float64
+		if v := c.Query("speed"); v != "" {
+			speed, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的播放速度"})
+				return
+			}
+		}
+
+		path, err := ps.SegmentAudio(id, segIdx, kind, speed)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, services.ErrInvalidSpeed):
+				status = http.StatusBadRequest
+			case errors.Is(err, services.ErrSegmentAudioNotFound):
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.File(path)
+	}
+}
+
+// createGetAnalysisHandler 分析整首原曲或段落音訊（?kind=tts 分析 TTS）
+// 可用 ?buckets=、?silenceDb=、?minSilence= 調整波形格數與靜音判斷
+func createGetAnalysisHandler(ps *services.ProcessService, segment bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		segIdx := -1
+		if segment {
+			var err error
+			segIdx, err = strconv.Atoi(c.Param("segIdx"))
+			if err != nil || segIdx < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的段落索引"})
+				return
+			}
+		}
+
+		var opts services.AnalysisOptions
+		var err error
+		if v := c.Query("buckets"); v != "" {
+			if opts.Buckets, err = strconv.Atoi(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的波形格數"})
+				return
+			}
+		}
+		if v := c.Query("silenceDb"); v != "" {
+			if opts.SilenceDB, err = strconv.ParseFloat(v, 64); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的靜音門檻"})
+				return
+			}
+		}
+		if v := c.Query("minSilence"); v != "" {
+			if opts.MinSilence, err = time.ParseDuration(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的靜音長度"})
+				return
+			}
+		}
+
+		kind := services.ClipOriginal
+		if c.Query("kind") == services.ClipTTS {
+			kind = services.ClipTTS
+		}
+
+		result, err := ps.Analyze(id, segIdx, kind, opts)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, services.ErrInvalidBuckets):
+				status = http.StatusBadRequest
+			case errors.Is(err, services.ErrSegmentAudioNotFound):
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// createGetWaveformHandler 取得整首原曲或段落音訊的波形峰值（?kind=tts 為 TTS）
+// 可用 ?points= 指定解析度，整首原曲會附上段落邊界與歌詞標記
+func createGetWaveformHandler(ps *services.ProcessService, segment bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
-		segIdx, _ := strconv.Atoi(c.Param("segIdx"))
+		segIdx := -1
+		if segment {
+			var err error
+			segIdx, err = strconv.Atoi(c.Param("segIdx"))
+			if err != nil || segIdx < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的段落索引"})
+				return
+			}
+		}
+
+		points := 0
+		if v := c.Query("points"); v != "" {
+			var err error
+			if points, err = strconv.Atoi(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的波形點數"})
+				return
+			}
+		}
 
-		// 使用三位數格式，例如 segment_000.mp3
-		segmentPath := filepath.Join(dataDir, id, "segments", fmt.Sprintf("segment_%03d.mp3", segIdx))
-		if _, err := os.Stat(segmentPath); os.IsNotExist(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "段落音訊不存在: " + segmentPath})
+		kind := services.ClipOriginal
+		if c.Query("kind") == services.ClipTTS {
+			kind = services.ClipTTS
+		}
+
+		result, err := ps.Waveform(id, segIdx, kind, points)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, services.ErrInvalidPoints):
+				status = http.StatusBadRequest
+			case errors.Is(err, services.ErrSegmentAudioNotFound):
+				status = http.StatusNotFound
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
 			return
 		}
-		c.File(segmentPath)
+		c.JSON(http.StatusOK, result)
 	}
 }
 
-func createGetSegmentTTSHandler(dataDir string) gin.HandlerFunc {
+// createSetSegmentSpeedHandler 設定段落專屬播放速度（0 表示沿用檔案設定）
+func createSetSegmentSpeedHandler(ps *services.ProcessService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
-		segIdx, _ := strconv.Atoi(c.Param("segIdx"))
+		segIdx, err := strconv.Atoi(c.Param("segIdx"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的段落索引"})
+			return
+		}
 
-		// 使用三位數格式，例如 tts_000.mp3
-		ttsPath := filepath.Join(dataDir, id, "tts", fmt.Sprintf("tts_%03d.mp3", segIdx))
-		if _, err := os.Stat(ttsPath); os.IsNotExist(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "TTS 音訊不存在: " + ttsPath})
+		var req struct {
+			Speed float64 `json:"speed"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求"})
 			return
 		}
-		c.File(ttsPath)
+
+		if err := ps.SetSegmentSpeed(id, segIdx, req.Speed); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, services.ErrInvalidSpeed) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true, "segmentIndex": segIdx, "speed": req.Speed})
 	}
 }
 
@@ -235,28 +682,511 @@ func createRetranslateHandler(ps *services.ProcessService) gin.HandlerFunc {
 	}
 }
 
+// ===== 羅馬拼音 Handler =====
+
+// createRomanizeHandler 為原文歌詞產生羅馬拼音（?force=true 時重新產生已有的）
+func createRomanizeHandler(ps *services.ProcessService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		force := false
+		if v := c.Query("force"); v != "" {
+			var err error
+			if force, err = strconv.ParseBool(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的 force 參數"})
+				return
+			}
+		}
+
+		updated, missing, err := ps.RomanizeLyrics(c.Request.Context(), c.Param("id"), force)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"updated": updated, "missing": missing})
+	}
+}
+
 // ===== 導出 Handlers =====
 
-func createExportHandler(ps *services.ProcessService) gin.HandlerFunc {
+func createExportHandler(es *services.ExportService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
-		exportPath, err := ps.Export(id)
+
+		// 導出選項皆為選填，格式也可以用 ?format= 指定
+		var opts models.ExportOptions
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&opts); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的請求格式"})
+				return
+			}
+		}
+		if opts.Format == "" {
+			opts.Format = models.ExportFormat(c.Query("format"))
+		}
+		if err := services.NormalizeExportOptions(&opts); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		job, err := es.StartExport(id, opts)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"path": exportPath})
+		c.JSON(http.StatusAccepted, job)
 	}
 }
 
-func createDownloadExportHandler(dataDir string) gin.HandlerFunc {
+func createListExportsHandler(es *services.ExportService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		exportPath := filepath.Join(dataDir, id, "export.mp3")
-		if _, err := os.Stat(exportPath); os.IsNotExist(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "導出檔案不存在"})
+		c.JSON(http.StatusOK, gin.H{"exports": es.ListExports(c.Param("id"))})
+	}
+}
+
+func createGetExportHandler(es *services.ExportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := es.GetJob(c.Param("id"), c.Param("jobId"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+func createDeleteExportHandler(es *services.ExportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := es.DeleteExport(c.Param("id"), c.Param("jobId")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "刪除成功"})
+	}
+}
+
+// 導出附帶的檔案
+const (
+	exportAssetMain      = ""
+	exportAssetLyrics    = "lyrics"
+	exportAssetSubtitles = "subtitles"
+)
+
+// downloadExport 下載導出檔案或附帶的 LRC、ASS 字幕
+func downloadExport(c *gin.Context, job *models.ExportJob, asset string) {
+	if job.Status != models.ExportDone {
+		c.JSON(http.StatusConflict, gin.H{"error": "導出尚未完成"})
+		return
+	}
+	path := job.Path
+	switch asset {
+	case exportAssetLyrics:
+		path = job.LyricsPath
+	case exportAssetSubtitles:
+		path = job.SubtitlePath
+	}
+	if path == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "導出檔案不存在"})
+		return
+	}
+	ext := filepath.Ext(path)
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "導出檔案不存在"})
+		return
+	}
+	c.FileAttachment(path, "export"+ext)
+}
+
+func createDownloadExportJobHandler(es *services.ExportService, asset string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := es.GetJob(c.Param("id"), c.Param("jobId"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		downloadExport(c, job, asset)
+	}
+}
+
+// createDownloadLatestExportHandler 下載最近一次完成的導出（可用 ?format= 指定格式）
+func createDownloadLatestExportHandler(es *services.ExportService, asset string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var format models.ExportFormat
+		if f := c.Query("format"); f != "" {
+			parsed, err := services.ParseExportFormat(f)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			format = parsed
+		}
+		job, err := es.Latest(c.Param("id"), format)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		downloadExport(c, job, asset)
+	}
+}
+
+// createAnkiExportHandler 下載檔案段落的 Anki 牌組（.apkg）
+func createAnkiExportHandler(es *services.ExportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pkg, err := es.AnkiPackage(c.Param("id"))
+		if err != nil {
+			status := http.StatusNotFound
+			if errors.Is(err, services.ErrNoAnkiNotes) {
+				status = http.StatusConflict
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		writeAnkiPackage(c, pkg)
+	}
+}
+
+// createAnkiCollectionExportHandler 下載集合所有歌曲的 Anki 牌組（.apkg），未處理的歌曲略過
+func createAnkiCollectionExportHandler(cs *services.CollectionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pkg, failed, err := cs.AnkiPackage(c.Param("cid"))
+		if err != nil {
+			status := http.StatusNotFound
+			if errors.Is(err, services.ErrNoAnkiNotes) || errors.Is(err, services.ErrEmptyCollection) {
+				status = http.StatusConflict
+			}
+			c.JSON(status, gin.H{"error": err.Error(), "failed": failed})
+			return
+		}
+		writeAnkiPackage(c, pkg)
+	}
+}
+
+// writeAnkiPackage 以附件回傳 .apkg，檔名為牌組名稱
+// 先寫到暫存檔，產生失敗（例如媒體檔不見）時還能回傳錯誤，而不是送出一半的檔案
+func writeAnkiPackage(c *gin.Context, pkg *anki.Package) {
+	tmp, err := os.CreateTemp("", "anki-*.apkg")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	err = pkg.Write(tmp, time.Now())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "產生 Anki 牌組失敗: " + err.Error()})
+		return
+	}
+	c.FileAttachment(tmp.Name(), pkg.Deck+".apkg")
+}
+
+// createGradeReviewHandler 記錄段落的複習評分 {"grade": 0~5}
+func createGradeReviewHandler(rs *services.ReviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		segIdx, err := strconv.Atoi(c.Param("segIdx"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的段落索引"})
+			return
+		}
+		var req struct {
+			Grade *int `json:"grade"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Grade == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "請提供評分 grade"})
+			return
+		}
+
+		card, err := rs.Grade(c.Param("fileId"), segIdx, *req.Grade)
+		if err != nil {
+			status := http.StatusNotFound
+			if errors.Is(err, services.ErrInvalidGrade) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, card)
+	}
+}
+
+// createListReviewCardsHandler 列出檔案的複習卡片
+func createListReviewCardsHandler(rs *services.ReviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cards, err := rs.Cards(c.Param("fileId"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"cards": cards})
+	}
+}
+
+// createDueReviewsHandler 今天到期的複習清單（可用 ?new= 與 ?limit= 調整新段落數與清單長度）
+func createDueReviewsHandler(rs *services.ReviewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		newLimit, limit := services.DefaultNewReviews, services.DefaultReviewLimit
+		var err error
+		if v := c.Query("new"); v != "" {
+			if newLimit, err = strconv.Atoi(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidReviewLimit.Error()})
+				return
+			}
+		}
+		if v := c.Query("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidReviewLimit.Error()})
+				return
+			}
+		}
+
+		queue, err := rs.Due(newLimit, limit)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, services.ErrInvalidReviewLimit) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, queue)
+	}
+}
+
+// ===== 練習紀錄 Handlers =====
+
+// practiceUserID 取得使用者 ID：X-User-ID 標頭、?user= 或預設使用者
+func practiceUserID(c *gin.Context) string {
+	if id := c.GetHeader("X-User-ID"); id != "" {
+		return id
+	}
+	if id := c.Query("user"); id != "" {
+		return id
+	}
+	return services.DefaultUserID
+}
+
+// writeSessionError 依錯誤類型回傳對應的狀態碼
+func writeSessionError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidUserID), errors.Is(err, services.ErrInvalidEvent),
+		errors.Is(err, services.ErrInvalidGrade), errors.Is(err, services.ErrInvalidStatsDays):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrSessionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrSessionEnded):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// createStartSessionHandler 開始一次練習 {"mode": "playlist"}
+func createStartSessionHandler(ss *services.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Mode string `json:"mode"`
+		}
+		c.ShouldBindJSON(&req)
+		session, err := ss.Start(practiceUserID(c), req.Mode)
+		if err != nil {
+			writeSessionError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, session)
+	}
+}
+
+// createListSessionsHandler 列出最近的練習紀錄（?limit= 預設 20）
+func createListSessionsHandler(ss *services.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 20
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "無效的筆數"})
+				return
+			}
+			limit = n
+		}
+		sessions, err := ss.List(practiceUserID(c), limit)
+		if err != nil {
+			writeSessionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+	}
+}
+
+// createAddSessionEventsHandler 記錄練習事件 {"events": [...]}；end 為 true 時同時結束練習
+// 結束練習時 body 可以省略（頁面關閉時以 sendBeacon 送出）
+func createAddSessionEventsHandler(ss *services.SessionService, end bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Events []models.PracticeEvent `json:"events"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil && !(end && c.Request.ContentLength == 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的練習事件"})
+			return
+		}
+
+		var session *models.PracticeSession
+		var err error
+		if end {
+			session, err = ss.End(practiceUserID(c), c.Param("sid"), req.Events)
+		} else {
+			session, err = ss.AddEvents(practiceUserID(c), c.Param("sid"), req.Events)
+		}
+		if err != nil {
+			writeSessionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, session)
+	}
+}
+
+// createPracticeStatsHandler 練習統計（?days= 預設 30，最多 365）
+func createPracticeStatsHandler(ss *services.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		days := services.DefaultStatsDays
+		if v := c.Query("days"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				writeSessionError(c, services.ErrInvalidStatsDays)
+				return
+			}
+			days = n
+		}
+		stats, err := ss.Stats(practiceUserID(c), days)
+		if err != nil {
+			writeSessionError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+// ===== 練習題 Handlers =====
+
+// createCreateExerciseHandler 從歌詞產生練習題 {"type": "cloze", "count": 10, "blanks": 1, "contentWords": true}
+func createCreateExerciseHandler(xs *services.ExerciseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var opts models.ExerciseOptions
+		if err := c.ShouldBindJSON(&opts); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的練習選項"})
+			return
+		}
+		exercise, err := xs.Create(c.Param("id"), opts)
+		if err != nil {
+			status := http.StatusNotFound
+			switch {
+			case errors.Is(err, services.ErrInvalidExerciseType), errors.Is(err, services.ErrInvalidExercise):
+				status = http.StatusBadRequest
+			case errors.Is(err, services.ErrNoExerciseLines):
+				status = http.StatusUnprocessableEntity
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, exercise)
+	}
+}
+
+// createGetExerciseHandler 獲取練習題（不含正解）
+func createGetExerciseHandler(xs *services.ExerciseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		exercise, err := xs.Get(c.Param("id"), c.Param("exId"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, exercise)
+	}
+}
+
+// createGradeExerciseHandler 批改答案 {"answers": {"1": ["word"], "2": ["整句"]}}
+func createGradeExerciseHandler(xs *services.ExerciseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Answers map[string][]string `json:"answers"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "無效的答案格式"})
+			return
+		}
+		result, err := xs.Grade(c.Param("id"), c.Param("exId"), req.Answers)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// ===== 詞彙 Handlers =====
+
+// createGetGlossaryHandler 獲取一首歌的詞彙表
+func createGetGlossaryHandler(vs *services.VocabularyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		glossary, err := vs.Glossary(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, glossary)
+	}
+}
+
+// createExtractGlossaryHandler 擷取（或重新擷取）一首歌的詞彙，完成後回傳詞彙表
+func createExtractGlossaryHandler(vs *services.VocabularyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		glossary, err := vs.Extract(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			status := http.StatusNotFound
+			switch {
+			case errors.Is(err, services.ErrVocabularyUnavailable):
+				status = http.StatusServiceUnavailable
+			case errors.Is(err, services.ErrVocabularyBusy):
+				status = http.StatusConflict
+			case errors.Is(err, services.ErrNoVocabLines):
+				status = http.StatusUnprocessableEntity
+			case errors.Is(err, services.ErrVocabularyFailed):
+				status = http.StatusBadGateway
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, glossary)
+	}
+}
+
+// createListVocabularyHandler 列出全域詞彙表（?lang= 語言、?q= 搜尋、?limit= 預設 100）
+func createListVocabularyHandler(vs *services.VocabularyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := services.DefaultVocabLimit
+		if v := c.Query("limit"); v != "" {
+			var err error
+			if limit, err = strconv.Atoi(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidVocabLimit.Error()})
+				return
+			}
+		}
+		words, total, err := vs.List(c.Query("lang"), c.Query("q"), limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"words": words, "total": total})
+	}
+}
+
+// createLookupWordHandler 查詢一個詞在所有歌曲中的出處
+func createLookupWordHandler(vs *services.VocabularyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		words, err := vs.Lookup(c.Param("word"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		c.FileAttachment(exportPath, "export.mp3")
+		c.JSON(http.StatusOK, gin.H{"words": words})
 	}
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"multilang-learner/internal/api"
 	"multilang-learner/internal/logger"
@@ -34,6 +36,39 @@ func main() {
 	fileService := services.NewFileService(dataDir, uploadDir)
 	lyricService := services.NewLyricService(dataDir, fileService)
 	processService := services.NewProcessService(dataDir, fileService, lyricService)
+	exportService := services.NewExportService(dataDir, fileService, processService)
+	collectionService := services.NewCollectionService(dataDir, fileService, processService, exportService)
+	reviewService := services.NewReviewService(dataDir, fileService, processService)
+	sessionService := services.NewSessionService(dataDir, fileService, processService)
+	exerciseService := services.NewExerciseService(dataDir, fileService, lyricService, processService)
+	vocabularyService := services.NewVocabularyService(dataDir, fileService, lyricService)
+	processService.SetVocabularyService(vocabularyService)
+
+	// 批次匯入（IMPORT_DIR 未設定時停用）
+	importConfig := services.ImportConfig{
+		Dir:         os.Getenv("IMPORT_DIR"),
+		AutoProcess: os.Getenv("IMPORT_AUTO_PROCESS") == "true",
+	}
+	if interval := os.Getenv("IMPORT_POLL_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			log.Printf("Warning: invalid IMPORT_POLL_INTERVAL %q: %v", interval, err)
+		}
+		importConfig.PollInterval = d
+	}
+	importService := services.NewImportService(importConfig, dataDir, fileService, processService)
+	importService.Watch()
+
+	// 上傳大小上限（MB），預設 200
+	maxUploadMB := int64(200)
+	if v := os.Getenv("MAX_UPLOAD_MB"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Printf("Warning: invalid MAX_UPLOAD_MB %q: %v", v, err)
+		} else {
+			maxUploadMB = n
+		}
+	}
 
 	// 建立路由
 	gin.SetMode(gin.ReleaseMode)
@@ -59,15 +94,19 @@ func main() {
 		// 檔案管理
 		files := apiGroup.Group("/files")
 		{
-			files.GET("", createListFilesHandler(fileService))
-			files.POST("/upload", createUploadHandler(fileService))
+			files.GET("", createListFilesHandler(fileService, collectionService))
+			files.POST("/upload", createUploadHandler(fileService, maxUploadMB<<20))
 			files.GET("/:id", createGetFileHandler(fileService))
-			files.DELETE("/:id", createDeleteFileHandler(fileService))
+			files.DELETE("/:id", createDeleteFileHandler(fileService, collectionService, exportService, reviewService, exerciseService, vocabularyService))
 			files.POST("/:id/settings", createUpdateSettingsHandler(fileService))
+			files.GET("/:id/cover", createGetCoverHandler(fileService))
+			files.POST("/:id/practiced", createMarkPracticedHandler(fileService))
+			files.PUT("/:id/tags", createSetTagsHandler(fileService))
 
 			// 歌詞
 			files.GET("/:id/lyrics", createGetLyricsHandler(lyricService))
 			files.POST("/:id/detect-start", createDetectStartHandler(lyricService))
+			files.POST("/:id/romanize", createRomanizeHandler(processService))
 
 			// 處理
 			files.POST("/:id/process", createStartProcessHandler(processService))
@@ -76,16 +115,85 @@ func main() {
 
 			// 音訊
 			files.GET("/:id/audio", createGetAudioHandler(fileService))
-			files.GET("/:id/segments/:segIdx/audio", createGetSegmentAudioHandler(dataDir))
-			files.GET("/:id/segments/:segIdx/tts", createGetSegmentTTSHandler(dataDir))
+			files.GET("/:id/analysis", createGetAnalysisHandler(processService, false))
+			files.GET("/:id/waveform", createGetWaveformHandler(processService, false))
+			files.GET("/:id/segments/:segIdx/audio", createGetSegmentAudioHandler(processService, services.ClipOriginal))
+			files.GET("/:id/segments/:segIdx/tts", createGetSegmentAudioHandler(processService, services.ClipTTS))
+			files.GET("/:id/segments/:segIdx/karaoke", createGetSegmentAudioHandler(processService, services.ClipKaraoke))
+			files.GET("/:id/segments/:segIdx/analysis", createGetAnalysisHandler(processService, true))
+			files.GET("/:id/segments/:segIdx/waveform", createGetWaveformHandler(processService, true))
+			files.PUT("/:id/segments/:segIdx/speed", createSetSegmentSpeedHandler(processService))
 
 			// 重新翻譯
 			files.POST("/:id/segments/:segIdx/retranslate", createRetranslateHandler(processService))
 
 			// 導出
-			files.POST("/:id/export", createExportHandler(processService))
-			files.GET("/:id/export/download", createDownloadExportHandler(dataDir))
+			files.POST("/:id/export", createExportHandler(exportService))
+			files.GET("/:id/export/download", createDownloadLatestExportHandler(exportService, exportAssetMain))
+			files.GET("/:id/export/lyrics", createDownloadLatestExportHandler(exportService, exportAssetLyrics))
+			files.GET("/:id/export/anki", createAnkiExportHandler(exportService))
+			files.GET("/:id/exports", createListExportsHandler(exportService))
+			files.GET("/:id/exports/:jobId", createGetExportHandler(exportService))
+			files.DELETE("/:id/exports/:jobId", createDeleteExportHandler(exportService))
+			files.GET("/:id/exports/:jobId/download", createDownloadExportJobHandler(exportService, exportAssetMain))
+			files.GET("/:id/exports/:jobId/lyrics", createDownloadExportJobHandler(exportService, exportAssetLyrics))
+			files.GET("/:id/exports/:jobId/subtitles", createDownloadExportJobHandler(exportService, exportAssetSubtitles))
+
+			// 練習題
+			files.POST("/:id/exercises", createCreateExerciseHandler(exerciseService))
+			files.GET("/:id/exercises/:exId", createGetExerciseHandler(exerciseService))
+			files.POST("/:id/exercises/:exId/answers", createGradeExerciseHandler(exerciseService))
+
+			// 詞彙
+			files.GET("/:id/glossary", createGetGlossaryHandler(vocabularyService))
+			files.POST("/:id/glossary", createExtractGlossaryHandler(vocabularyService))
 		}
+
+		// 批次匯入
+		apiGroup.POST("/import/scan", createStartImportHandler(importService))
+		apiGroup.GET("/import/status", createImportStatusHandler(importService))
+
+		// 標籤
+		apiGroup.GET("/tags", createListTagsHandler(fileService))
+
+		// 集合
+		collections := apiGroup.Group("/collections")
+		{
+			collections.GET("", createListCollectionsHandler(collectionService))
+			collections.POST("", createCreateCollectionHandler(collectionService))
+			collections.GET("/:cid", createGetCollectionHandler(fileService, collectionService))
+			collections.PUT("/:cid", createUpdateCollectionHandler(collectionService))
+			collections.DELETE("/:cid", createDeleteCollectionHandler(collectionService))
+			collections.POST("/:cid/files", createAddCollectionFilesHandler(collectionService))
+			collections.DELETE("/:cid/files/:fileId", createRemoveCollectionFileHandler(collectionService))
+			collections.POST("/:cid/process", createProcessCollectionHandler(collectionService))
+			collections.POST("/:cid/export", createExportCollectionHandler(collectionService))
+			collections.GET("/:cid/export", createGetCollectionExportHandler(collectionService))
+			collections.GET("/:cid/export/download", createDownloadCollectionExportHandler(collectionService))
+			collections.GET("/:cid/export/anki", createAnkiCollectionExportHandler(collectionService))
+		}
+
+		// 間隔重複複習
+		review := apiGroup.Group("/review")
+		{
+			review.GET("/due", createDueReviewsHandler(reviewService))
+			review.GET("/:fileId", createListReviewCardsHandler(reviewService))
+			review.POST("/:fileId/:segIdx", createGradeReviewHandler(reviewService))
+		}
+
+		// 練習紀錄與統計（使用者以 X-User-ID 標頭或 ?user= 指定）
+		sessions := apiGroup.Group("/sessions")
+		{
+			sessions.GET("", createListSessionsHandler(sessionService))
+			sessions.POST("", createStartSessionHandler(sessionService))
+			sessions.POST("/:sid/events", createAddSessionEventsHandler(sessionService, false))
+			sessions.POST("/:sid/end", createAddSessionEventsHandler(sessionService, true))
+		}
+		apiGroup.GET("/stats", createPracticeStatsHandler(sessionService))
+
+		// 全域詞彙表
+		apiGroup.GET("/vocabulary", createListVocabularyHandler(vocabularyService))
+		apiGroup.GET("/vocabulary/:word", createLookupWordHandler(vocabularyService))
 	}
 
 	// 啟動伺服器
@@ -0,0 +1,258 @@
+// Package anki 產生可匯入 Anki 的 .apkg 套件（SQLite collection 與媒體檔打包成 ZIP）
+package anki
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Model 筆記類型：欄位與單一卡片模板
+type Model struct {
+	Name   string
+	Fields []string
+	Front  string // 正面模板，例如 {{Audio}}{{Original}}
+	Back   string // 背面模板
+	CSS    string
+}
+
+// Note 一則筆記，Fields 與 Model.Fields 一一對應
+type Note struct {
+	GUID   string // 穩定的識別碼，重新匯入時更新同一則筆記（見 GUID）
+	Fields []string
+	Tags   []string
+}
+
+// Media 要一起打包的媒體檔，欄位中以 [sound:Name] 引用
+type Media struct {
+	Name string
+	Path string
+}
+
+// Package 一個 .apkg 套件（單一牌組）
+type Package struct {
+	Deck  string // 牌組名稱，可用 :: 表示子牌組
+	Model Model
+	Notes []Note
+	Media []Media
+}
+
+// GUID 由任意字串產生穩定的筆記識別碼
+func GUID(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Sound 欄位中播放媒體檔的標記
+func Sound(name string) string {
+	return "[sound:" + name + "]"
+}
+
+// Write 將套件寫成 .apkg
+func (p *Package) Write(w io.Writer, now time.Time) error {
+	if len(p.Model.Fields) == 0 {
+		return fmt.Errorf("anki: model has no fields")
+	}
+	collection, err := p.collection(now)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	f, err := zw.Create("collection.anki2")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(collection); err != nil {
+		return err
+	}
+
+	// 媒體檔在 ZIP 中依序命名為 0、1、2...，media 檔記錄對應的檔名
+	mediaMap := make(map[string]string, len(p.Media))
+	for i, m := range p.Media {
+		key := strconv.Itoa(i)
+		if err := addMedia(zw, key, m.Path); err != nil {
+			return fmt.Errorf("anki: add media %s: %w", m.Name, err)
+		}
+		mediaMap[key] = m.Name
+	}
+	data, _ := json.Marshal(mediaMap)
+	f, err = zw.Create("media")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// addMedia 將媒體檔寫入 ZIP（音訊已壓縮，不再壓縮）
+func addMedia(zw *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// collection 產生 collection.anki2（schema 11）
+func (p *Package) collection(now time.Time) ([]byte, error) {
+	nowMs := now.UnixMilli()
+	modelID := stableID("model:" + p.Model.Name)
+	deckID := stableID("deck:" + p.Deck)
+
+	db := &sqliteDB{}
+	col := db.table("col", schemaCol)
+	notes := db.table("notes", schemaNotes)
+	cards := db.table("cards", schemaCards)
+	db.table("revlog", schemaRevlog)
+	db.table("graves", schemaGraves)
+
+	conf, _ := json.Marshal(map[string]any{
+		"activeDecks": []int64{1}, "curDeck": 1, "newSpread": 0, "collapseTime": 1200, "timeLim": 0,
+		"estTimes": true, "dueCounts": true, "curModel": modelID, "nextPos": len(p.Notes) + 1,
+		"sortType": "noteFld", "sortBackwards": false, "addToCur": true,
+	})
+	models, _ := json.Marshal(map[string]any{strconv.FormatInt(modelID, 10): p.modelJSON(modelID, deckID, now)})
+	decks, _ := json.Marshal(map[string]any{
+		"1":                           deckJSON(1, "Default", now),
+		strconv.FormatInt(deckID, 10): deckJSON(deckID, p.Deck, now),
+	})
+	dconf, _ := json.Marshal(map[string]any{"1": defaultDeckConfig})
+	col.insert(1, nil, now.Unix(), nowMs, nowMs, 11, 0, 0, 0, string(conf), string(models), string(decks), string(dconf), "{}")
+
+	for i, n := range p.Notes {
+		fields := make([]string, len(p.Model.Fields))
+		copy(fields, n.Fields)
+		sortField := stripHTML(fields[0])
+		tags := ""
+		if len(n.Tags) > 0 {
+			tags = " " + strings.Join(n.Tags, " ") + " "
+		}
+		noteID := nowMs + int64(i)
+		notes.insert(noteID, nil, n.GUID, modelID, now.Unix(), -1, tags, strings.Join(fields, "\x1f"), sortField, checksum(sortField), 0, "")
+		// 新卡片：type/queue 0，due 為新卡順序
+		cards.insert(noteID, nil, noteID, deckID, 0, now.Unix(), -1, 0, 0, i+1, 0, 0, 0, 0, 0, 0, 0, 0, "")
+	}
+	return db.bytes()
+}
+
+// modelJSON 筆記類型的 JSON（標準卡片類型，單一模板）
+func (p *Package) modelJSON(id, deckID int64, now time.Time) map[string]any {
+	fields := make([]map[string]any, len(p.Model.Fields))
+	for i, name := range p.Model.Fields {
+		fields[i] = map[string]any{
+			"name": name, "ord": i, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{},
+		}
+	}
+	return map[string]any{
+		"id": id, "name": p.Model.Name, "type": 0, "mod": now.Unix(), "usn": -1, "sortf": 0, "did": deckID,
+		"tmpls": []map[string]any{{
+			"name": "Card 1", "ord": 0, "qfmt": p.Model.Front, "afmt": p.Model.Back,
+			"did": nil, "bqfmt": "", "bafmt": "",
+		}},
+		"flds":      fields,
+		"css":       p.Model.CSS,
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"tags":      []string{},
+		"vers":      []any{},
+		"req":       []any{[]any{0, "any", []int{0}}},
+	}
+}
+
+// deckJSON 牌組的 JSON
+func deckJSON(id int64, name string, now time.Time) map[string]any {
+	return map[string]any{
+		"id": id, "name": name, "mod": now.Unix(), "usn": -1, "desc": "", "dyn": 0, "conf": 1, "collapsed": false,
+		"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+		"extendNew": 10, "extendRev": 50,
+	}
+}
+
+// defaultDeckConfig Anki 預設的牌組選項
+var defaultDeckConfig = map[string]any{
+	"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true, "timer": 0, "replayq": true, "dyn": false,
+	"new": map[string]any{
+		"delays": []float64{1, 10}, "ints": []int{1, 4, 7}, "initialFactor": 2500, "separate": true,
+		"order": 1, "perDay": 20, "bury": false,
+	},
+	"rev": map[string]any{
+		"perDay": 200, "ease4": 1.3, "fuzz": 0.05, "ivlFct": 1, "maxIvl": 36500, "minSpace": 1, "bury": false,
+	},
+	"lapse": map[string]any{
+		"delays": []float64{10}, "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 0,
+	},
+}
+
+// stableID 由名稱產生固定的 ID，重新匯入時沿用同一個筆記類型與牌組
+func stableID(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	// 保持在 JavaScript 可精確表示的範圍內
+	return int64(h.Sum64()&(1<<52-1)) + 1<<52
+}
+
+// checksum 排序欄位 SHA-1 的前 8 個十六進位數字（Anki 用來偵測重複）
+func checksum(s string) int64 {
+	sum := sha1.Sum([]byte(s))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+var (
+	soundTag = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	htmlTag  = regexp.MustCompile(`<[^>]*>`)
+)
+
+// stripHTML 移除 HTML 與媒體標記
+func stripHTML(s string) string {
+	s = soundTag.ReplaceAllString(s, "")
+	s = htmlTag.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
+
+// collection.anki2 的資料表（Anki schema 11）
+const (
+	schemaCol = `CREATE TABLE col (
+    id integer primary key, crt integer not null, mod integer not null, scm integer not null,
+    ver integer not null, dty integer not null, usn integer not null, ls integer not null,
+    conf text not null, models text not null, decks text not null, dconf text not null, tags text not null
+)`
+	schemaNotes = `CREATE TABLE notes (
+    id integer primary key, guid text not null, mid integer not null, mod integer not null,
+    usn integer not null, tags text not null, flds text not null, sfld integer not null,
+    csum integer not null, flags integer not null, data text not null
+)`
+	schemaCards = `CREATE TABLE cards (
+    id integer primary key, nid integer not null, did integer not null, ord integer not null,
+    mod integer not null, usn integer not null, type integer not null, queue integer not null,
+    due integer not null, ivl integer not null, factor integer not null, reps integer not null,
+    lapses integer not null, left integer not null, odue integer not null, odid integer not null,
+    flags integer not null, data text not null
+)`
+	schemaRevlog = `CREATE TABLE revlog (
+    id integer primary key, cid integer not null, usn integer not null, ease integer not null,
+    ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null,
+    type integer not null
+)`
+	schemaGraves = `CREATE TABLE graves (
+    usn integer not null, oid integer not null, type integer not null
+)`
+)
@@ -0,0 +1,348 @@
+package anki
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 只寫入的最小 SQLite 資料庫：每個資料表一棵 table b-tree，不建索引，
+// 一次產生整個檔案（格式參考 https://www.sqlite.org/fileformat.html）
+const (
+	sqlitePageSize   = 4096
+	sqliteHeaderSize = 100 // 第一頁開頭的資料庫標頭
+	leafHeaderSize   = 8
+	interiorHdrSize  = 12
+
+	pageTypeLeaf     = 0x0D // table b-tree leaf
+	pageTypeInterior = 0x05 // table b-tree interior
+)
+
+// sqliteDB 要寫出的資料庫
+type sqliteDB struct {
+	tables []*sqliteTable
+	pages  [][]byte // pages[0] 是第 1 頁
+}
+
+// sqliteTable 一個資料表與它的列
+type sqliteTable struct {
+	name string
+	sql  string
+	rows []sqliteRow
+}
+
+// sqliteRow 一列：rowid 與編碼後的 record
+type sqliteRow struct {
+	rowid  int64
+	record []byte
+}
+
+// table 新增資料表，sql 為 CREATE TABLE 敘述
+func (db *sqliteDB) table(name, sql string) *sqliteTable {
+	t := &sqliteTable{name: name, sql: sql}
+	db.tables = append(db.tables, t)
+	return t
+}
+
+// insert 新增一列，values 依欄位順序為 nil、int、int64、float64、string 或 []byte
+// INTEGER PRIMARY KEY 欄位是 rowid 的別名，值要給 nil
+func (t *sqliteTable) insert(rowid int64, values ...any) {
+	t.rows = append(t.rows, sqliteRow{rowid: rowid, record: encodeRecord(values)})
+}
+
+// bytes 產生整個資料庫檔案
+func (db *sqliteDB) bytes() ([]byte, error) {
+	db.pages = [][]byte{make([]byte, sqlitePageSize)} // 第 1 頁保留給 sqlite_master
+
+	master := make([]sqliteRow, 0, len(db.tables))
+	for i, t := range db.tables {
+		rows := append([]sqliteRow(nil), t.rows...)
+		sort.Slice(rows, func(a, b int) bool { return rows[a].rowid < rows[b].rowid })
+		for j := 1; j < len(rows); j++ {
+			if rows[j].rowid == rows[j-1].rowid {
+				return nil, fmt.Errorf("duplicate rowid %d in table %s", rows[j].rowid, t.name)
+			}
+		}
+		root := db.buildTree(rows, 0)
+		master = append(master, sqliteRow{
+			rowid:  int64(i + 1),
+			record: encodeRecord([]any{"table", t.name, t.name, int64(root), t.sql}),
+		})
+	}
+	db.buildTree(master, 1)
+	db.writeHeader()
+
+	out := make([]byte, 0, len(db.pages)*sqlitePageSize)
+	for _, p := range db.pages {
+		out = append(out, p...)
+	}
+	return out, nil
+}
+
+// writeHeader 寫入第 1 頁開頭的資料庫標頭
+func (db *sqliteDB) writeHeader() {
+	h := db.pages[0][:sqliteHeaderSize]
+	copy(h, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(h[16:], sqlitePageSize)
+	h[18], h[19] = 1, 1              // legacy journal 模式的讀寫版本
+	h[21], h[22], h[23] = 64, 32, 32 // payload 比例（固定值）
+	binary.BigEndian.PutUint32(h[24:], 1)
+	binary.BigEndian.PutUint32(h[28:], uint32(len(db.pages)))
+	binary.BigEndian.PutUint32(h[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(h[44:], 4) // schema format
+	binary.BigEndian.PutUint32(h[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(h[92:], 1)
+	binary.BigEndian.PutUint32(h[96:], 3046000)
+}
+
+// buildTree 建立 table b-tree 並回傳根頁碼；root 不為 0 時根節點寫在指定頁
+// rows 需依 rowid 排序
+func (db *sqliteDB) buildTree(rows []sqliteRow, root int) int {
+	rootOffset := 0
+	if root == 1 {
+		rootOffset = sqliteHeaderSize
+	}
+
+	cells := make([][]byte, len(rows))
+	for i, r := range rows {
+		cells[i] = db.leafCell(r)
+	}
+	if cellsSize(cells)+leafHeaderSize+rootOffset <= sqlitePageSize {
+		return db.putPage(root, leafPage(cells, rootOffset))
+	}
+
+	type child struct {
+		page int
+		key  int64 // 子樹中最大的 rowid
+	}
+	var children []child
+	for start := 0; start < len(cells); {
+		end, used := start, leafHeaderSize
+		for end < len(cells) && used+2+len(cells[end]) <= sqlitePageSize {
+			used += 2 + len(cells[end])
+			end++
+		}
+		page := db.putPage(0, leafPage(cells[start:end], 0))
+		children = append(children, child{page: page, key: rows[end-1].rowid})
+		start = end
+	}
+
+	for {
+		// 除了最右邊的子節點，每個子節點是一個 cell：4 位元組頁碼 + rowid
+		interiorCells := make([][]byte, len(children)-1)
+		for i, c := range children[:len(children)-1] {
+			interiorCells[i] = interiorCell(c.page, c.key)
+		}
+		if cellsSize(interiorCells)+interiorHdrSize+rootOffset <= sqlitePageSize {
+			return db.putPage(root, interiorPage(interiorCells, children[len(children)-1].page, rootOffset))
+		}
+
+		var parents []child
+		for start := 0; start < len(children); {
+			end, used := start+1, interiorHdrSize
+			for end < len(children) && used+2+len(interiorCells[end-1]) <= sqlitePageSize {
+				used += 2 + len(interiorCells[end-1])
+				end++
+			}
+			// 避免最後一頁只剩一個子節點（沒有 cell 的 interior 頁）
+			if len(children)-end == 1 && end-start > 1 {
+				end--
+			}
+			page := db.putPage(0, interiorPage(interiorCells[start:end-1], children[end-1].page, 0))
+			parents = append(parents, child{page: page, key: children[end-1].key})
+			start = end
+		}
+		children = parents
+	}
+}
+
+// putPage 寫入頁面，pageNo 為 0 時配置新頁，回傳頁碼
+func (db *sqliteDB) putPage(pageNo int, page []byte) int {
+	if pageNo == 0 {
+		db.pages = append(db.pages, page)
+		return len(db.pages)
+	}
+	if pageNo == 1 {
+		// 保留第 1 頁的資料庫標頭
+		copy(db.pages[0][sqliteHeaderSize:], page[sqliteHeaderSize:])
+		return 1
+	}
+	db.pages[pageNo-1] = page
+	return pageNo
+}
+
+// leafCell 編碼 leaf cell，payload 太大時其餘部分寫入 overflow 頁
+func (db *sqliteDB) leafCell(r sqliteRow) []byte {
+	const usable = sqlitePageSize
+	const maxLocal = usable - 35
+	const minLocal = (usable-12)*32/255 - 23
+
+	payload := r.record
+	cell := appendVarint(nil, uint64(len(payload)))
+	cell = appendVarint(cell, uint64(r.rowid))
+	if len(payload) <= maxLocal {
+		return append(cell, payload...)
+	}
+
+	local := minLocal + (len(payload)-minLocal)%(usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	cell = append(cell, payload[:local]...)
+	return binary.BigEndian.AppendUint32(cell, uint32(db.overflow(payload[local:])))
+}
+
+// overflow 將資料寫入 overflow 頁串列，回傳第一頁的頁碼
+func (db *sqliteDB) overflow(data []byte) int {
+	first := len(db.pages) + 1
+	for len(data) > 0 {
+		n := min(len(data), sqlitePageSize-4)
+		page := make([]byte, sqlitePageSize)
+		if n < len(data) {
+			binary.BigEndian.PutUint32(page, uint32(len(db.pages)+2))
+		}
+		copy(page[4:], data[:n])
+		db.pages = append(db.pages, page)
+		data = data[n:]
+	}
+	return first
+}
+
+// leafPage 產生 leaf 頁，offset 為頁首前保留的長度（第 1 頁為 100）
+func leafPage(cells [][]byte, offset int) []byte {
+	page := make([]byte, sqlitePageSize)
+	page[offset] = pageTypeLeaf
+	writeCells(page, offset, leafHeaderSize, cells)
+	return page
+}
+
+// interiorPage 產生 interior 頁，right 為最右邊的子節點
+func interiorPage(cells [][]byte, right int, offset int) []byte {
+	page := make([]byte, sqlitePageSize)
+	page[offset] = pageTypeInterior
+	binary.BigEndian.PutUint32(page[offset+8:], uint32(right))
+	writeCells(page, offset, interiorHdrSize, cells)
+	return page
+}
+
+// writeCells 從頁尾往前放入 cells，並寫入 cell 指標陣列與頁首欄位
+func writeCells(page []byte, offset, headerSize int, cells [][]byte) {
+	content := len(page)
+	for i, c := range cells {
+		content -= len(c)
+		copy(page[content:], c)
+		binary.BigEndian.PutUint16(page[offset+headerSize+2*i:], uint16(content))
+	}
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+}
+
+func interiorCell(page int, key int64) []byte {
+	cell := binary.BigEndian.AppendUint32(nil, uint32(page))
+	return appendVarint(cell, uint64(key))
+}
+
+// cellsSize cells 加上指標陣列所需的空間
+func cellsSize(cells [][]byte) int {
+	n := 0
+	for _, c := range cells {
+		n += 2 + len(c)
+	}
+	return n
+}
+
+// encodeRecord 依 SQLite record 格式編碼一列
+func encodeRecord(values []any) []byte {
+	var header, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+			header = appendVarint(header, 0)
+		case int:
+			header, body = appendInt(header, body, int64(v))
+		case int64:
+			header, body = appendInt(header, body, v)
+		case float64:
+			header = appendVarint(header, 7)
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+		case string:
+			header = appendVarint(header, uint64(len(v))*2+13)
+			body = append(body, v...)
+		case []byte:
+			header = appendVarint(header, uint64(len(v))*2+12)
+			body = append(body, v...)
+		default:
+			panic(fmt.Sprintf("anki: unsupported sqlite value %T", v))
+		}
+	}
+
+	// 標頭長度包含自己
+	size := len(header) + 1
+	for varintLen(uint64(size))+len(header) != size {
+		size = varintLen(uint64(size)) + len(header)
+	}
+	record := appendVarint(make([]byte, 0, size+len(body)), uint64(size))
+	record = append(record, header...)
+	return append(record, body...)
+}
+
+// appendInt 以最短的整數 serial type 編碼
+func appendInt(header, body []byte, v int64) ([]byte, []byte) {
+	var serial uint64
+	var n int
+	switch {
+	case v == 0:
+		return appendVarint(header, 8), body
+	case v == 1:
+		return appendVarint(header, 9), body
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		serial, n = 1, 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		serial, n = 2, 2
+	case v >= -1<<23 && v < 1<<23:
+		serial, n = 3, 3
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		serial, n = 4, 4
+	case v >= -1<<47 && v < 1<<47:
+		serial, n = 5, 6
+	default:
+		serial, n = 6, 8
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(v))
+	return appendVarint(header, serial), append(body, buf[8-n:]...)
+}
+
+// appendVarint 編碼 SQLite 的 varint（高位在前，最多 9 個位元組）
+func appendVarint(b []byte, v uint64) []byte {
+	if v > 1<<56-1 {
+		var buf [9]byte
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return append(b, buf[:]...)
+	}
+	var buf [8]byte
+	n := 0
+	for {
+		buf[n] = byte(v&0x7f) | 0x80
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	buf[0] &= 0x7f
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, buf[i])
+	}
+	return b
+}
+
+func varintLen(v uint64) int {
+	return len(appendVarint(nil, v))
+}
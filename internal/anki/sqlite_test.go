@@ -0,0 +1,261 @@
+package anki
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 以下是測試用的最小 SQLite 讀取器，依檔案格式文件獨立實作，用來驗證寫出的內容
+
+type decodedRow struct {
+	rowid  int64
+	values []any
+}
+
+// readVarint 解碼 SQLite varint，回傳值與長度
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v<<8 | uint64(b[8]), 9
+}
+
+// decodeRecord 解碼 record 為 nil、int64、float64、string 或 []byte
+func decodeRecord(t *testing.T, rec []byte) []any {
+	t.Helper()
+	hdrLen, n := readVarint(rec)
+	header, body := rec[n:hdrLen], rec[hdrLen:]
+	var values []any
+	for len(header) > 0 {
+		serial, n := readVarint(header)
+		header = header[n:]
+		switch {
+		case serial == 0:
+			values = append(values, nil)
+		case serial >= 1 && serial <= 6:
+			size := []int{0, 1, 2, 3, 4, 6, 8}[serial]
+			var buf [8]byte
+			if body[0]&0x80 != 0 {
+				buf = [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+			}
+			copy(buf[8-size:], body[:size])
+			values = append(values, int64(binary.BigEndian.Uint64(buf[:])))
+			body = body[size:]
+		case serial == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(body)))
+			body = body[8:]
+		case serial == 8, serial == 9:
+			values = append(values, int64(serial-8))
+		case serial >= 12 && serial%2 == 0:
+			size := int(serial-12) / 2
+			values = append(values, append([]byte(nil), body[:size]...))
+			body = body[size:]
+		case serial >= 13:
+			size := int(serial-13) / 2
+			values = append(values, string(body[:size]))
+			body = body[size:]
+		default:
+			t.Fatalf("unexpected serial type %d", serial)
+		}
+	}
+	if len(body) != 0 {
+		t.Fatalf("record has %d trailing bytes", len(body))
+	}
+	return values
+}
+
+// readTable 走訪 table b-tree，依序回傳每一列，並檢查 rowid 遞增與 interior 頁的鍵
+func readTable(t *testing.T, data []byte, root int) []decodedRow {
+	t.Helper()
+	var rows []decodedRow
+	var walk func(pageNo int, maxKey int64)
+	walk = func(pageNo int, maxKey int64) {
+		page := data[(pageNo-1)*sqlitePageSize : pageNo*sqlitePageSize]
+		off := 0
+		if pageNo == 1 {
+			off = sqliteHeaderSize
+		}
+		count := int(binary.BigEndian.Uint16(page[off+3:]))
+		switch page[off] {
+		case pageTypeLeaf:
+			for i := 0; i < count; i++ {
+				cell := page[binary.BigEndian.Uint16(page[off+leafHeaderSize+2*i:]):]
+				size, n := readVarint(cell)
+				cell = cell[n:]
+				rowid, n := readVarint(cell)
+				cell = cell[n:]
+				rows = append(rows, decodedRow{rowid: int64(rowid), values: decodeRecord(t, readPayload(t, data, cell, int(size)))})
+				if maxKey >= 0 && int64(rowid) > maxKey {
+					t.Errorf("page %d: rowid %d above parent key %d", pageNo, rowid, maxKey)
+				}
+			}
+		case pageTypeInterior:
+			if count == 0 {
+				t.Errorf("page %d: interior page without cells", pageNo)
+			}
+			for i := 0; i < count; i++ {
+				cell := page[binary.BigEndian.Uint16(page[off+interiorHdrSize+2*i:]):]
+				key, _ := readVarint(cell[4:])
+				walk(int(binary.BigEndian.Uint32(cell)), int64(key))
+			}
+			walk(int(binary.BigEndian.Uint32(page[off+8:])), -1)
+		default:
+			t.Fatalf("page %d: unexpected page type 0x%02x", pageNo, page[off])
+		}
+	}
+	walk(root, -1)
+	for i := 1; i < len(rows); i++ {
+		if rows[i].rowid <= rows[i-1].rowid {
+			t.Errorf("rowid %d after %d", rows[i].rowid, rows[i-1].rowid)
+		}
+	}
+	return rows
+}
+
+// readPayload 取出 cell 的 payload，超過頁面可放的長度時沿著 overflow 頁串列讀取
+func readPayload(t *testing.T, data, cell []byte, size int) []byte {
+	t.Helper()
+	const u = sqlitePageSize
+	maxLocal, minLocal := u-35, (u-12)*32/255-23
+	if size <= maxLocal {
+		return cell[:size]
+	}
+	local := minLocal + (size-minLocal)%(u-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	payload := append([]byte(nil), cell[:local]...)
+	next := int(binary.BigEndian.Uint32(cell[local:]))
+	for len(payload) < size {
+		if next == 0 {
+			t.Fatalf("overflow chain ends after %d of %d bytes", len(payload), size)
+		}
+		page := data[(next-1)*u : next*u]
+		n := min(size-len(payload), u-4)
+		payload = append(payload, page[4:4+n]...)
+		next = int(binary.BigEndian.Uint32(page))
+	}
+	if next != 0 {
+		t.Errorf("overflow chain continues past payload")
+	}
+	return payload
+}
+
+func TestSQLiteMultiPageTable(t *testing.T) {
+	db := &sqliteDB{}
+	notes := db.table("notes", "CREATE TABLE notes (id integer primary key, flds text, data blob, n integer, f real)")
+	small := db.table("small", "CREATE TABLE small (id integer primary key, v text)")
+
+	want := map[int64][]any{}
+	add := func(rowid int64, values ...any) {
+		notes.insert(rowid, values...)
+		want[rowid] = values
+	}
+	// 足夠多的列讓資料表需要多個 leaf 頁與 interior 頁；刻意以相反順序插入
+	for i := int64(3000); i >= 1; i-- {
+		add(i, nil, fmt.Sprintf("%s-%d", strings.Repeat("詞", int(i%40)), i), nil, i*1000-1<<40, float64(i)/4)
+	}
+	// overflow：跨多頁的文字與剛好超過一頁的 blob
+	add(5000, nil, strings.Repeat("overflow text ", 2000), nil, int64(-1), 0.0)
+	add(5001, nil, "", bytes.Repeat([]byte{0xAB}, sqlitePageSize), int64(math.MaxInt64), math.Inf(1))
+	small.insert(7, nil, "only row")
+
+	data, err := db.bytes()
+	if err != nil {
+		t.Fatalf("bytes: %v", err)
+	}
+
+	// 標頭
+	if len(data)%sqlitePageSize != 0 {
+		t.Fatalf("file size %d is not a multiple of the page size", len(data))
+	}
+	if string(data[:16]) != "SQLite format 3\x00" {
+		t.Errorf("magic = %q", data[:16])
+	}
+	if got := binary.BigEndian.Uint16(data[16:]); got != sqlitePageSize {
+		t.Errorf("page size = %d", got)
+	}
+	if got, want := binary.BigEndian.Uint32(data[28:]), uint32(len(data)/sqlitePageSize); got != want {
+		t.Errorf("header page count = %d, file has %d pages", got, want)
+	}
+	if got := binary.BigEndian.Uint32(data[56:]); got != 1 {
+		t.Errorf("text encoding = %d, want 1 (UTF-8)", got)
+	}
+	if data[21] != 64 || data[22] != 32 || data[23] != 32 {
+		t.Errorf("payload fractions = %d %d %d", data[21], data[22], data[23])
+	}
+
+	// sqlite_master
+	master := readTable(t, data, 1)
+	if len(master) != 2 {
+		t.Fatalf("sqlite_master has %d rows, want 2", len(master))
+	}
+	roots := map[string]int{}
+	for _, r := range master {
+		if r.values[0] != "table" || r.values[1] != r.values[2] {
+			t.Errorf("sqlite_master row = %v", r.values)
+		}
+		roots[r.values[1].(string)] = int(r.values[3].(int64))
+	}
+	if root := data[(roots["notes"]-1)*sqlitePageSize]; root != pageTypeInterior {
+		t.Errorf("notes root page type = 0x%02x, want interior", root)
+	}
+
+	rows := readTable(t, data, roots["notes"])
+	if len(rows) != len(want) {
+		t.Fatalf("notes has %d rows, want %d", len(rows), len(want))
+	}
+	for _, r := range rows {
+		if !reflect.DeepEqual(r.values, want[r.rowid]) {
+			t.Errorf("row %d = %.80v, want %.80v", r.rowid, r.values, want[r.rowid])
+		}
+	}
+
+	if rows := readTable(t, data, roots["small"]); len(rows) != 1 || rows[0].rowid != 7 || rows[0].values[1] != "only row" {
+		t.Errorf("small = %v", rows)
+	}
+
+	// 有 sqlite3 指令時再請 SQLite 本身檢查一次
+	if sqlite, err := exec.LookPath("sqlite3"); err == nil {
+		path := filepath.Join(t.TempDir(), "test.db")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command(sqlite, path, "PRAGMA integrity_check; SELECT count(*), sum(length(flds)) FROM notes;").CombinedOutput()
+		if err != nil || !strings.HasPrefix(string(out), "ok\n3002|") {
+			t.Errorf("sqlite3: %v\n%s", err, out)
+		}
+	}
+}
+
+func TestSQLiteDuplicateRowid(t *testing.T) {
+	db := &sqliteDB{}
+	tbl := db.table("t", "CREATE TABLE t (id integer primary key)")
+	tbl.insert(1, nil)
+	tbl.insert(1, nil)
+	if _, err := db.bytes(); err == nil {
+		t.Error("expected error for duplicate rowid")
+	}
+}
+
+func TestVarint(t *testing.T) {
+	for _, v := range []uint64{0, 127, 128, 16383, 16384, 1<<56 - 1, 1 << 56, math.MaxUint64} {
+		b := appendVarint(nil, v)
+		got, n := readVarint(append(b, 0xFF))
+		if got != v || n != len(b) {
+			t.Errorf("varint %d: decoded %d from %d bytes (encoded %d)", v, got, n, len(b))
+		}
+	}
+}
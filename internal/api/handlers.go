@@ -1,12 +1,13 @@
 package api
 
 import (
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 
+	"multilang-learner/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,37 +15,21 @@ import (
 
 // handleListFiles 獲取檔案列表
 func (r *Router) handleListFiles(c *gin.Context) {
-	files, err := r.fileService.List()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	query := services.ListQuery{
+		Search:   c.Query("q"),
+		Status:   c.Query("status"),
+		Language: c.Query("language"),
+		Sort:     c.Query("sort"),
+		Order:    c.Query("order"),
+		Cursor:   c.Query("cursor"),
 	}
-	c.JSON(http.StatusOK, gin.H{"files": files})
-}
+	query.Limit, _ = strconv.Atoi(c.Query("limit"))
 
-// handleUploadFile 上傳檔案
-func (r *Router) handleUploadFile(c *gin.Context) {
-	file, header, err := c.Request.FormFile("file")
+	result, err := r.fileService.List(query)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "無法讀取上傳檔案"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer file.Close()
-
-	// 讀取檔案內容
-	data, err := io.ReadAll(file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "無法讀取檔案內容"})
-		return
-	}
-
-	// 儲存檔案
-	result, err := r.fileService.Upload(header.Filename, data)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
 	c.JSON(http.StatusOK, result)
 }
 
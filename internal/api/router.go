@@ -3,6 +3,8 @@ package api
 import (
 	"net/http"
 
+	"multilang-learner/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,9 +18,8 @@ type Router struct {
 
 // FileServiceInterface 檔案服務介面
 type FileServiceInterface interface {
-	List() ([]interface{}, error)
+	List(query services.ListQuery) (*services.ListResult, error)
 	Get(id string) (interface{}, error)
-	Upload(filename string, data []byte) (interface{}, error)
 	Delete(id string) error
 	UpdateSettings(id string, settings interface{}) error
 	GetFilePath(id string) (string, error)
@@ -68,7 +69,6 @@ func (r *Router) setupRoutes() {
 		files := api.Group("/files")
 		{
 			files.GET("", r.handleListFiles)
-			files.POST("/upload", r.handleUploadFile)
 			files.GET("/:id", r.handleGetFile)
 			files.DELETE("/:id", r.handleDeleteFile)
 			files.POST("/:id/settings", r.handleUpdateSettings)
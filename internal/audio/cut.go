@@ -0,0 +1,64 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxCutsPerRun 每次 ffmpeg 最多同時輸出的段落數，避免一次開太多編碼器
+const maxCutsPerRun = 32
+
+// Cut 要從原曲切出的一個段落
+type Cut struct {
+	Start  time.Duration
+	End    time.Duration
+	Output string
+	Fade   time.Duration // 頭尾淡入淡出長度，0 表示不淡入淡出
+}
+
+// CutSegments 切出多個段落，每 maxCutsPerRun 個段落只解碼一次原曲
+// 以 atrim 依取樣點切割，不受壓縮格式影格邊界影響
+func (p *Processor) CutSegments(ctx context.Context, inputPath string, cuts []Cut) error {
+	for start := 0; start < len(cuts); start += maxCutsPerRun {
+		end := min(start+maxCutsPerRun, len(cuts))
+		if err := p.runner.FFmpeg(ctx, cutArgs(inputPath, cuts[start:end])...); err != nil {
+			return fmt.Errorf("cut segments %d-%d failed: %w", start+1, end, err)
+		}
+	}
+	return nil
+}
+
+// cutArgs 以 asplit 把原曲分給每個段落的 atrim，每個段落各自輸出一個 MP3
+func cutArgs(inputPath string, cuts []Cut) []string {
+	var graph strings.Builder
+	graph.WriteString(fmt.Sprintf("[0:a]asplit=%d", len(cuts)))
+	for i := range cuts {
+		graph.WriteString(fmt.Sprintf("[in%d]", i))
+	}
+	for i, c := range cuts {
+		d := c.End - c.Start
+		if d <= 0 {
+			d = 100 * time.Millisecond
+		}
+		graph.WriteString(fmt.Sprintf(";[in%d]atrim=start=%.6f:end=%.6f,asetpts=PTS-STARTPTS", i, c.Start.Seconds(), (c.Start + d).Seconds()))
+		if fade := min(c.Fade, d/2); fade > 0 {
+			graph.WriteString(fmt.Sprintf(",afade=t=in:st=0:d=%.3f,afade=t=out:st=%.3f:d=%.3f", fade.Seconds(), (d - fade).Seconds(), fade.Seconds()))
+		}
+		graph.WriteString(fmt.Sprintf("[out%d]", i))
+	}
+
+	args := []string{"-y", "-i", inputPath, "-filter_complex", graph.String()}
+	for i, c := range cuts {
+		args = append(args,
+			"-map", fmt.Sprintf("[out%d]", i),
+			"-acodec", "libmp3lame",
+			"-ar", "44100",
+			"-ac", "2",
+			"-b:a", "192k",
+			c.Output,
+		)
+	}
+	return args
+}
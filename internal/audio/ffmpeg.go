@@ -0,0 +1,159 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stderrTailSize 錯誤訊息保留的 stderr 長度（分析濾鏡的結果也在最後面）
+const stderrTailSize = 64 << 10
+
+// FFmpegError ffmpeg 或 ffprobe 執行失敗
+type FFmpegError struct {
+	Tool     string   // ffmpeg 或 ffprobe
+	Args     []string // 執行參數
+	ExitCode int      // -1 表示沒有正常結束（無法啟動或被取消）
+	Stderr   string   // stderr 的最後一段
+	Err      error
+}
+
+func (e *FFmpegError) Error() string {
+	if msg := e.Reason(); msg != "" {
+		return fmt.Sprintf("%s failed: %v: %s", e.Tool, e.Err, msg)
+	}
+	return fmt.Sprintf("%s failed: %v", e.Tool, e.Err)
+}
+
+func (e *FFmpegError) Unwrap() error {
+	return e.Err
+}
+
+// Reason stderr 中最後一行非空白的訊息，通常就是失敗原因
+func (e *FFmpegError) Reason() string {
+	lines := strings.Split(strings.TrimSpace(e.Stderr), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Runner 執行 ffmpeg 與 ffprobe
+// 收集 stderr 轉成 FFmpegError，並在 context 取消時終止程序
+type Runner struct {
+	verbose bool
+}
+
+func NewRunner(verbose bool) *Runner {
+	return &Runner{verbose: verbose}
+}
+
+// FFmpeg 執行 ffmpeg
+func (r *Runner) FFmpeg(ctx context.Context, args ...string) error {
+	_, err := r.run(ctx, "ffmpeg", args, nil)
+	return err
+}
+
+// FFmpegLog 執行 ffmpeg 並回傳 stderr（loudnorm 等分析濾鏡的輸出）
+func (r *Runner) FFmpegLog(ctx context.Context, args ...string) (string, error) {
+	return r.run(ctx, "ffmpeg", args, nil)
+}
+
+// FFmpegPipe 執行 ffmpeg，將 stdout 寫入 w（例如以 -f s16le - 輸出的 PCM）
+func (r *Runner) FFmpegPipe(ctx context.Context, w io.Writer, args ...string) error {
+	_, err := r.run(ctx, "ffmpeg", args, w)
+	return err
+}
+
+// FFprobe 執行 ffprobe 並回傳 stdout
+func (r *Runner) FFprobe(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout strings.Builder
+	if _, err := r.run(ctx, "ffprobe", args, &stdout); err != nil {
+		return nil, err
+	}
+	return []byte(stdout.String()), nil
+}
+
+// FFmpegProgress 執行 ffmpeg 並回報已處理的時間
+// 會自動加上 -nostats -progress pipe:1，解析 out_time_us
+func (r *Runner) FFmpegProgress(ctx context.Context, args []string, onProgress func(done time.Duration)) error {
+	args = append([]string{"-nostats", "-progress", "pipe:1"}, args...)
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), "=")
+			// out_time_ms 實際單位也是微秒
+			if !ok || (key != "out_time_us" && key != "out_time_ms") {
+				continue
+			}
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil {
+				onProgress(time.Duration(us) * time.Microsecond)
+			}
+		}
+		io.Copy(io.Discard, pr)
+	}()
+
+	_, err := r.run(ctx, "ffmpeg", args, pw)
+	pw.Close()
+	<-done
+	return err
+}
+
+// run 執行指令，回傳 stderr 的最後一段
+func (r *Runner) run(ctx context.Context, tool string, args []string, stdout io.Writer) (string, error) {
+	cmd := exec.CommandContext(ctx, tool, args...)
+	stderr := &tailBuffer{max: stderrTailSize}
+	cmd.Stderr = stderr
+	cmd.Stdout = stdout
+	if r.verbose {
+		cmd.Stderr = io.MultiWriter(stderr, os.Stderr)
+		if stdout == nil {
+			cmd.Stdout = os.Stdout
+		}
+	}
+
+	err := cmd.Run()
+	if err == nil {
+		return stderr.String(), nil
+	}
+
+	ffErr := &FFmpegError{Tool: tool, Args: args, ExitCode: -1, Stderr: stderr.String(), Err: err}
+	var exitErr *exec.ExitError
+	if ctx.Err() != nil {
+		ffErr.Err = ctx.Err()
+	} else if errors.As(err, &exitErr) {
+		ffErr.ExitCode = exitErr.ExitCode()
+	}
+	return ffErr.Stderr, ffErr
+}
+
+// tailBuffer 只保留最後 max 個位元組的 io.Writer
+type tailBuffer struct {
+	buf []byte
+	max int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = append(b.buf[:0], b.buf[len(b.buf)-b.max:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(b.buf)
+}
@@ -0,0 +1,211 @@
+// Package id3 在 MP3 的 ID3v2 標籤中寫入歌詞框架
+// ffmpeg 的 id3v2 muxer 會把非標準的標籤鍵寫成 TXXX，手機播放器讀不到；
+// 這裡在 ffmpeg 輸出後直接改寫標籤，加入不同步歌詞（USLT）與同步歌詞（SYLT）
+package id3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// ID3v2 標頭旗標
+const (
+	flagUnsync   = 0x80
+	flagExtended = 0x40
+	flagFooter   = 0x10
+)
+
+// 文字編碼：ID3v2.3 不支援 UTF-8，統一使用帶 BOM 的 UTF-16
+const encodingUTF16 = 1
+
+// SYLT 的時間單位與內容類型
+const (
+	syltMilliseconds = 2
+	syltLyrics       = 1
+)
+
+// SyncedText 同步歌詞的一行
+type SyncedText struct {
+	Time time.Duration
+	Text string
+}
+
+// Lyrics 要寫入的歌詞
+type Lyrics struct {
+	Language string       // ISO 639-2 語言代碼（三碼），空白時為 und
+	Text     string       // 不同步歌詞（USLT），空白時不寫入
+	Synced   []SyncedText // 同步歌詞（SYLT），空白時不寫入
+}
+
+// iso6392 常見語言的 ISO 639-1 → 639-2 對照
+var iso6392 = map[string]string{
+	"en": "eng", "zh": "zho", "ja": "jpn", "ko": "kor", "ru": "rus", "uk": "ukr",
+	"fr": "fra", "de": "deu", "es": "spa", "it": "ita", "pt": "por", "vi": "vie",
+	"th": "tha", "id": "ind",
+}
+
+// LanguageCode 將 ISO 639-1 語言代碼轉成 ID3 使用的 ISO 639-2，未知時回傳 und
+func LanguageCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(code) == 3 {
+		return code
+	}
+	if c, ok := iso6392[code]; ok {
+		return c
+	}
+	return "und"
+}
+
+// AddLyrics 在 MP3 的 ID3v2 標籤加入歌詞框架，沒有標籤時建立 ID3v2.3 標籤
+// 原有的 USLT、SYLT 框架會被取代，其他框架（標題、章節等）保留
+// 以暫存檔寫出後再改名取代原檔
+func AddLyrics(path string, lyrics Lyrics) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	r := bufio.NewReader(src)
+
+	version, frames, err := readTag(r)
+	if err != nil {
+		return err
+	}
+	if lyrics.Text != "" {
+		frames = append(frames, frame("USLT", usltBody(lyrics), version))
+	}
+	if len(lyrics.Synced) > 0 {
+		frames = append(frames, frame("SYLT", syltBody(lyrics), version))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	body := bytes.Join(frames, nil)
+	header := []byte{'I', 'D', '3', version, 0, 0}
+	header = append(header, syncsafe(uint32(len(body)))...)
+	w.Write(header)
+	w.Write(body)
+	if _, err := io.Copy(w, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readTag 讀取檔頭的 ID3v2 標籤，回傳版本與原有的框架（不含歌詞框架與補白）
+// 讀取後 r 停在音訊資料的開頭；沒有標籤時回傳 ID3v2.3 與空的框架列表
+func readTag(r *bufio.Reader) (byte, [][]byte, error) {
+	peek, err := r.Peek(10)
+	if err != nil || string(peek[:3]) != "ID3" {
+		// 沒有標籤（或檔案太短），整個檔案都是音訊資料
+		return 3, nil, nil
+	}
+	version, flags := peek[3], peek[5]
+	if version != 3 && version != 4 {
+		return 0, nil, fmt.Errorf("unsupported ID3v2.%d tag", version)
+	}
+	if flags&(flagUnsync|flagExtended|flagFooter) != 0 {
+		return 0, nil, fmt.Errorf("unsupported ID3v2 tag flags 0x%02x", flags)
+	}
+	size := unsyncsafe(peek[6:10])
+	r.Discard(10)
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, fmt.Errorf("read ID3v2 tag: %w", err)
+	}
+
+	var frames [][]byte
+	for len(body) >= 10 && body[0] != 0 {
+		id := string(body[0:4])
+		var n uint32
+		if version == 4 {
+			n = unsyncsafe(body[4:8])
+		} else {
+			n = binary.BigEndian.Uint32(body[4:8])
+		}
+		if int64(n) > int64(len(body)-10) {
+			return 0, nil, errors.New("invalid ID3v2 frame size")
+		}
+		if id != "USLT" && id != "SYLT" {
+			frames = append(frames, body[:10+n])
+		}
+		body = body[10+n:]
+	}
+	return version, frames, nil
+}
+
+// frame 組出框架（標頭 + 內容），ID3v2.4 的框架長度為 syncsafe 整數
+func frame(id string, body []byte, version byte) []byte {
+	out := make([]byte, 10, 10+len(body))
+	copy(out, id)
+	if version == 4 {
+		copy(out[4:8], syncsafe(uint32(len(body))))
+	} else {
+		binary.BigEndian.PutUint32(out[4:8], uint32(len(body)))
+	}
+	return append(out, body...)
+}
+
+// usltBody USLT：編碼、語言、空白的內容描述、歌詞全文
+func usltBody(lyrics Lyrics) []byte {
+	body := []byte{encodingUTF16}
+	body = append(body, LanguageCode(lyrics.Language)...)
+	body = append(body, utf16String("")...)
+	// 歌詞是框架的最後一個欄位，不需要結尾的 0
+	text := utf16String(lyrics.Text)
+	return append(body, text[:len(text)-2]...)
+}
+
+// syltBody SYLT：編碼、語言、毫秒時間、歌詞類型、空白的內容描述，接著每行文字與時間
+func syltBody(lyrics Lyrics) []byte {
+	body := []byte{encodingUTF16}
+	body = append(body, LanguageCode(lyrics.Language)...)
+	body = append(body, syltMilliseconds, syltLyrics)
+	body = append(body, utf16String("")...)
+	for _, line := range lyrics.Synced {
+		body = append(body, utf16String(line.Text)...)
+		body = binary.BigEndian.AppendUint32(body, uint32(max(line.Time.Milliseconds(), 0)))
+	}
+	return body
+}
+
+// utf16String 帶 BOM 的 UTF-16LE 字串，以兩個 0 位元組結尾
+func utf16String(s string) []byte {
+	out := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, u)
+	}
+	return append(out, 0, 0)
+}
+
+// syncsafe 將整數編成每個位元組只用 7 位元的 syncsafe 格式
+func syncsafe(n uint32) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+// unsyncsafe 解碼 syncsafe 整數
+func unsyncsafe(b []byte) uint32 {
+	return uint32(b[0])<<21 | uint32(b[1])<<14 | uint32(b[2])<<7 | uint32(b[3])
+}
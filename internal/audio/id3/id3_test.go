@@ -0,0 +1,215 @@
+package id3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// fakeAudio 標籤後面的「音訊資料」
+var fakeAudio = []byte{0xFF, 0xFB, 0x90, 0x64, 1, 2, 3, 4, 5}
+
+// writeMP3 寫出帶有 ID3v2.3 標籤（TIT2 + 補白）的假 MP3
+func writeMP3(t *testing.T, frames ...[]byte) string {
+	t.Helper()
+	body := bytes.Join(frames, nil)
+	body = append(body, make([]byte, 64)...) // 補白
+	var b bytes.Buffer
+	b.Write([]byte{'I', 'D', '3', 3, 0, 0})
+	b.Write(syncsafe(uint32(len(body))))
+	b.Write(body)
+	b.Write(fakeAudio)
+
+	path := filepath.Join(t.TempDir(), "song.mp3")
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// parse 讀回標籤的框架（id -> 內容）與剩下的音訊資料
+func parse(t *testing.T, path string) (map[string][]byte, []byte) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(bytes.NewReader(data))
+	_, frames, err := readTag(r)
+	if err != nil {
+		t.Fatalf("readTag: %v", err)
+	}
+	// readTag 會略過歌詞框架，這裡直接解析整個標籤
+	size := unsyncsafe(data[6:10])
+	body := data[10 : 10+size]
+	result := make(map[string][]byte)
+	for len(body) >= 10 && body[0] != 0 {
+		n := binary.BigEndian.Uint32(body[4:8])
+		result[string(body[:4])] = body[10 : 10+n]
+		body = body[10+n:]
+	}
+	if len(frames) != len(result)-countLyrics(result) {
+		t.Errorf("readTag kept %d frames, tag has %d non-lyrics frames", len(frames), len(result)-countLyrics(result))
+	}
+	return result, data[10+size:]
+}
+
+func countLyrics(frames map[string][]byte) int {
+	n := 0
+	for _, id := range []string{"USLT", "SYLT"} {
+		if _, ok := frames[id]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// decodeUTF16 解碼帶 BOM 的 UTF-16LE
+func decodeUTF16(t *testing.T, b []byte) string {
+	t.Helper()
+	if len(b) < 2 || b[0] != 0xFF || b[1] != 0xFE {
+		t.Fatalf("missing UTF-16LE BOM in % x", b)
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 2; i+1 < len(b); i += 2 {
+		u = append(u, binary.LittleEndian.Uint16(b[i:]))
+	}
+	return string(utf16.Decode(u))
+}
+
+// splitUTF16 在 UTF-16 的結尾 0 處切開
+func splitUTF16(b []byte) (string, []byte) {
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 && b[i+1] == 0 {
+			return string(b[:i]), b[i+2:]
+		}
+	}
+	return string(b), nil
+}
+
+func TestAddLyrics(t *testing.T) {
+	title := frame("TIT2", append([]byte{0}, "Song"...), 3)
+	path := writeMP3(t, title)
+
+	lyrics := Lyrics{
+		Language: "jpn",
+		Text:     "夢を見た\nI had a dream",
+		Synced: []SyncedText{
+			{Time: 1500 * time.Millisecond, Text: "夢を見た"},
+			{Time: 4 * time.Second, Text: "I had a dream"},
+		},
+	}
+	if err := AddLyrics(path, lyrics); err != nil {
+		t.Fatalf("AddLyrics: %v", err)
+	}
+
+	frames, audio := parse(t, path)
+	if !bytes.Equal(audio, fakeAudio) {
+		t.Errorf("audio data = % x, want % x", audio, fakeAudio)
+	}
+	if !bytes.Equal(frames["TIT2"], title[10:]) {
+		t.Errorf("TIT2 = %q, want it preserved", frames["TIT2"])
+	}
+
+	uslt := frames["USLT"]
+	if uslt == nil {
+		t.Fatal("USLT frame missing")
+	}
+	if uslt[0] != encodingUTF16 || string(uslt[1:4]) != "jpn" {
+		t.Errorf("USLT header = % x", uslt[:4])
+	}
+	descriptor, text := splitUTF16(uslt[4:])
+	if descriptor != "\xff\xfe" {
+		t.Errorf("USLT descriptor = %q, want empty", descriptor)
+	}
+	if got := decodeUTF16(t, text); got != lyrics.Text {
+		t.Errorf("USLT text = %q, want %q", got, lyrics.Text)
+	}
+
+	sylt := frames["SYLT"]
+	if sylt == nil {
+		t.Fatal("SYLT frame missing")
+	}
+	if sylt[0] != encodingUTF16 || string(sylt[1:4]) != "jpn" || sylt[4] != syltMilliseconds || sylt[5] != syltLyrics {
+		t.Errorf("SYLT header = % x", sylt[:6])
+	}
+	_, rest := splitUTF16(sylt[6:])
+	for i, want := range lyrics.Synced {
+		var raw string
+		raw, rest = splitUTF16(rest)
+		if got := decodeUTF16(t, []byte(raw)); got != want.Text {
+			t.Errorf("SYLT line %d = %q, want %q", i, got, want.Text)
+		}
+		if len(rest) < 4 {
+			t.Fatalf("SYLT line %d: missing timestamp", i)
+		}
+		if ms := binary.BigEndian.Uint32(rest); int64(ms) != want.Time.Milliseconds() {
+			t.Errorf("SYLT line %d time = %dms, want %dms", i, ms, want.Time.Milliseconds())
+		}
+		rest = rest[4:]
+	}
+	if len(rest) != 0 {
+		t.Errorf("SYLT has %d trailing bytes", len(rest))
+	}
+
+	// 再寫一次會取代舊的歌詞框架而不是重複
+	lyrics.Text = "second"
+	if err := AddLyrics(path, lyrics); err != nil {
+		t.Fatalf("AddLyrics again: %v", err)
+	}
+	frames, audio = parse(t, path)
+	_, text = splitUTF16(frames["USLT"][4:])
+	if got := decodeUTF16(t, text); got != "second" {
+		t.Errorf("USLT after rewrite = %q, want %q", got, "second")
+	}
+	if !bytes.Equal(audio, fakeAudio) {
+		t.Errorf("audio data changed after rewrite")
+	}
+}
+
+func TestAddLyricsWithoutTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bare.mp3")
+	if err := os.WriteFile(path, fakeAudio, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddLyrics(path, Lyrics{Text: "la la"}); err != nil {
+		t.Fatalf("AddLyrics: %v", err)
+	}
+	frames, audio := parse(t, path)
+	if frames["USLT"] == nil || string(frames["USLT"][1:4]) != "und" {
+		t.Errorf("USLT = % x, want frame with und language", frames["USLT"])
+	}
+	if _, ok := frames["SYLT"]; ok {
+		t.Error("SYLT written without synced lines")
+	}
+	if !bytes.Equal(audio, fakeAudio) {
+		t.Errorf("audio data = % x, want % x", audio, fakeAudio)
+	}
+}
+
+func TestAddLyricsRejectsUnsupportedTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v22.mp3")
+	data := append([]byte{'I', 'D', '3', 2, 0, 0, 0, 0, 0, 0}, fakeAudio...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddLyrics(path, Lyrics{Text: "x"}); err == nil {
+		t.Error("expected error for ID3v2.2 tag")
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Error("file modified after failed write")
+	}
+}
+
+func TestLanguageCode(t *testing.T) {
+	for in, want := range map[string]string{"ja": "jpn", "ZH": "zho", "": "und", "xx": "und", "eng": "eng"} {
+		if got := LanguageCode(in); got != want {
+			t.Errorf("LanguageCode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
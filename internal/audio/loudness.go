@@ -0,0 +1,112 @@
+package audio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EBU R128 正規化的預設峰值與響度範圍
+const (
+	loudnormTruePeak = -1.5 // dBTP
+	loudnormLRA      = 11.0 // LU
+)
+
+// LoudnessStats loudnorm 量測結果（EBU R128）
+type LoudnessStats struct {
+	IntegratedLUFS float64 // 整合響度 (LUFS)
+	TruePeak       float64 // 真實峰值 (dBTP)
+	LRA            float64 // 響度範圍 (LU)
+	Threshold      float64 // 閘限 (LUFS)
+	TargetOffset   float64 // 第二階段的增益補償
+}
+
+// loudnormOutput loudnorm print_format=json 的輸出（數值皆為字串）
+type loudnormOutput struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// MeasureLoudness 以 loudnorm 第一階段量測音檔響度
+func (p *Processor) MeasureLoudness(inputPath string, targetLUFS float64) (*LoudnessStats, error) {
+	filter := fmt.Sprintf("loudnorm=I=%.1f:TP=%.1f:LRA=%.1f:print_format=json", targetLUFS, loudnormTruePeak, loudnormLRA)
+	// loudnorm 的量測結果輸出到 stderr
+	output, err := p.runner.FFmpegLog(context.Background(), "-hide_banner", "-i", inputPath, "-af", filter, "-f", "null", "-")
+	if err != nil {
+		return nil, fmt.Errorf("loudnorm measure failed: %w", err)
+	}
+	return parseLoudnorm(output)
+}
+
+// NormalizeLoudness 以 loudnorm 兩階段將音檔正規化到目標整合響度
+// 回傳第一階段量測到的原始響度
+func (p *Processor) NormalizeLoudness(inputPath string, outputPath string, targetLUFS float64) (*LoudnessStats, error) {
+	stats, err := p.MeasureLoudness(inputPath, targetLUFS)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := fmt.Sprintf("loudnorm=I=%.1f:TP=%.1f:LRA=%.1f:measured_I=%.2f:measured_TP=%.2f:measured_LRA=%.2f:measured_thresh=%.2f:offset=%.2f:linear=true",
+		targetLUFS, loudnormTruePeak, loudnormLRA,
+		stats.IntegratedLUFS, stats.TruePeak, stats.LRA, stats.Threshold, stats.TargetOffset)
+	if p.verbose {
+		fmt.Printf("Loudness: %.2f LUFS, true peak %.2f dBTP, target %.1f LUFS\n",
+			stats.IntegratedLUFS, stats.TruePeak, targetLUFS)
+	}
+	err = p.runner.FFmpeg(context.Background(),
+		"-y",
+		"-i", inputPath,
+		"-af", filter,
+		"-acodec", "libmp3lame",
+		"-ar", "44100",
+		"-ac", "2",
+		"-b:a", "192k",
+		outputPath,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("loudnorm failed: %w", err)
+	}
+	return stats, nil
+}
+
+// parseLoudnorm 從 ffmpeg 輸出中取出最後一段 JSON
+func parseLoudnorm(output string) (*LoudnessStats, error) {
+	start := strings.LastIndex(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("loudnorm output not found")
+	}
+
+	var raw loudnormOutput
+	if err := json.Unmarshal([]byte(output[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("parse loudnorm output failed: %w", err)
+	}
+
+	stats := &LoudnessStats{}
+	fields := []struct {
+		value string
+		dst   *float64
+	}{
+		{raw.InputI, &stats.IntegratedLUFS},
+		{raw.InputTP, &stats.TruePeak},
+		{raw.InputLRA, &stats.LRA},
+		{raw.InputThresh, &stats.Threshold},
+		{raw.TargetOffset, &stats.TargetOffset},
+	}
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f.value), 64)
+		if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+			// 靜音片段會得到 -inf，無法正規化
+			return nil, fmt.Errorf("invalid loudnorm value %q", f.value)
+		}
+		*f.dst = v
+	}
+	return stats, nil
+}
@@ -1,56 +1,52 @@
 package audio
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type Merger struct {
 	verbose bool
+	runner  *Runner
 }
 
 func NewMerger(verbose bool) *Merger {
-	return &Merger{verbose: verbose}
+	return &Merger{verbose: verbose, runner: NewRunner(verbose)}
 }
 
+// MergeInterleaved 依播放模式交錯合併原曲與 TTS，pattern 的格式見 ParsePattern
+// 這裡沒有伴奏版本，karaoke 步驟會被略過
 func (m *Merger) MergeInterleaved(origFiles, ttsFiles []string, outputPath string, pattern string) error {
 	if len(origFiles) != len(ttsFiles) {
 		return fmt.Errorf("file count mismatch")
 	}
+	steps, err := ParsePattern(pattern)
+	if err != nil {
+		return err
+	}
 	var files []string
 	for i := 0; i < len(origFiles); i++ {
-		orig := origFiles[i]
-		tts := ttsFiles[i]
-		switch pattern {
-		case "original-tts":
-			if orig != "" {
-				files = append(files, orig)
-			}
-			if tts != "" {
-				files = append(files, tts)
-			}
-		case "original-tts-original":
-			if orig != "" {
-				files = append(files, orig)
-			}
-			if tts != "" {
-				files = append(files, tts)
-			}
-			if orig != "" {
-				files = append(files, orig)
-			}
-		case "tts-original":
-			if tts != "" {
-				files = append(files, tts)
+		for _, st := range steps {
+			switch st.Kind {
+			case StepOriginal:
+				if origFiles[i] != "" {
+					files = append(files, origFiles[i])
+				}
+			case StepTTS:
+				if ttsFiles[i] != "" {
+					files = append(files, ttsFiles[i])
+				}
+			case StepPause:
+				silence, err := m.Silence(filepath.Dir(outputPath), st.Pause)
+				if err != nil {
+					return err
+				}
+				files = append(files, silence)
 			}
-			if orig != "" {
-				files = append(files, orig)
-			}
-		default:
-			return fmt.Errorf("unknown pattern: %s", pattern)
 		}
 	}
 	if len(files) == 0 {
@@ -59,6 +55,27 @@ func (m *Merger) MergeInterleaved(origFiles, ttsFiles []string, outputPath strin
 	return m.concat(files, outputPath)
 }
 
+// Silence 在 dir 產生指定長度的靜音 MP3（同長度會重複使用）
+func (m *Merger) Silence(dir string, d time.Duration) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("silence_%dms.mp3", d.Milliseconds()))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	os.MkdirAll(dir, 0755)
+	err := m.runner.FFmpeg(context.Background(),
+		"-y",
+		"-f", "lavfi",
+		"-i", fmt.Sprintf("anullsrc=r=44100:cl=stereo:d=%.3f", d.Seconds()),
+		"-acodec", "libmp3lame",
+		"-b:a", "192k",
+		path,
+	)
+	if err != nil {
+		return "", fmt.Errorf("generate silence failed: %w", err)
+	}
+	return path, nil
+}
+
 func (m *Merger) concat(files []string, outputPath string) error {
 	if len(files) == 0 {
 		return fmt.Errorf("no files to concat")
@@ -95,10 +112,5 @@ func (m *Merger) concat(files []string, outputPath string) error {
 
 	os.MkdirAll(filepath.Dir(outputPath), 0755)
 	args := []string{"-f", "concat", "-safe", "0", "-i", concatPath, "-acodec", "libmp3lame", "-ar", "44100", "-ac", "2", "-b:a", "192k", "-y", outputPath}
-	cmd := exec.Command("ffmpeg", args...)
-	if m.verbose {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-	}
-	return cmd.Run()
+	return m.runner.FFmpeg(context.Background(), args...)
 }
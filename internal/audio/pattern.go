@@ -0,0 +1,155 @@
+package audio
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StepKind 播放模式中的片段類型
+type StepKind string
+
+const (
+	StepOriginal StepKind = "original"
+	StepTTS      StepKind = "tts"
+	StepKaraoke  StepKind = "karaoke" // 人聲減弱的原曲段落，讓學習者自己跟唱
+	StepPause    StepKind = "pause"
+)
+
+// 單一步驟的上限，避免產生過長的音檔
+const (
+	maxStepRepeat = 10
+	maxPause      = 30 * time.Second
+)
+
+// Step 播放模式中的一個步驟
+type Step struct {
+	Kind  StepKind
+	Pause time.Duration // 只有 StepPause 使用
+}
+
+// Pattern 每個段落的播放順序
+type Pattern []Step
+
+// 內建的播放模式
+var presetPatterns = map[string]string{
+	"original-tts":          "orig, tts",
+	"original-tts-original": "orig, tts, orig",
+	"tts-original":          "tts, orig",
+	"original-tts-karaoke":  "orig, tts, karaoke",
+}
+
+// ParsePattern 解析播放模式
+// 可以是內建名稱（original-tts、original-tts-original、tts-original、original-tts-karaoke），
+// 或以逗號分隔的自訂序列，例如 "orig, tts x2, pause 2s, orig"：
+//
+//	step   = ("orig" | "original" | "tts" | "karaoke" | "sing" | "pause" duration) [repeat]
+//	repeat = ("x" | "×" | "*") 1~10
+func ParsePattern(s string) (Pattern, error) {
+	s = strings.TrimSpace(s)
+	if preset, ok := presetPatterns[strings.ToLower(s)]; ok {
+		s = preset
+	}
+	if s == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var pattern Pattern
+	hasAudio := false
+	for i, token := range strings.Split(s, ",") {
+		steps, err := parseStep(token)
+		if err != nil {
+			return nil, fmt.Errorf("pattern step %d (%q): %w", i+1, strings.TrimSpace(token), err)
+		}
+		for _, st := range steps {
+			if st.Kind != StepPause {
+				hasAudio = true
+			}
+		}
+		pattern = append(pattern, steps...)
+	}
+	if !hasAudio {
+		return nil, fmt.Errorf("pattern must contain at least one orig, tts or karaoke step")
+	}
+	return pattern, nil
+}
+
+var stepRe = regexp.MustCompile(`^(?:(orig|original|tts|karaoke|sing)|(?:pause|silence)\s+(\S+?))(?:\s*[x×*]\s*(\d+))?$`)
+
+// parseStep 解析單一步驟，重複次數會展開成多個步驟
+func parseStep(token string) ([]Step, error) {
+	token = strings.ToLower(strings.TrimSpace(token))
+	if token == "" {
+		return nil, fmt.Errorf("empty step")
+	}
+	m := stepRe.FindStringSubmatch(token)
+	if m == nil {
+		if token == "pause" || token == "silence" {
+			return nil, fmt.Errorf("pause needs a duration, e.g. \"pause 2s\"")
+		}
+		return nil, fmt.Errorf("unknown step, expected orig, tts, karaoke or pause <duration> with optional x<count>")
+	}
+
+	var step Step
+	switch m[1] {
+	case "orig", "original":
+		step.Kind = StepOriginal
+	case "tts":
+		step.Kind = StepTTS
+	case "karaoke", "sing":
+		step.Kind = StepKaraoke
+	default:
+		d, err := time.ParseDuration(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pause duration %q", m[2])
+		}
+		if d <= 0 || d > maxPause {
+			return nil, fmt.Errorf("pause must be between 0 and %s", maxPause)
+		}
+		step = Step{Kind: StepPause, Pause: d}
+	}
+
+	repeat := 1
+	if m[3] != "" {
+		repeat, _ = strconv.Atoi(m[3])
+		if repeat < 1 || repeat > maxStepRepeat {
+			return nil, fmt.Errorf("repeat must be between 1 and %d", maxStepRepeat)
+		}
+	}
+
+	steps := make([]Step, repeat)
+	for i := range steps {
+		steps[i] = step
+	}
+	return steps, nil
+}
+
+// Has 播放模式是否包含指定類型的步驟
+func (p Pattern) Has(kind StepKind) bool {
+	for _, st := range p {
+		if st.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// String 以自訂序列格式輸出
+func (p Pattern) String() string {
+	parts := make([]string, len(p))
+	for i, st := range p {
+		switch st.Kind {
+		case StepOriginal:
+			parts[i] = "orig"
+		case StepTTS:
+			parts[i] = "tts"
+		case StepKaraoke:
+			parts[i] = "karaoke"
+		case StepPause:
+			parts[i] = "pause " + st.Pause.String()
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,125 @@
+package pcm
+
+import (
+	"math"
+	"time"
+)
+
+// silenceWindow 偵測靜音時計算 RMS 的視窗長度
+const silenceWindow = 10 * time.Millisecond
+
+// Stats 音量統計
+type Stats struct {
+	Peak   float64 // 最大絕對振幅（線性，0~1）
+	RMS    float64 // 均方根振幅（線性，0~1）
+	PeakDB float64 // 峰值 (dBFS)
+	RMSDB  float64 // 平均音量 (dBFS)，相當於 volumedetect 的 mean_volume
+}
+
+// Region 一段時間範圍
+type Region struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Bucket 波形摘要中的一格
+type Bucket struct {
+	Min float32 // 最小樣本值
+	Max float32 // 最大樣本值
+	RMS float32 // 均方根振幅
+}
+
+// Analyze 計算所有聲道的峰值與 RMS
+func (b *Buffer) Analyze() Stats {
+	var peak, sum float64
+	for _, s := range b.Samples {
+		v := math.Abs(float64(s))
+		if v > peak {
+			peak = v
+		}
+		sum += v * v
+	}
+	var rms float64
+	if len(b.Samples) > 0 {
+		rms = math.Sqrt(sum / float64(len(b.Samples)))
+	}
+	return Stats{Peak: peak, RMS: rms, PeakDB: ToDB(peak), RMSDB: ToDB(rms)}
+}
+
+// Silences 找出音量低於 thresholdDB 且持續至少 minDuration 的區段
+// 以 10ms 視窗的 RMS 判斷，結果精確到視窗長度
+func (b *Buffer) Silences(thresholdDB float64, minDuration time.Duration) []Region {
+	window := int(int64(b.SampleRate) * int64(silenceWindow) / int64(time.Second))
+	if window <= 0 {
+		return nil
+	}
+	frames := b.Frames()
+	threshold := math.Pow(10, thresholdDB/20)
+
+	var regions []Region
+	start := -1 // 目前靜音區段的起始 frame，-1 表示不在靜音中
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		r := Region{Start: b.frameTime(start), End: b.frameTime(end)}
+		if r.End-r.Start >= minDuration {
+			regions = append(regions, r)
+		}
+		start = -1
+	}
+
+	for from := 0; from < frames; from += window {
+		to := min(from+window, frames)
+		if b.rms(from, to) < threshold {
+			if start < 0 {
+				start = from
+			}
+		} else {
+			flush(from)
+		}
+	}
+	flush(frames)
+	return regions
+}
+
+// Waveform 將音訊平均分成 buckets 格，每格記錄最小、最大值與 RMS（各聲道混合）
+func (b *Buffer) Waveform(buckets int) []Bucket {
+	frames := b.Frames()
+	if buckets <= 0 || frames == 0 {
+		return nil
+	}
+	buckets = min(buckets, frames)
+
+	result := make([]Bucket, buckets)
+	for i := range result {
+		from := i * frames / buckets
+		to := (i + 1) * frames / buckets
+		bucket := Bucket{Min: 1, Max: -1}
+		for _, s := range b.Samples[from*b.Channels : to*b.Channels] {
+			bucket.Min = min(bucket.Min, s)
+			bucket.Max = max(bucket.Max, s)
+		}
+		bucket.RMS = float32(b.rms(from, to))
+		result[i] = bucket
+	}
+	return result
+}
+
+// rms 計算 frame 範圍 [from, to) 內所有聲道的 RMS
+func (b *Buffer) rms(from, to int) float64 {
+	samples := b.Samples[from*b.Channels : to*b.Channels]
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// frameTime 將 frame 位置轉為時間
+func (b *Buffer) frameTime(frame int) time.Duration {
+	return time.Duration(int64(frame) * int64(time.Second) / int64(b.SampleRate))
+}
@@ -0,0 +1,92 @@
+package pcm
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSilences(t *testing.T) {
+	// 1 秒聲音、0.5 秒數位靜音、1 秒聲音
+	const rate = 8000
+	var samples []float64
+	samples = append(samples, sine(0.5, 440, rate, rate)...)
+	samples = append(samples, make([]float64, rate/2)...)
+	samples = append(samples, sine(0.5, 440, rate, rate)...)
+
+	buf, err := DecodeWAV(bytes.NewReader(wavFile(wavFormatPCM, 16, rate, 1, s16le(samples))))
+	if err != nil {
+		t.Fatalf("DecodeWAV: %v", err)
+	}
+	regions := buf.Silences(-50, 200*time.Millisecond)
+	if len(regions) != 1 {
+		t.Fatalf("got %d silences %v, want 1", len(regions), regions)
+	}
+	const tolerance = silenceWindow
+	if d := regions[0].Start - time.Second; d < -tolerance || d > tolerance {
+		t.Errorf("silence start = %v, want 1s ± %v", regions[0].Start, tolerance)
+	}
+	if d := regions[0].End - 1500*time.Millisecond; d < -tolerance || d > tolerance {
+		t.Errorf("silence end = %v, want 1.5s ± %v", regions[0].End, tolerance)
+	}
+
+	// 比最短長度短的靜音不算
+	if regions := buf.Silences(-50, time.Second); len(regions) != 0 {
+		t.Errorf("got %v with 1s minimum, want none", regions)
+	}
+}
+
+func TestSilencesTrailing(t *testing.T) {
+	// 結尾的靜音延伸到檔案結束
+	const rate = 8000
+	samples := append(sine(0.5, 440, rate, rate/2), make([]float64, rate/2)...)
+	buf, err := DecodeRaw(bytes.NewReader(s16le(samples)), rate, 1)
+	if err != nil {
+		t.Fatalf("DecodeRaw: %v", err)
+	}
+	regions := buf.Silences(-50, 100*time.Millisecond)
+	if len(regions) != 1 || regions[0].End != time.Second {
+		t.Errorf("got %v, want one silence ending at 1s", regions)
+	}
+}
+
+func TestWaveformRamp(t *testing.T) {
+	// 由 -1 線性升到 1 的斜波：每格的最小、最大值與 RMS 都應單調
+	const frames = 10000
+	samples := make([]float64, frames)
+	for i := range samples {
+		samples[i] = 2*float64(i)/frames - 1
+	}
+	buf, err := DecodeRaw(bytes.NewReader(s16le(samples)), 8000, 1)
+	if err != nil {
+		t.Fatalf("DecodeRaw: %v", err)
+	}
+
+	buckets := buf.Waveform(100)
+	if len(buckets) != 100 {
+		t.Fatalf("got %d buckets, want 100", len(buckets))
+	}
+	for i, b := range buckets {
+		if b.Min > b.Max {
+			t.Fatalf("bucket %d: min %v > max %v", i, b.Min, b.Max)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := buckets[i-1]
+		if b.Min <= prev.Min || b.Max <= prev.Max {
+			t.Errorf("bucket %d not increasing: %+v after %+v", i, b, prev)
+		}
+		// RMS 在前半段遞減、後半段遞增
+		if i < 50 && b.RMS >= prev.RMS || i > 50 && b.RMS <= prev.RMS {
+			t.Errorf("bucket %d rms %v not monotonic after %v", i, b.RMS, prev.RMS)
+		}
+	}
+
+	if got := buf.Waveform(frames * 2); len(got) != frames {
+		t.Errorf("got %d buckets for more buckets than frames, want %d", len(got), frames)
+	}
+	if got := buf.Waveform(0); got != nil {
+		t.Errorf("Waveform(0) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,171 @@
+// Package pcm 解碼 WAV 與原始 PCM，並直接計算峰值、RMS、靜音區段與波形摘要
+package pcm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// MinDB 完全靜音時回傳的分貝值（與 ffmpeg volumedetect 的下限相近）
+const MinDB = -91.0
+
+// decodeChunkFrames 解碼時每次讀取的 frame 數
+const decodeChunkFrames = 16384
+
+// WAV 格式代碼
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// Buffer 解碼後的音訊，樣本以 [-1, 1] 的 float32 交錯存放
+type Buffer struct {
+	SampleRate int
+	Channels   int
+	Samples    []float32
+}
+
+// Frames 每個聲道的樣本數
+func (b *Buffer) Frames() int {
+	if b.Channels == 0 {
+		return 0
+	}
+	return len(b.Samples) / b.Channels
+}
+
+// Duration 音訊長度
+func (b *Buffer) Duration() time.Duration {
+	if b.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(b.Frames()) * time.Second / time.Duration(b.SampleRate)
+}
+
+// DecodeWAV 解碼 WAV（8/16/24/32 位元整數或 32/64 位元浮點 PCM）
+func DecodeWAV(r io.Reader) (*Buffer, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("read wav header: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, errors.New("not a RIFF/WAVE file")
+	}
+
+	var format, channels, bits uint16
+	var sampleRate uint32
+	haveFmt := false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("wav data chunk not found: %w", err)
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("invalid wav fmt chunk")
+			}
+			data := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, fmt.Errorf("read wav fmt chunk: %w", err)
+			}
+			format = binary.LittleEndian.Uint16(data[0:2])
+			channels = binary.LittleEndian.Uint16(data[2:4])
+			sampleRate = binary.LittleEndian.Uint32(data[4:8])
+			bits = binary.LittleEndian.Uint16(data[14:16])
+			if format == wavFormatExtensible && size >= 26 {
+				// 實際格式在 SubFormat GUID 的前兩個位元組
+				format = binary.LittleEndian.Uint16(data[24:26])
+			}
+			haveFmt = true
+
+		case "data":
+			if !haveFmt {
+				return nil, errors.New("wav data chunk before fmt chunk")
+			}
+			// ffmpeg 以管線輸出時 data 長度會是 0 或 0xFFFFFFFF，直接讀到結尾
+			var body io.Reader = r
+			hint := 0
+			if size != 0 && size != math.MaxUint32 {
+				body = io.LimitReader(r, int64(size))
+				hint = int(size)
+			}
+			return decode(body, hint, format, int(bits), int(sampleRate), int(channels))
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size+size%2)); err != nil {
+				return nil, fmt.Errorf("skip wav chunk %q: %w", id, err)
+			}
+		}
+	}
+}
+
+// DecodeRaw 解碼 16 位元 little-endian 的原始 PCM（ffmpeg -f s16le 的輸出）
+func DecodeRaw(r io.Reader, sampleRate, channels int) (*Buffer, error) {
+	return decode(r, 0, wavFormatPCM, 16, sampleRate, channels)
+}
+
+// decode 分段讀取樣本並轉成 float32，sizeHint 為已知的資料位元組數（未知時為 0）
+// 整段音訊會留在記憶體中：每個樣本 4 bytes，44.1 kHz 立體聲約每分鐘 21 MB，
+// 讀取緩衝區固定為 decodeChunkFrames 個 frame，不會再額外保留一份原始位元組
+func decode(r io.Reader, sizeHint int, format uint16, bits, sampleRate, channels int) (*Buffer, error) {
+	if channels <= 0 || sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid pcm layout: %d Hz, %d channels", sampleRate, channels)
+	}
+
+	var convert func([]byte) float32
+	switch {
+	case format == wavFormatPCM && bits == 8:
+		convert = func(b []byte) float32 { return (float32(b[0]) - 128) / 128 }
+	case format == wavFormatPCM && bits == 16:
+		convert = func(b []byte) float32 { return float32(int16(binary.LittleEndian.Uint16(b))) / 32768 }
+	case format == wavFormatPCM && bits == 24:
+		convert = func(b []byte) float32 {
+			v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+			return float32(v) / 8388608
+		}
+	case format == wavFormatPCM && bits == 32:
+		convert = func(b []byte) float32 { return float32(int32(binary.LittleEndian.Uint32(b))) / 2147483648 }
+	case format == wavFormatFloat && bits == 32:
+		convert = func(b []byte) float32 { return math.Float32frombits(binary.LittleEndian.Uint32(b)) }
+	case format == wavFormatFloat && bits == 64:
+		convert = func(b []byte) float32 { return float32(math.Float64frombits(binary.LittleEndian.Uint64(b))) }
+	default:
+		return nil, fmt.Errorf("unsupported pcm format %d with %d bits", format, bits)
+	}
+
+	width := bits / 8
+	frameSize := width * channels
+	samples := make([]float32, 0, sizeHint/width)
+	chunk := make([]byte, decodeChunkFrames*frameSize)
+	for {
+		n, err := io.ReadFull(r, chunk)
+		// 捨棄最後不完整的 frame
+		n -= n % frameSize
+		for i := 0; i < n; i += width {
+			samples = append(samples, convert(chunk[i:i+width]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read pcm data: %w", err)
+		}
+	}
+	return &Buffer{SampleRate: sampleRate, Channels: channels, Samples: samples}, nil
+}
+
+// ToDB 將線性振幅轉為 dBFS，0 以 MinDB 表示
+func ToDB(amplitude float64) float64 {
+	if amplitude <= 0 {
+		return MinDB
+	}
+	return math.Max(20*math.Log10(amplitude), MinDB)
+}
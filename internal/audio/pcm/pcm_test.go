@@ -0,0 +1,183 @@
+package pcm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+// s16le 將 [-1, 1] 的樣本編碼成 16 位元 little-endian PCM
+func s16le(samples []float64) []byte {
+	out := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(math.Round(s*32767))))
+	}
+	return out
+}
+
+// wavFile 組出只有 fmt 與 data 兩個 chunk 的 WAV 檔
+func wavFile(format uint16, bits, sampleRate, channels int, data []byte) []byte {
+	var b bytes.Buffer
+	le := func(v any) { binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("RIFF")
+	le(uint32(4 + 8 + 16 + 8 + len(data)))
+	b.WriteString("WAVE")
+	b.WriteString("fmt ")
+	le(uint32(16))
+	le(format)
+	le(uint16(channels))
+	le(uint32(sampleRate))
+	le(uint32(sampleRate * channels * bits / 8))
+	le(uint16(channels * bits / 8))
+	le(uint16(bits))
+	b.WriteString("data")
+	le(uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+// sine 產生指定振幅與頻率的正弦波
+func sine(amplitude, freq float64, sampleRate, frames int) []float64 {
+	out := make([]float64, frames)
+	for i := range out {
+		out[i] = amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate))
+	}
+	return out
+}
+
+func TestDecodeWAVSine(t *testing.T) {
+	const rate = 8000
+	data := s16le(sine(0.5, 440, rate, rate))
+	buf, err := DecodeWAV(bytes.NewReader(wavFile(wavFormatPCM, 16, rate, 1, data)))
+	if err != nil {
+		t.Fatalf("DecodeWAV: %v", err)
+	}
+	if buf.SampleRate != rate || buf.Channels != 1 || buf.Frames() != rate {
+		t.Fatalf("layout = %d Hz, %d ch, %d frames", buf.SampleRate, buf.Channels, buf.Frames())
+	}
+	if d := buf.Duration().Seconds(); d != 1 {
+		t.Errorf("duration = %vs, want 1s", d)
+	}
+
+	stats := buf.Analyze()
+	if math.Abs(stats.Peak-0.5) > 0.001 {
+		t.Errorf("peak = %v, want 0.5", stats.Peak)
+	}
+	if want := 0.5 / math.Sqrt2; math.Abs(stats.RMS-want) > 0.001 {
+		t.Errorf("rms = %v, want %v", stats.RMS, want)
+	}
+	if math.Abs(stats.PeakDB-ToDB(0.5)) > 0.02 {
+		t.Errorf("peak dB = %v, want %v", stats.PeakDB, ToDB(0.5))
+	}
+}
+
+func TestDecodeWAVFormats(t *testing.T) {
+	float32le := make([]byte, 8)
+	binary.LittleEndian.PutUint32(float32le, math.Float32bits(0.25))
+	binary.LittleEndian.PutUint32(float32le[4:], math.Float32bits(-0.75))
+
+	tests := []struct {
+		name   string
+		format uint16
+		bits   int
+		data   []byte
+		want   []float32
+	}{
+		{"8-bit", wavFormatPCM, 8, []byte{128, 192, 0}, []float32{0, 0.5, -1}},
+		{"24-bit", wavFormatPCM, 24, []byte{0, 0, 0x40, 0, 0, 0xC0}, []float32{0.5, -0.5}},
+		{"float32", wavFormatFloat, 32, float32le, []float32{0.25, -0.75}},
+	}
+	for _, tt := range tests {
+		buf, err := DecodeWAV(bytes.NewReader(wavFile(tt.format, tt.bits, 8000, 1, tt.data)))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(buf.Samples) != len(tt.want) {
+			t.Errorf("%s: got %d samples, want %d", tt.name, len(buf.Samples), len(tt.want))
+			continue
+		}
+		for i, s := range buf.Samples {
+			if s != tt.want[i] {
+				t.Errorf("%s: sample %d = %v, want %v", tt.name, i, s, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestDecodeRawStereo(t *testing.T) {
+	// 左聲道 0.5、右聲道 -0.25，最後多一個不完整的 frame
+	interleaved := []float64{0.5, -0.25, 0.5, -0.25, 0.5}
+	buf, err := DecodeRaw(bytes.NewReader(s16le(interleaved)), 44100, 2)
+	if err != nil {
+		t.Fatalf("DecodeRaw: %v", err)
+	}
+	if buf.Frames() != 2 || len(buf.Samples) != 4 {
+		t.Fatalf("got %d frames, %d samples; want 2, 4", buf.Frames(), len(buf.Samples))
+	}
+	if math.Abs(float64(buf.Samples[1])+0.25) > 0.001 {
+		t.Errorf("right channel = %v, want -0.25", buf.Samples[1])
+	}
+}
+
+func TestDecodeRawLarge(t *testing.T) {
+	// 超過一個讀取緩衝區，確認分段讀取不會遺漏或重複樣本
+	frames := decodeChunkFrames*2 + 123
+	samples := make([]float64, frames)
+	for i := range samples {
+		samples[i] = float64(i%200)/200 - 0.5
+	}
+	buf, err := DecodeRaw(bytes.NewReader(s16le(samples)), 8000, 1)
+	if err != nil {
+		t.Fatalf("DecodeRaw: %v", err)
+	}
+	if buf.Frames() != frames {
+		t.Fatalf("got %d frames, want %d", buf.Frames(), frames)
+	}
+	for _, i := range []int{0, decodeChunkFrames - 1, decodeChunkFrames, frames - 1} {
+		if math.Abs(float64(buf.Samples[i])-samples[i]) > 0.001 {
+			t.Errorf("sample %d = %v, want %v", i, buf.Samples[i], samples[i])
+		}
+	}
+}
+
+func TestDecodeWAVRejects(t *testing.T) {
+	valid := wavFile(wavFormatPCM, 16, 8000, 1, s16le([]float64{0, 0.5}))
+	dataFirst := append([]byte{}, valid[:12]...)
+	dataFirst = append(dataFirst, "data\x00\x00\x00\x00"...)
+
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr string
+	}{
+		{"empty", nil, "read wav header"},
+		{"truncated header", valid[:8], "read wav header"},
+		{"not riff", append([]byte("RIFX"), valid[4:]...), "not a RIFF/WAVE"},
+		{"truncated fmt", valid[:20], "read wav fmt chunk"},
+		{"no data chunk", valid[:36], "data chunk not found"},
+		{"data before fmt", dataFirst, "before fmt"},
+		{"adpcm", wavFile(2, 4, 8000, 1, []byte{0, 0}), "unsupported pcm format"},
+		{"zero channels", wavFile(wavFormatPCM, 16, 8000, 0, nil), "invalid pcm layout"},
+	}
+	for _, tt := range tests {
+		_, err := DecodeWAV(bytes.NewReader(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestToDB(t *testing.T) {
+	if got := ToDB(0); got != MinDB {
+		t.Errorf("ToDB(0) = %v, want %v", got, MinDB)
+	}
+	if got := ToDB(1); got != 0 {
+		t.Errorf("ToDB(1) = %v, want 0", got)
+	}
+	if got := ToDB(0.5); math.Abs(got+6.0206) > 0.001 {
+		t.Errorf("ToDB(0.5) = %v, want -6.02", got)
+	}
+}
@@ -1,11 +1,12 @@
 package audio
 
 import (
-	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"multilang-learner/internal/audio/pcm"
 	"multilang-learner/internal/subtitle"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -22,10 +23,11 @@ type AudioStats struct {
 
 type Processor struct {
 	verbose bool
+	runner  *Runner
 }
 
 func NewProcessor(verbose bool) *Processor {
-	return &Processor{verbose: verbose}
+	return &Processor{verbose: verbose, runner: NewRunner(verbose)}
 }
 
 func (p *Processor) SplitByLyrics(inputPath string, lines []subtitle.Line, outputDir string) ([]string, error) {
@@ -33,38 +35,25 @@ func (p *Processor) SplitByLyrics(inputPath string, lines []subtitle.Line, outpu
 		return nil, err
 	}
 	var results []string
+	var cuts []Cut
 	for i, line := range lines {
 		safeName := sanitize(line.Text)
 		if len(safeName) > 30 {
 			safeName = safeName[:30]
 		}
 		outPath := filepath.Join(outputDir, fmt.Sprintf("%03d_%s.mp3", i+1, safeName))
-		if err := p.cut(inputPath, line.StartTime, line.EndTime, outPath); err != nil {
-			return nil, fmt.Errorf("cut %d failed: %w", i+1, err)
-		}
+		cuts = append(cuts, Cut{Start: line.StartTime, End: line.EndTime, Output: outPath})
 		results = append(results, outPath)
 	}
-	return results, nil
-}
-
-func (p *Processor) cut(inputPath string, start, end time.Duration, outputPath string) error {
-	startSec := start.Seconds()
-	duration := end.Seconds() - startSec
-	if duration <= 0 {
-		duration = 0.1
-	}
-	args := []string{"-y", "-i", inputPath, "-ss", fmt.Sprintf("%.3f", startSec), "-t", fmt.Sprintf("%.3f", duration), "-acodec", "libmp3lame", "-ar", "44100", "-ac", "2", "-b:a", "192k", outputPath}
-	cmd := exec.Command("ffmpeg", args...)
-	if p.verbose {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	if err := p.CutSegments(context.Background(), inputPath, cuts); err != nil {
+		return nil, err
 	}
-	return cmd.Run()
+	return results, nil
 }
 
 // CutSegment cuts a segment from the audio file
 func (p *Processor) CutSegment(inputPath string, start, end time.Duration, outputPath string) error {
-	return p.cut(inputPath, start, end, outputPath)
+	return p.CutSegments(context.Background(), inputPath, []Cut{{Start: start, End: end, Output: outputPath}})
 }
 
 func sanitize(s string) string {
@@ -77,58 +66,91 @@ func sanitize(s string) string {
 	return s
 }
 
+// analysisSampleRate 音量分析時解碼的取樣率（單聲道），與波形分析相同
+const analysisSampleRate = 22050
+
 // AnalyzeVolume 分析音檔的音量統計
-// 使用 ffmpeg 的 volumedetect filter 來取得峰值和平均音量
+// WAV（例如 TTS 的輸出）直接解碼，其他格式以 ffmpeg 解碼成單聲道 PCM 後計算峰值與 RMS
 func (p *Processor) AnalyzeVolume(inputPath string) (*AudioStats, error) {
-	// 使用 ffmpeg volumedetect filter
-	args := []string{
-		"-i", inputPath,
-		"-af", "volumedetect",
-		"-f", "null",
-		"-",
+	buf, err := decodeWAVFile(inputPath)
+	if err != nil {
+		buf, err = p.DecodePCM(context.Background(), inputPath, analysisSampleRate, 1)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("analyze volume failed: %w", err)
+	}
+	stats := buf.Analyze()
+	return &AudioStats{
+		PeakDB:    stats.PeakDB,
+		MeanDB:    stats.RMSDB,
+		MaxVolume: stats.Peak,
+	}, nil
+}
 
-	cmd := exec.Command("ffmpeg", args...)
-	// volumedetect 輸出到 stderr
-	output, err := cmd.CombinedOutput()
+// decodeWAVFile 以 pcm.DecodeWAV 讀取 .wav 檔，其他副檔名或不支援的編碼回傳錯誤
+func decodeWAVFile(path string) (*pcm.Buffer, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".wav") {
+		return nil, fmt.Errorf("not a wav file: %s", path)
+	}
+	f, err := os.Open(path)
 	if err != nil {
-		// ffmpeg 可能回傳非零但輸出有效，檢查輸出
-		if len(output) == 0 {
-			return nil, fmt.Errorf("volumedetect failed: %w", err)
-		}
+		return nil, err
 	}
+	defer f.Close()
+	return pcm.DecodeWAV(f)
+}
 
-	stats := &AudioStats{}
-	outputStr := string(output)
-
-	// 解析輸出
-	// [Parsed_volumedetect_0 @ xxx] max_volume: -5.2 dB
-	// [Parsed_volumedetect_0 @ xxx] mean_volume: -18.3 dB
-	scanner := bufio.NewScanner(strings.NewReader(outputStr))
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "max_volume:") {
-			// 提取 max_volume 值
-			parts := strings.Split(line, "max_volume:")
-			if len(parts) >= 2 {
-				valStr := strings.TrimSpace(strings.Replace(parts[1], "dB", "", 1))
-				if val, err := strconv.ParseFloat(strings.TrimSpace(valStr), 64); err == nil {
-					stats.PeakDB = val
-				}
-			}
-		} else if strings.Contains(line, "mean_volume:") {
-			// 提取 mean_volume 值
-			parts := strings.Split(line, "mean_volume:")
-			if len(parts) >= 2 {
-				valStr := strings.TrimSpace(strings.Replace(parts[1], "dB", "", 1))
-				if val, err := strconv.ParseFloat(strings.TrimSpace(valStr), 64); err == nil {
-					stats.MeanDB = val
-				}
-			}
-		}
+// DecodePCM 以 ffmpeg 將音檔解碼成指定取樣率與聲道數的 16 位元 PCM
+// 解碼結果整段放在記憶體（每個樣本 4 bytes），呼叫端應以較低的取樣率或單聲道分析長音檔
+func (p *Processor) DecodePCM(ctx context.Context, inputPath string, sampleRate, channels int) (*pcm.Buffer, error) {
+	pr, pw := io.Pipe()
+	errc := make(chan error, 1)
+	go func() {
+		err := p.runner.FFmpegPipe(ctx, pw,
+			"-v", "error",
+			"-i", inputPath,
+			"-vn",
+			"-f", "s16le",
+			"-acodec", "pcm_s16le",
+			"-ar", strconv.Itoa(sampleRate),
+			"-ac", strconv.Itoa(channels),
+			"-",
+		)
+		pw.CloseWithError(err)
+		errc <- err
+	}()
+
+	buf, err := pcm.DecodeRaw(pr, sampleRate, channels)
+	pr.Close()
+	if ffErr := <-errc; ffErr != nil {
+		return nil, ffErr
 	}
+	return buf, err
+}
 
-	return stats, nil
+// EncodeMP3 將音檔轉成段落使用的 MP3 格式（44.1 kHz 立體聲 192k）
+func (p *Processor) EncodeMP3(inputPath string, outputPath string) error {
+	return p.runner.FFmpeg(context.Background(),
+		"-y",
+		"-i", inputPath,
+		"-acodec", "libmp3lame",
+		"-ar", "44100",
+		"-ac", "2",
+		"-b:a", "192k",
+		outputPath,
+	)
+}
+
+// copyAudio 副檔名相同時直接複製，否則轉成 MP3
+func (p *Processor) copyAudio(inputPath string, outputPath string) error {
+	if !strings.EqualFold(filepath.Ext(inputPath), filepath.Ext(outputPath)) {
+		return p.EncodeMP3(inputPath, outputPath)
+	}
+	input, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, input, 0644)
 }
 
 // AdjustVolume 調整音檔音量
@@ -148,12 +170,7 @@ func (p *Processor) AdjustVolume(inputPath string, outputPath string, adjustment
 		outputPath,
 	}
 
-	cmd := exec.Command("ffmpeg", args...)
-	if p.verbose {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-	}
-	return cmd.Run()
+	return p.runner.FFmpeg(context.Background(), args...)
 }
 
 // NormalizeToTarget 將音檔音量正規化到目標峰值
@@ -170,12 +187,7 @@ func (p *Processor) NormalizeToTarget(inputPath string, outputPath string, targe
 
 	// 如果調整量太小（小於 0.5 dB），直接複製檔案
 	if adjustmentDB > -0.5 && adjustmentDB < 0.5 {
-		// 直接複製
-		input, err := os.ReadFile(inputPath)
-		if err != nil {
-			return err
-		}
-		return os.WriteFile(outputPath, input, 0644)
+		return p.copyAudio(inputPath, outputPath)
 	}
 
 	// 調整音量
@@ -207,13 +219,61 @@ func (p *Processor) MatchVolume(segmentPath string, ttsPath string, outputPath s
 
 	// 如果調整量太小，直接複製
 	if adjustmentDB > -0.5 && adjustmentDB < 0.5 {
-		input, err := os.ReadFile(ttsPath)
-		if err != nil {
-			return err
-		}
-		return os.WriteFile(outputPath, input, 0644)
+		return p.copyAudio(ttsPath, outputPath)
 	}
 
 	// 調整 TTS 音量
 	return p.AdjustVolume(ttsPath, outputPath, adjustmentDB)
 }
+
+// Duration 以 ffprobe 讀取音檔時長
+func (p *Processor) Duration(inputPath string) (time.Duration, error) {
+	output, err := p.runner.FFprobe(context.Background(),
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		inputPath,
+	)
+	if err != nil {
+		return 0, err
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse duration failed: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// TimeStretch 以 atempo 改變播放速度（保持音高）
+func (p *Processor) TimeStretch(inputPath string, outputPath string, speed float64) error {
+	err := p.runner.FFmpeg(context.Background(),
+		"-y",
+		"-i", inputPath,
+		"-filter:a", fmt.Sprintf("atempo=%.3f", speed),
+		"-acodec", "libmp3lame",
+		"-b:a", "192k",
+		outputPath,
+	)
+	if err != nil {
+		return fmt.Errorf("time stretch failed: %w", err)
+	}
+	return nil
+}
+
+// RemoveVocals 以相位抵消減弱置中的人聲，輸出伴奏版本
+// 左右聲道相減會消去兩邊相同的訊號（通常是人聲），單聲道音檔沒有效果
+func (p *Processor) RemoveVocals(inputPath string, outputPath string) error {
+	err := p.runner.FFmpeg(context.Background(),
+		"-y",
+		"-i", inputPath,
+		"-af", "pan=stereo|c0=0.5*c0-0.5*c1|c1=0.5*c1-0.5*c0",
+		"-acodec", "libmp3lame",
+		"-ar", "44100",
+		"-b:a", "192k",
+		outputPath,
+	)
+	if err != nil {
+		return fmt.Errorf("remove vocals failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package exercise
+
+import "strings"
+
+// chineseWords 中文常用詞（簡體），切詞時以正向最長比對使用；繁體由 traditionalChars 轉換產生
+const chineseWords = `
+我们 你们 他们 她们 它们 咱们 自己 大家 别人 人们 什么 怎么 怎样 怎么样 为什么
+这样 那样 这里 那里 哪里 这儿 那儿 这些 那些 这个 那个 哪个 一切 所有 每个 每天
+每次 有些 一些 一点 一下 一起 一直 一定 一样 一般 一边 一生 一次 一天 一年 一半
+一点点 一辈子 一瞬间 一会儿
+已经 曾经 正在 还是 或者 但是 可是 因为 所以 如果 虽然 然后 而且 不过 只是 只有
+只要 就是 也许 或许 可能 应该 必须 需要 能够 可以 不能 不会 不要 不用 没有 没什么
+不是 就算 即使 哪怕 终于 突然 忽然 渐渐 慢慢 静静 轻轻 悄悄 默默 深深 好好 常常
+总是 永远 从来 始终 依然 仍然 还有 再次 重新 马上 立刻 刚才 现在 以前 以后 之前
+之后 后来 将来 未来 过去 今天 明天 昨天 今晚 今夜 昨夜 明年 去年 今年 时候 时间
+时光 瞬间 刹那 片刻 从前 最后 最初 开始 结束 原来 其实 真的 也是 都是 还要 不再
+再也 越来越 难道 到底 究竟 几乎 非常 特别 十分 很多 许多 太多 那么 这么 多么 如此
+彼此 互相 于是 否则 除了 关于 对于 为了 因此 而已 罢了 不管 无论 不断 一直到 到处
+处处 一起来 不如 好像 仿佛 似乎 一定要
+世界 地方 城市 国家 家乡 故乡 远方 天空 大地 大海 海洋 海边 沙滩 星星 星空 月亮
+月光 太阳 阳光 天涯 海角 风雨 彩虹 云朵 白云 雪花 花朵 玫瑰 蝴蝶 森林 河流 街道
+路口 窗外 窗口 房间 门口 角落 身边 心里 心中 心情 心事 心跳 心灵 心底 灵魂 眼睛
+眼泪 眼神 泪水 泪光 笑容 微笑 声音 歌声 歌曲 音乐 旋律 故事 回忆 记忆 梦想 梦境
+美梦 希望 理想 青春 岁月 人生 生命 生活 爱情 感情 友情 亲情 朋友 情人 爱人 恋人
+女孩 男孩 女人 男人 孩子 父母 妈妈 爸爸 家人 老师 学生 同学 名字 样子 影子 背影
+身影 脚步 双手 肩膀 嘴唇 头发 衣服 礼物 照片 电话 手机 电影 电视 电脑 问题 答案
+秘密 谎言 承诺 誓言 约定 勇气 力量 自由 幸福 快乐 痛苦 寂寞 孤单 孤独 温柔 温暖
+黑夜 夜晚 夜空 白天 早晨 黎明 黄昏 夕阳 晚霞 春天 夏天 秋天 冬天 季节 天气 风景
+旅行 旅程 方向 距离 终点 起点 道路 东西 事情 意义 理由 原因 结果 机会 方法 办法
+地球 宇宙 天堂 明星 舞台 灯光 烟火 烟花 玻璃 镜子 钢琴 吉他 咖啡 城堡 童话 天使
+王子 公主 英雄 命运 缘分 思念 感觉 时代 历史 文化 中国 台湾 北京 上海 香港 日本
+美国 中文 英文 汉语 工作 学校 公司 医院 飞机 火车 汽车 早上 晚上 中午 下午 上午
+周末 生日 新年 节日 明天见
+喜欢 爱上 知道 认识 觉得 以为 相信 明白 了解 理解 懂得 记得 忘记 记住 想起 想念
+怀念 等待 等着 期待 盼望 害怕 担心 放心 放弃 坚持 离开 回来 回去 回家 出发 到达
+走过 经过 走进 走出 走向 看见 看到 听见 听到 遇见 遇到 找到 寻找 失去 得到 拥有
+拥抱 亲吻 牵手 分开 分手 告别 离别 相遇 相逢 重逢 陪伴 守护 保护 照顾 原谅 后悔
+哭泣 流泪 唱歌 跳舞 飞翔 奔跑 追求 追逐 追寻 实现 改变 变成 成为 成长 长大 开心
+伤心 难过 感动 感谢 谢谢 对不起 没关系 再见 告诉 说话 回答 发现 决定 选择 努力
+学习 休息 睡觉 起来 出来 进来 下来 上来 过来 起床 吃饭 喝酒 看书 旅游 活着 死去
+存在 消失 出现 发生 继续 停止 燃烧 闪烁 闪耀 飘落 坠落 沉默 呼吸 心动 心碎 心疼
+祝福 祈祷 梦见 醒来 哭着 笑着 看着 想着 等到 听说 说过 爱过 来到 留下 留在 带着
+带走 放开 放手 拒绝 接受 承认 答应 感受 享受 习惯 打开 关上 开门
+美丽 漂亮 可爱 善良 勇敢 坚强 脆弱 简单 复杂 重要 容易 困难 安静 热闹 清楚 明亮
+黑暗 美好 完美 真实 虚假 疯狂 永恒 短暂 遥远 漫长 年轻 辛苦 舒服 满足 幸运 熟悉
+陌生 普通 干净 新鲜 骄傲 高兴 愉快 悲伤 无聊 认真 自然 奇怪 厉害 可怕 可惜 寒冷
+炎热 模糊 清晰 灿烂 辉煌 浪漫 天真
+一个 两个 几个 这次 那次 下次 上次 第一 第一次 唯一 全部 部分 其他 其中 之间 中间
+里面 外面 上面 下面 前面 后面 旁边 对面
+`
+
+// chineseExtraWords 無法由逐字轉換得到的繁體寫法
+const chineseExtraWords = `頭髮 彷彿`
+
+// traditionalChars 詞表用到的簡繁對照，每兩個字為一組（簡、繁）
+// 一簡對多繁的字只取詞表中最常見的用法（例如 发 → 發），其他寫法列在 chineseExtraWords
+const traditionalChars = `
+丽麗东東两兩个個为為么麼义義乐樂习習乡鄉书書云雲亲親们們会會伤傷儿兒关關兴興决決净淨几幾则則刚剛刹剎别別办辦动動医醫单單
+历歷厉厲双雙发發变變后後听聽国國坚堅坠墜声聲处處复複够夠头頭妈媽学學实實对對寻尋将將岁歲师師带帶干乾应應开開弃棄强強忆憶
+怀懷总總恋戀恒恆惯慣护護担擔拥擁择擇断斷无無时時暂暫机機杂雜来來样樣梦夢欢歡气氣汉漢没沒泪淚渐漸温溫湾灣满滿滩灘灯燈灵靈
+灿燦点點烁爍烂爛烟煙烧燒热熱爱愛牵牽独獨现現电電疯瘋离離礼禮祷禱简簡系係约約终終经經结結绝絕继繼续續缘緣罢罷脑腦脚腳节節
+虚虛虽雖见見视視觉覺认認记記许許论論识識诉訴话話该該语語说說诺諾谅諒谎謊谢謝轻輕辈輩辉輝边邊达達过過运運还還这這进進远遠
+选選遥遙钢鋼镜鏡长長门門闪閃问問间間闹鬧阳陽难難静靜须須顾顧题題风風飘飄飞飛饭飯马馬骄驕鲜鮮黄黃车車从從于於着著里裡
+`
+
+// chineseDict 中文詞表（簡體與繁體）與最長詞的字數
+var (
+	chineseDict       = make(map[string]bool)
+	chineseMaxWordLen int
+)
+
+func init() {
+	toTraditional := make(map[rune]rune)
+	pairs := []rune(strings.Join(strings.Fields(traditionalChars), ""))
+	for i := 0; i+1 < len(pairs); i += 2 {
+		toTraditional[pairs[i]] = pairs[i+1]
+	}
+	add := func(word string) {
+		chineseDict[word] = true
+		chineseMaxWordLen = max(chineseMaxWordLen, len([]rune(word)))
+	}
+	for _, word := range strings.Fields(chineseWords) {
+		add(word)
+		add(strings.Map(func(r rune) rune {
+			if t, ok := toTraditional[r]; ok {
+				return t
+			}
+			return r
+		}, word))
+	}
+	for _, word := range strings.Fields(chineseExtraWords) {
+		add(word)
+	}
+}
+
+// SegmentChinese 以詞表正向最長比對切開一段漢字，詞表沒有的字各自成詞
+func SegmentChinese(text string) []string {
+	runes := []rune(text)
+	var words []string
+	for i := 0; i < len(runes); {
+		n := min(chineseMaxWordLen, len(runes)-i)
+		for ; n > 1; n-- {
+			if chineseDict[string(runes[i:i+n])] {
+				break
+			}
+		}
+		words = append(words, string(runes[i:i+n]))
+		i += n
+	}
+	return words
+}
@@ -0,0 +1,51 @@
+package exercise
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Blank 克漏字中被挖空的位置
+const Blank = "____"
+
+// Cloze 從 text 隨機挖掉 blanks 個詞（中文先以詞表切詞），回傳題目與依序排列的答案
+// contentOnly 時只挑實詞，沒有實詞時改從所有詞中挑；沒有任何詞時 ok 為 false
+func Cloze(text string, blanks int, contentOnly bool, rng *rand.Rand) (prompt string, answers []string, ok bool) {
+	tokens := TokenizeWords(text)
+	var words, content []int
+	for i, t := range tokens {
+		if !t.Word {
+			continue
+		}
+		words = append(words, i)
+		if t.IsContentWord() {
+			content = append(content, i)
+		}
+	}
+	candidates := words
+	if contentOnly && len(content) > 0 {
+		candidates = content
+	}
+	if len(candidates) == 0 || blanks <= 0 {
+		return "", nil, false
+	}
+
+	picked := rng.Perm(len(candidates))[:min(blanks, len(candidates))]
+	sort.Ints(picked)
+	hidden := make(map[int]bool, len(picked))
+	for _, p := range picked {
+		hidden[candidates[p]] = true
+	}
+
+	var b strings.Builder
+	for i, t := range tokens {
+		if hidden[i] {
+			b.WriteString(Blank)
+			answers = append(answers, t.Text)
+		} else {
+			b.WriteString(t.Text)
+		}
+	}
+	return b.String(), answers, true
+}
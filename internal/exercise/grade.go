@@ -0,0 +1,91 @@
+package exercise
+
+import "math"
+
+// maxDiffRunes 逐字比對的長度上限，超過的部分不列入差異
+const maxDiffRunes = 1000
+
+// 差異類型
+const (
+	DiffEqual   = "equal"   // 答對的字
+	DiffMissing = "missing" // 正解中有、答案中缺少的字
+	DiffExtra   = "extra"   // 答案中多出來的字
+)
+
+// DiffOp 逐字差異中的一段
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Result 一個答案的批改結果
+type Result struct {
+	Correct  bool     `json:"correct"`
+	Score    float64  `json:"score"`    // 0~1，正規化後的相似度
+	Expected string   `json:"expected"` // 正規化後的正解
+	Answer   string   `json:"answer"`   // 正規化後的答案
+	Diff     []DiffOp `json:"diff"`
+}
+
+// Grade 正規化後比對答案，回傳是否正確、相似度與逐字差異
+func Grade(expected, answer string) Result {
+	e, a := Normalize(expected), Normalize(answer)
+	diff, common := Diff(e, a)
+	score := 1.0
+	if total := len([]rune(e)) + len([]rune(a)); total > 0 {
+		score = math.Round(float64(2*common)/float64(total)*100) / 100
+	}
+	return Result{Correct: e == a, Score: score, Expected: e, Answer: a, Diff: diff}
+}
+
+// Diff 以最長共同子序列計算逐字差異，並回傳共同的字數
+func Diff(expected, answer string) ([]DiffOp, int) {
+	e, a := []rune(expected), []rune(answer)
+	e, a = e[:min(len(e), maxDiffRunes)], a[:min(len(a), maxDiffRunes)]
+
+	// lcs[i][j] 為 e[i:] 與 a[j:] 的最長共同子序列長度
+	lcs := make([][]uint16, len(e)+1)
+	for i := range lcs {
+		lcs[i] = make([]uint16, len(a)+1)
+	}
+	for i := len(e) - 1; i >= 0; i-- {
+		for j := len(a) - 1; j >= 0; j-- {
+			if e[i] == a[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := []DiffOp{}
+	push := func(op string, r rune) {
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += string(r)
+			return
+		}
+		ops = append(ops, DiffOp{Op: op, Text: string(r)})
+	}
+	i, j := 0, 0
+	for i < len(e) && j < len(a) {
+		switch {
+		case e[i] == a[j]:
+			push(DiffEqual, e[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			push(DiffMissing, e[i])
+			i++
+		default:
+			push(DiffExtra, a[j])
+			j++
+		}
+	}
+	for ; i < len(e); i++ {
+		push(DiffMissing, e[i])
+	}
+	for ; j < len(a); j++ {
+		push(DiffExtra, a[j])
+	}
+	return ops, int(lcs[0][0])
+}
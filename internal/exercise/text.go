@@ -0,0 +1,187 @@
+// Package exercise 從歌詞產生克漏字等練習，並以正規化與逐字差異批改答案
+package exercise
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Script 字元所屬的文字類型，連續同類型的字元組成一個詞
+type Script int
+
+const (
+	ScriptNone     Script = iota // 空白與標點
+	ScriptWord                   // 以空白分詞的文字（拉丁、西里爾、韓文等）與數字
+	ScriptHan                    // 漢字
+	ScriptHiragana               // 平假名
+	ScriptKatakana               // 片假名（含長音符號）
+)
+
+// Token 一段文字，Word 為 false 時是空白或標點
+type Token struct {
+	Text   string
+	Word   bool
+	Script Script
+}
+
+func classify(r rune) Script {
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return ScriptHan
+	case unicode.Is(unicode.Hiragana, r):
+		return ScriptHiragana
+	case unicode.Is(unicode.Katakana, r), r == 'ー':
+		return ScriptKatakana
+	case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r):
+		return ScriptWord
+	}
+	return ScriptNone
+}
+
+// isCJK 不以空白分詞的文字
+func isCJK(r rune) bool {
+	c := classify(r)
+	return c == ScriptHan || c == ScriptHiragana || c == ScriptKatakana
+}
+
+// Tokenize 將一行歌詞切成詞與分隔符號
+// 以空白分詞的語言依空白與標點切開；中文與日文沒有空白，依漢字、平假名、片假名的邊界切開
+// 詞中的撇號（don't）與連字號（well-known）視為詞的一部分
+func Tokenize(s string) []Token {
+	runes := []rune(s)
+	var tokens []Token
+	for i := 0; i < len(runes); {
+		kind := classify(runes[i])
+		j := i + 1
+		for j < len(runes) {
+			next := classify(runes[j])
+			if next == kind {
+				j++
+				continue
+			}
+			// 撇號與連字號前後都是字母時不切開
+			if kind == ScriptWord && isJoiner(runes[j]) && j+1 < len(runes) && classify(runes[j+1]) == ScriptWord {
+				j += 2
+				continue
+			}
+			break
+		}
+		tokens = append(tokens, Token{Text: string(runes[i:j]), Word: kind != ScriptNone, Script: kind})
+		i = j
+	}
+	return tokens
+}
+
+// TokenizeWords 與 Tokenize 相同，但沒有假名的行視為中文，漢字段再以詞表切成詞
+// 日文的漢字段多半是一個詞（或詞幹），維持原樣
+func TokenizeWords(s string) []Token {
+	tokens := Tokenize(s)
+	for _, t := range tokens {
+		if t.Script == ScriptHiragana || t.Script == ScriptKatakana {
+			return tokens
+		}
+	}
+	var words []Token
+	for _, t := range tokens {
+		if t.Script != ScriptHan {
+			words = append(words, t)
+			continue
+		}
+		for _, w := range SegmentChinese(t.Text) {
+			words = append(words, Token{Text: w, Word: true, Script: ScriptHan})
+		}
+	}
+	return words
+}
+
+func isJoiner(r rune) bool {
+	return r == '\'' || r == '’' || r == '-'
+}
+
+// IsContentWord 是否為實詞（適合挖空）
+// 漢字與片假名視為實詞（排除中文常見的單字虛詞與代名詞），平假名多半是助詞或詞尾；其他文字排除常見虛詞與過短的詞
+func (t Token) IsContentWord() bool {
+	switch t.Script {
+	case ScriptHan:
+		return !chineseStopWords[t.Text]
+	case ScriptKatakana:
+		return true
+	case ScriptWord:
+		word := strings.ToLower(t.Text)
+		if len([]rune(word)) < 3 || stopWords[word] {
+			return false
+		}
+		for _, r := range word {
+			if unicode.IsLetter(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stopWords 常見的虛詞（英、西、法、德、義、葡）
+var stopWords = toSet(`
+the and but for nor yet you your yours are was were been being have has had his her hers its our ours their theirs
+him she they them this that these those what which who whom whose with from into onto upon than then there here
+when where why how all any both each few more most some such not only own same too very can will just don't
+i'm you're it's that's can't won't let's
+los las del una unos unas por con para que como pero más sus muy sin sobre este esta eso esa
+les des une dans pour pas par sur avec est sont qui que mais ses tes mes nous vous ils elles
+der die das und den dem des ein eine einen nicht mit von auf für ist sind ich du wir ihr sie
+gli che non per una con del della sono come anche
+não uma com por para que dos das mais como seu sua
+`)
+
+// chineseStopWords 中文常見的單字虛詞與代名詞（簡繁）
+var chineseStopWords = toSet(`
+的 地 得 了 着 著 过 過 吗 嗎 呢 吧 啊 呀 哦 啦 么 麼 我 你 妳 他 她 它 是 在 和 与 與 也 都 就 不 这 這 那 个 個
+`)
+
+func toSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+// Normalize 正規化答案以便比對：全形半形統一（NFKC）、轉小寫、片假名轉平假名，
+// 撇號直接移除（don't 與 dont 相同），其他標點與符號視為空白（hello,world 與 hello world 相同），
+// 空白合併為一個（中日文字之間的空白直接移除）
+func Normalize(s string) string {
+	var b strings.Builder
+	var last rune
+	pendingSpace := false
+	for _, r := range norm.NFKC.String(s) {
+		switch {
+		case r == '\'' || r == '’':
+			// 撇號直接略過
+			// 撇號直接略過
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r):
+			r = foldKana(unicode.ToLower(r))
+			if pendingSpace && last != 0 && !isCJK(last) && !isCJK(r) {
+				b.WriteByte(' ')
+			}
+			pendingSpace = false
+			b.WriteRune(r)
+			last = r
+		default:
+			pendingSpace = true
+		}
+	}
+	return b.String()
+}
+
+// foldKana 片假名轉為對應的平假名
+func foldKana(r rune) rune {
+	switch {
+	case r >= 'ァ' && r <= 'ヶ':
+		return r - 0x60
+	case r == 'ヽ' || r == 'ヾ':
+		return r - 0x60
+	}
+	return r
+}
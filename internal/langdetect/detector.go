@@ -55,3 +55,30 @@ combined := strings.Join(texts, " ")
 info := whatlanggo.Detect(combined)
 return info.Lang.String()
 }
+
+// DetectCode 回傳 ISO 639-1 語言代碼（無法判斷時回傳空字串）
+func (d *Detector) DetectCode(text string) string {
+info := whatlanggo.Detect(text)
+if info.Confidence < 0.3 {
+return ""
+}
+return info.Lang.Iso6391()
+}
+
+// NormalizeCode 將 ISO 639-3 代碼（例如 rus、jpn）轉為 ISO 639-1
+func NormalizeCode(code string) string {
+code = strings.ToLower(strings.TrimSpace(code))
+if len(code) != 3 {
+return code
+}
+switch code {
+case "zho", "chi":
+return "zh"
+case "und", "zxx", "mul":
+return ""
+}
+if short := whatlanggo.CodeToLang(code).Iso6391(); short != "" {
+return short
+}
+return code
+}
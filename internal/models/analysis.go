@@ -0,0 +1,41 @@
+package models
+
+// AudioAnalysis 音訊分析結果（音量、靜音區段與波形摘要）
+type AudioAnalysis struct {
+	Duration   float64          `json:"duration"`   // 秒
+	SampleRate int              `json:"sampleRate"` // 分析時解碼的取樣率
+	PeakDB     float64          `json:"peakDb"`     // 峰值 (dBFS)
+	RMSDB      float64          `json:"rmsDb"`      // 平均音量 (dBFS)
+	Silences   []TimeRange      `json:"silences"`   // 靜音區段
+	Waveform   []WaveformBucket `json:"waveform"`   // 波形摘要，依時間平均分格
+}
+
+// TimeRange 時間範圍（秒）
+type TimeRange struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// WaveformBucket 波形摘要中的一格（單聲道，-1~1）
+type WaveformBucket struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	RMS float64 `json:"rms"`
+}
+
+// Waveform 播放器用的波形峰值
+type Waveform struct {
+	Duration float64       `json:"duration"`           // 秒
+	Points   int           `json:"points"`             // 峰值點數
+	Peaks    []float32     `json:"peaks"`              // 依序為每個點的 min、max（-1~1），長度為 points × 2
+	Segments []TimeRange   `json:"segments,omitempty"` // 段落邊界（只有整首原曲）
+	Markers  []LyricMarker `json:"markers,omitempty"`  // 歌詞行起點（只有整首原曲）
+}
+
+// LyricMarker 波形上的歌詞標記
+type LyricMarker struct {
+	Index   int     `json:"index"`
+	Time    float64 `json:"time"` // 秒
+	Text    string  `json:"text"`
+	Skipped bool    `json:"skipped,omitempty"` // 在起始行之前，不會產生段落
+}
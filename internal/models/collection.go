@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// Collection 歌曲集合（例如「俄語入門」、「JLPT N3 歌曲」）
+type Collection struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	FileIDs     []string  `json:"fileIds"` // 依加入順序排列
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// HasFile 檢查集合是否包含檔案
+func (c *Collection) HasFile(fileID string) bool {
+	for _, id := range c.FileIDs {
+		if id == fileID {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectionExportJob 集合導出工作：在背景逐首導出後打包成 ZIP
+type CollectionExportJob struct {
+	ID           string            `json:"id"`
+	CollectionID string            `json:"collectionId"`
+	Status       ExportStatus      `json:"status"`
+	Progress     float64           `json:"progress"` // 0-100
+	Message      string            `json:"message,omitempty"`
+	Total        int               `json:"total"`            // 集合內的檔案數
+	Completed    int               `json:"completed"`        // 已處理完（成功或失敗）的檔案數
+	Failed       map[string]string `json:"failed,omitempty"` // 檔案 ID -> 失敗原因
+	Size         int64             `json:"size,omitempty"`   // ZIP 位元組
+	CreatedAt    time.Time         `json:"createdAt"`
+	FinishedAt   *time.Time        `json:"finishedAt,omitempty"`
+}
+
+// TagCount 標籤與使用次數
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
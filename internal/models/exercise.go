@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// ExerciseType 練習題類型
+type ExerciseType string
+
+const (
+	ExerciseCloze     ExerciseType = "cloze"     // 克漏字：歌詞挖空
+	ExerciseDictation ExerciseType = "dictation" // 聽寫：聽段落音訊寫出整句
+	ExerciseMatch     ExerciseType = "match"     // 配對：將歌詞與翻譯配對
+)
+
+// ExerciseOptions 產生練習題的選項，零值使用預設
+type ExerciseOptions struct {
+	Type         ExerciseType `json:"type"`
+	Count        int          `json:"count,omitempty"`        // 題數
+	Blanks       int          `json:"blanks,omitempty"`       // 克漏字每題挖空的詞數
+	ContentWords bool         `json:"contentWords,omitempty"` // 克漏字只挖實詞
+	Seed         int64        `json:"seed,omitempty"`         // 亂數種子，0 表示隨機
+}
+
+// Exercise 一組練習題
+type Exercise struct {
+	ID        string          `json:"id"`
+	FileID    string          `json:"fileId"`
+	Type      ExerciseType    `json:"type"`
+	Options   ExerciseOptions `json:"options"`
+	Choices   []string        `json:"choices,omitempty"` // 配對題的翻譯選項（已打亂）
+	Items     []ExerciseItem  `json:"items"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// ExerciseItem 一道題目
+type ExerciseItem struct {
+	ID           string   `json:"id"`
+	LineIndex    int      `json:"lineIndex"`              // 歌詞行索引（聽寫題為段落的第一行）
+	SegmentIndex *int     `json:"segmentIndex,omitempty"` // 聽寫題的段落
+	Prompt       string   `json:"prompt,omitempty"`       // 題目：挖空後的歌詞或要配對的原文
+	Hint         string   `json:"hint,omitempty"`         // 翻譯提示
+	AudioURL     string   `json:"audioUrl,omitempty"`     // 聽寫題的段落音訊
+	Blanks       int      `json:"blanks"`                 // 需要作答的空格數
+	Answers      []string `json:"answers,omitempty"`      // 正解（出題時不回傳）
+}
+
+// WithoutAnswers 移除正解後的副本（給前端作答用）
+func (e *Exercise) WithoutAnswers() *Exercise {
+	copied := *e
+	copied.Items = make([]ExerciseItem, len(e.Items))
+	for i, item := range e.Items {
+		item.Answers = nil
+		copied.Items[i] = item
+	}
+	return &copied
+}
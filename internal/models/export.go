@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// ExportFormat 導出格式
+type ExportFormat string
+
+const (
+	ExportMP3  ExportFormat = "mp3"  // MP3，含同步歌詞與 ID3 CHAP/CTOC 章節
+	ExportM4A  ExportFormat = "m4a"  // AAC（MP4 容器）
+	ExportM4B  ExportFormat = "m4b"  // 有聲書格式（AAC），含章節
+	ExportOpus ExportFormat = "opus" // Opus（Ogg 容器）
+	ExportWAV  ExportFormat = "wav"  // 無壓縮 PCM
+	ExportFLAC ExportFormat = "flac" // 無損壓縮
+	ExportMP4  ExportFormat = "mp4"  // 學習影片（封面或純色背景 + 字幕）
+)
+
+// 影片字幕模式
+const (
+	SubtitlesBurn  = "burn"  // 字幕直接燒進畫面
+	SubtitlesTrack = "track" // 以獨立字幕軌加入（可在播放器開關）
+)
+
+// ExportOptions 導出選項
+type ExportOptions struct {
+	Format     ExportFormat `json:"format"`
+	Bitrate    int          `json:"bitrate,omitempty"`    // kbps，無損格式忽略
+	SampleRate int          `json:"sampleRate,omitempty"` // Hz
+	Channels   int          `json:"channels,omitempty"`   // 1 或 2
+	Pattern    string       `json:"pattern,omitempty"`    // 每個段落的播放模式，空白時為原曲 + TTS × 設定的重複次數
+	Repeat     int          `json:"repeat,omitempty"`     // 每個段落重複播放模式的次數
+	Gap        string       `json:"gap,omitempty"`        // 段落之間插入的靜音，例如 "1.5s"
+	Crossfade  string       `json:"crossfade,omitempty"`  // 相鄰片段交叉淡化的長度，例如 "80ms"，空白表示直接串接
+	FullSong   bool         `json:"fullSong,omitempty"`   // 最後再完整播放一次原曲
+	Subtitles  string       `json:"subtitles,omitempty"`  // 影片字幕模式（burn 或 track），只用於 mp4
+}
+
+// ExportStatus 導出工作狀態
+type ExportStatus string
+
+const (
+	ExportQueued  ExportStatus = "queued"
+	ExportRunning ExportStatus = "running"
+	ExportDone    ExportStatus = "done"
+	ExportFailed  ExportStatus = "error"
+)
+
+// ExportJob 導出工作（完成後保留為導出紀錄）
+type ExportJob struct {
+	ID           string        `json:"id"`
+	FileID       string        `json:"fileId"`
+	Options      ExportOptions `json:"options"`
+	Status       ExportStatus  `json:"status"`
+	Progress     float64       `json:"progress"` // 0-100
+	Message      string        `json:"message,omitempty"`
+	Path         string        `json:"path,omitempty"`
+	LyricsPath   string        `json:"lyricsPath,omitempty"`
+	SubtitlePath string        `json:"subtitlePath,omitempty"` // ASS 字幕（影片導出）
+	Size         int64         `json:"size,omitempty"`         // 位元組
+	Duration     float64       `json:"duration,omitempty"`     // 秒
+	CreatedAt    time.Time     `json:"createdAt"`
+	FinishedAt   *time.Time    `json:"finishedAt,omitempty"`
+}
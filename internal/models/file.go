@@ -17,10 +17,27 @@ const (
 
 // FileSettings 檔案設定
 type FileSettings struct {
-	PrimaryLanguage        string `json:"primaryLanguage"`        // 主要語言 (en, zh)
-	TTSRepeatCount         int    `json:"ttsRepeatCount"`         // TTS 重複次數
-	StartLineIndex         int    `json:"startLineIndex"`         // 歌詞起點行索引
-	ShowChineseTranslation bool   `json:"showChineseTranslation"` // 顯示中文翻譯
+	PrimaryLanguage        string  `json:"primaryLanguage"`          // 主要語言 (en, zh)
+	TTSRepeatCount         int     `json:"ttsRepeatCount"`           // TTS 重複次數
+	StartLineIndex         int     `json:"startLineIndex"`           // 歌詞起點行索引
+	ShowChineseTranslation bool    `json:"showChineseTranslation"`   // 顯示中文翻譯
+	PlaybackSpeed          float64 `json:"playbackSpeed,omitempty"`  // 原曲段落播放速度（0 視為 1.0）
+	TTSSpeed               float64 `json:"ttsSpeed,omitempty"`       // TTS 播放速度（0 視為 1.0）
+	LoudnessTarget         float64 `json:"loudnessTarget,omitempty"` // 段落與 TTS 的目標整合響度（LUFS），0 表示沿用峰值匹配
+	FadeMs                 int     `json:"fadeMs,omitempty"`         // 切割段落時頭尾的淡入淡出長度（毫秒），0 表示不淡入淡出
+	ExtractVocabulary      bool    `json:"extractVocabulary"`        // 處理時一併擷取詞彙（需要 Gemini API key）
+	Romanize               bool    `json:"romanize"`                 // 處理時請翻譯模型補齊本地無法確定的羅馬拼音（日文漢字、多音字，需要 Gemini API key）
+}
+
+// SongMetadata 歌曲資訊（來自容器標籤與 LRC 標籤）
+type SongMetadata struct {
+	Title       string `json:"title,omitempty"`
+	Artist      string `json:"artist,omitempty"`
+	Album       string `json:"album,omitempty"`
+	Language    string `json:"language,omitempty"`    // ISO 639-1 語言代碼
+	TrackNumber int    `json:"trackNumber,omitempty"` // 曲目編號
+	HasCover    bool   `json:"hasCover"`              // 是否有內嵌封面
+	CoverPath   string `json:"coverPath,omitempty"`   // 封面圖片路徑
 }
 
 // MusicFile 音樂檔案
@@ -28,15 +45,23 @@ type MusicFile struct {
 	ID          string       `json:"id"`
 	Filename    string       `json:"filename"`
 	Filepath    string       `json:"filepath"`
-	Duration    float64      `json:"duration"` // 秒
+	Duration    float64      `json:"duration"`              // 秒
+	ContentHash string       `json:"contentHash,omitempty"` // 原始檔案 SHA-256
+	Metadata    SongMetadata `json:"metadata"`
+	Tags        []string     `json:"tags,omitempty"` // 使用者自訂標籤
 	UploadedAt  time.Time    `json:"uploadedAt"`
 	Status      FileStatus   `json:"status"`
 	Settings    FileSettings `json:"settings"`
 	LyricCount  int          `json:"lyricCount"` // 歌詞行數
 	ErrorMsg    string       `json:"errorMsg,omitempty"`
 	ProcessedAt *time.Time   `json:"processedAt,omitempty"`
+
+	LastPracticedAt *time.Time `json:"lastPracticedAt,omitempty"` // 最後練習時間
 }
 
+// defaultFadeMs 新檔案預設的段落淡入淡出長度（毫秒）
+const defaultFadeMs = 20
+
 // DefaultSettings 預設設定
 func DefaultSettings() FileSettings {
 	return FileSettings{
@@ -44,6 +69,7 @@ func DefaultSettings() FileSettings {
 		TTSRepeatCount:         2,
 		StartLineIndex:         0,
 		ShowChineseTranslation: true,
+		FadeMs:                 defaultFadeMs,
 	}
 }
 
@@ -51,10 +77,16 @@ func DefaultSettings() FileSettings {
 type FileListItem struct {
 	ID         string     `json:"id"`
 	Filename   string     `json:"filename"`
+	Title      string     `json:"title,omitempty"`
+	Artist     string     `json:"artist,omitempty"`
 	Duration   float64    `json:"duration"`
 	Status     FileStatus `json:"status"`
 	LyricCount int        `json:"lyricCount"`
+	Language   string     `json:"language,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
 	UploadedAt time.Time  `json:"uploadedAt"`
+
+	LastPracticedAt *time.Time `json:"lastPracticedAt,omitempty"`
 }
 
 // ToListItem 轉換為列表項目
@@ -62,9 +94,15 @@ func (f *MusicFile) ToListItem() FileListItem {
 	return FileListItem{
 		ID:         f.ID,
 		Filename:   f.Filename,
+		Title:      f.Metadata.Title,
+		Artist:     f.Metadata.Artist,
 		Duration:   f.Duration,
 		Status:     f.Status,
 		LyricCount: f.LyricCount,
+		Language:   f.Metadata.Language,
+		Tags:       f.Tags,
 		UploadedAt: f.UploadedAt,
+
+		LastPracticedAt: f.LastPracticedAt,
 	}
 }
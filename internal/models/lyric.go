@@ -10,22 +10,32 @@ type Translations struct {
 // LyricLine 歌詞行
 type LyricLine struct {
 	Index        int          `json:"index"`
-	Timestamp    string       `json:"timestamp"`    // "00:01.79"
-	StartTime    float64      `json:"startTime"`    // 秒
-	EndTime      float64      `json:"endTime"`      // 秒
-	Original     string       `json:"original"`     // 原文歌詞
-	Translations Translations `json:"translations"` // 翻譯
-	IsMeaningful bool         `json:"isMeaningful"` // 是否有意義（非空白、非標記）
-	IsSkipped    bool         `json:"isSkipped"`    // 是否被跳過（在起點之前）
+	Timestamp    string       `json:"timestamp"`              // "00:01.79"
+	StartTime    float64      `json:"startTime"`              // 秒
+	EndTime      float64      `json:"endTime"`                // 秒
+	Original     string       `json:"original"`               // 原文歌詞
+	Romanization string       `json:"romanization,omitempty"` // 原文的羅馬拼音（原文已是拉丁字母時為空）
+	Translations Translations `json:"translations"`           // 翻譯
+	Extras       []string     `json:"extras,omitempty"`       // 同一時間戳的其他行（第三行以後）
+	IsMeaningful bool         `json:"isMeaningful"`           // 是否有意義（非空白、非標記）
+	IsSkipped    bool         `json:"isSkipped"`              // 是否被跳過（在起點之前）
 }
 
 // LyricsData 歌詞資料
 type LyricsData struct {
-	FileID         string      `json:"fileId"`
-	Lines          []LyricLine `json:"lines"`
-	DetectedLang   string      `json:"detectedLang"`   // 檢測到的原文語言
-	HasEmbedded    bool        `json:"hasEmbedded"`    // 是否有內嵌翻譯
-	StartLineIndex int         `json:"startLineIndex"` // 起點行索引
+	FileID         string         `json:"fileId"`
+	Lines          []LyricLine    `json:"lines"`
+	DetectedLang   string         `json:"detectedLang"`       // 檢測到的原文語言
+	HasEmbedded    bool           `json:"hasEmbedded"`        // 是否有內嵌翻譯
+	StartLineIndex int            `json:"startLineIndex"`     // 起點行索引
+	Warnings       []ParseWarning `json:"warnings,omitempty"` // 歌詞解析警告
+}
+
+// ParseWarning 歌詞解析警告
+type ParseWarning struct {
+	Line    int    `json:"line"`    // 原始 LRC 行號
+	Content string `json:"content"` // 原始行內容
+	Message string `json:"message"` // 警告說明
 }
 
 // GetActiveLyrics 獲取有效歌詞（起點之後且有意義的）
@@ -42,7 +52,8 @@ func (ld *LyricsData) GetActiveLyrics() []LyricLine {
 // GetDisplayText 獲取顯示文字
 func (l *LyricLine) GetDisplayText(lang string, showChinese bool) DisplayText {
 	dt := DisplayText{
-		Original: l.Original,
+		Original:     l.Original,
+		Romanization: l.Romanization,
 	}
 
 	// 主要語言翻譯
@@ -73,7 +84,8 @@ func (l *LyricLine) GetDisplayText(lang string, showChinese bool) DisplayText {
 
 // DisplayText 顯示文字
 type DisplayText struct {
-	Original string `json:"original"` // 原文
-	Primary  string `json:"primary"`  // 主要語言翻譯
-	Chinese  string `json:"chinese"`  // 中文翻譯（可選）
+	Original     string `json:"original"`               // 原文
+	Romanization string `json:"romanization,omitempty"` // 原文的羅馬拼音（可選）
+	Primary      string `json:"primary"`                // 主要語言翻譯
+	Chinese      string `json:"chinese"`                // 中文翻譯（可選）
 }
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// SM-2 的參數
+const (
+	DefaultEaseFactor = 2.5
+	MinEaseFactor     = 1.3
+	MaxReviewGrade    = 5 // 評分 0~5，3 以上算記得
+	passingGrade      = 3
+)
+
+// ReviewCard 段落的複習卡片（SM-2 間隔重複）
+type ReviewCard struct {
+	FileID        string     `json:"fileId"`
+	SegmentIndex  int        `json:"segmentIndex"`
+	Text          string     `json:"text"`        // 建立卡片時的段落原文，段落重新產生後不同時重新開始
+	EaseFactor    float64    `json:"easeFactor"`  // 難易度係數，最低 1.3
+	Interval      int        `json:"interval"`    // 目前間隔（天）
+	Repetitions   int        `json:"repetitions"` // 連續答對次數
+	Lapses        int        `json:"lapses"`      // 忘記次數
+	Due           time.Time  `json:"due"`         // 下次複習時間
+	LastGrade     int        `json:"lastGrade"`
+	LastReviewAt  *time.Time `json:"lastReviewAt,omitempty"`
+	FirstReviewAt *time.Time `json:"firstReviewAt,omitempty"` // 第一次複習的時間，用來計算每日新卡片上限
+}
+
+// NewReviewCard 建立新卡片，立即到期
+func NewReviewCard(fileID string, segIdx int, text string, now time.Time) *ReviewCard {
+	return &ReviewCard{
+		FileID:       fileID,
+		SegmentIndex: segIdx,
+		Text:         text,
+		EaseFactor:   DefaultEaseFactor,
+		Due:          now,
+	}
+}
+
+// Review 依評分（0~5）更新間隔與下次複習時間
+// 答對時間隔依序為 1 天、6 天，之後乘上難易度係數；答錯時從 1 天重新開始
+func (c *ReviewCard) Review(grade int, now time.Time) {
+	if grade >= passingGrade {
+		switch c.Repetitions {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.EaseFactor))
+		}
+		c.Repetitions++
+	} else {
+		c.Repetitions = 0
+		c.Interval = 1
+		c.Lapses++
+	}
+
+	q := float64(MaxReviewGrade - grade)
+	c.EaseFactor = math.Max(c.EaseFactor+0.1-q*(0.08+q*0.02), MinEaseFactor)
+	c.LastGrade = grade
+	c.LastReviewAt = &now
+	if c.FirstReviewAt == nil {
+		c.FirstReviewAt = &now
+	}
+	c.Due = now.AddDate(0, 0, c.Interval)
+}
+
+// ReviewItem 今日複習清單中的一個段落
+type ReviewItem struct {
+	FileID       string      `json:"fileId"`
+	Filename     string      `json:"filename"`
+	Title        string      `json:"title,omitempty"`
+	SegmentIndex int         `json:"segmentIndex"`
+	OriginalText string      `json:"originalText"`
+	TTSText      string      `json:"ttsText"`
+	AudioURL     string      `json:"audioUrl"` // 段落原曲
+	TTSURL       string      `json:"ttsUrl"`   // 段落 TTS
+	New          bool        `json:"new"`      // 尚未複習過
+	Card         *ReviewCard `json:"card,omitempty"`
+}
+
+// ReviewQueue 今日到期的複習清單
+type ReviewQueue struct {
+	Date     string       `json:"date"`     // YYYY-MM-DD
+	DueCount int          `json:"dueCount"` // 到期的複習卡片數（不含新卡片）
+	NewCount int          `json:"newCount"` // 清單中的新卡片數
+	Items    []ReviewItem `json:"items"`
+}
@@ -1,17 +1,22 @@
 package models
 
+import "strings"
+
 // Segment 音訊段落
 type Segment struct {
 	Index        int     `json:"index"`
-	StartTime    float64 `json:"startTime"`    // 開始時間（秒）
-	EndTime      float64 `json:"endTime"`      // 結束時間（秒）
-	Duration     float64 `json:"duration"`     // 時長（秒）
-	LineIndices  []int   `json:"lineIndices"`  // 包含的歌詞行索引
-	OriginalText string  `json:"originalText"` // 合併的原文
-	TTSText      string  `json:"ttsText"`      // TTS 用的翻譯文字
-	IsMeaningful bool    `json:"isMeaningful"` // 是否有意義
-	AudioPath    string  `json:"audioPath"`    // 段落音訊路徑
-	TTSPath      string  `json:"ttsPath"`      // TTS 音訊路徑
+	StartTime    float64 `json:"startTime"`             // 開始時間（秒）
+	EndTime      float64 `json:"endTime"`               // 結束時間（秒）
+	Duration     float64 `json:"duration"`              // 時長（秒）
+	LineIndices  []int   `json:"lineIndices"`           // 包含的歌詞行索引
+	OriginalText string  `json:"originalText"`          // 合併的原文
+	TTSText      string  `json:"ttsText"`               // TTS 用的翻譯文字
+	IsMeaningful bool    `json:"isMeaningful"`          // 是否有意義
+	AudioPath    string  `json:"audioPath"`             // 段落音訊路徑
+	TTSPath      string  `json:"ttsPath"`               // TTS 音訊路徑
+	Speed        float64 `json:"speed,omitempty"`       // 段落專屬播放速度，0 表示沿用檔案設定
+	Loudness     float64 `json:"loudness,omitempty"`    // 段落音訊量測到的整合響度（LUFS，正規化前）
+	TTSLoudness  float64 `json:"ttsLoudness,omitempty"` // TTS 量測到的整合響度（LUFS，正規化前）
 }
 
 // SegmentsData 段落資料
@@ -41,18 +46,20 @@ func GeneratePlaylist(segments []Segment, lyrics []LyricLine, settings FileSetti
 		}
 
 		// 獲取段落對應的顯示文字
-		var displayOriginal, displayPrimary, displayChinese string
+		var displayOriginal, displayRoman, displayPrimary, displayChinese string
 		for _, idx := range seg.LineIndices {
 			if idx < len(lyrics) {
 				dt := lyrics[idx].GetDisplayText(settings.PrimaryLanguage, settings.ShowChineseTranslation)
 				if displayOriginal != "" {
 					displayOriginal += "\n"
 					displayPrimary += "\n"
+					displayRoman += "\n"
 					if displayChinese != "" {
 						displayChinese += "\n"
 					}
 				}
 				displayOriginal += dt.Original
+				displayRoman += dt.Romanization
 				displayPrimary += dt.Primary
 				if dt.Chinese != "" {
 					displayChinese += dt.Chinese
@@ -60,10 +67,16 @@ func GeneratePlaylist(segments []Segment, lyrics []LyricLine, settings FileSetti
 			}
 		}
 
+		// 羅馬拼音逐行對齊原文；整段都沒有時留空
+		if strings.TrimSpace(displayRoman) == "" {
+			displayRoman = ""
+		}
+
 		display := DisplayText{
-			Original: displayOriginal,
-			Primary:  displayPrimary,
-			Chinese:  displayChinese,
+			Original:     displayOriginal,
+			Romanization: displayRoman,
+			Primary:      displayPrimary,
+			Chinese:      displayChinese,
 		}
 
 		// 添加原曲段落
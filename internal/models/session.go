@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// PracticeEventType 練習事件類型
+type PracticeEventType string
+
+const (
+	EventPlay   PracticeEventType = "play"   // 播放段落
+	EventRepeat PracticeEventType = "repeat" // 再次播放同一段落
+	EventSkip   PracticeEventType = "skip"   // 沒播完就跳過
+	EventGrade  PracticeEventType = "grade"  // 自我評分（0~5）
+)
+
+// PracticeEvent 練習中的一個事件
+type PracticeEvent struct {
+	Type         PracticeEventType `json:"type"`
+	FileID       string            `json:"fileId"`
+	SegmentIndex int               `json:"segmentIndex"`
+	Grade        *int              `json:"grade,omitempty"` // 只有 grade 事件
+	At           time.Time         `json:"at"`
+}
+
+// PracticeSession 一次練習的紀錄
+type PracticeSession struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"userId"`
+	Mode      string          `json:"mode,omitempty"` // 練習模式，例如 playlist、super
+	StartedAt time.Time       `json:"startedAt"`
+	EndedAt   *time.Time      `json:"endedAt,omitempty"`
+	Events    []PracticeEvent `json:"events"`
+}
+
+// Duration 練習時間，沒有正常結束時算到最後一個事件
+func (s *PracticeSession) Duration() time.Duration {
+	end := s.StartedAt
+	if s.EndedAt != nil {
+		end = *s.EndedAt
+	} else if n := len(s.Events); n > 0 {
+		end = s.Events[n-1].At
+	}
+	if end.Before(s.StartedAt) {
+		return 0
+	}
+	return end.Sub(s.StartedAt)
+}
+
+// PracticeStats 練習統計
+type PracticeStats struct {
+	UserID          string          `json:"userId"`
+	From            string          `json:"from"` // YYYY-MM-DD
+	To              string          `json:"to"`
+	TotalSeconds    float64         `json:"totalSeconds"`
+	Sessions        int             `json:"sessions"`
+	SongsCovered    int             `json:"songsCovered"`    // 播放過的歌曲數
+	SegmentsCovered int             `json:"segmentsCovered"` // 播放過的段落數
+	CurrentStreak   int             `json:"currentStreak"`   // 連續練習天數（到今天或昨天）
+	LongestStreak   int             `json:"longestStreak"`
+	Days            []DailyPractice `json:"days"`
+	Weakest         []WeakSegment   `json:"weakest"`
+}
+
+// DailyPractice 單日的練習量
+type DailyPractice struct {
+	Date     string  `json:"date"` // YYYY-MM-DD
+	Seconds  float64 `json:"seconds"`
+	Sessions int     `json:"sessions"`
+	Segments int     `json:"segments"` // 播放過的不同段落數
+}
+
+// WeakSegment 評分偏低的段落
+type WeakSegment struct {
+	FileID       string  `json:"fileId"`
+	Filename     string  `json:"filename"`
+	SegmentIndex int     `json:"segmentIndex"`
+	Text         string  `json:"text"`
+	Plays        int     `json:"plays"`
+	Repeats      int     `json:"repeats"`
+	Skips        int     `json:"skips"`
+	Grades       int     `json:"grades"`
+	AverageGrade float64 `json:"averageGrade"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// VocabEntry 歌詞中的一個詞
+type VocabEntry struct {
+	Surface string `json:"surface"`       // 歌詞中出現的形式
+	Lemma   string `json:"lemma"`         // 辭典形（原形）
+	POS     string `json:"pos,omitempty"` // 詞性（noun、verb...）
+	Gloss   string `json:"gloss,omitempty"`
+}
+
+// GlossaryLine 一句歌詞的詞彙
+type GlossaryLine struct {
+	LineIndex int          `json:"lineIndex"`
+	Original  string       `json:"original"`
+	Words     []VocabEntry `json:"words"`
+}
+
+// Glossary 一首歌的詞彙表
+type Glossary struct {
+	FileID        string         `json:"fileId"`
+	Language      string         `json:"language,omitempty"` // 歌詞語言
+	GlossLanguage string         `json:"glossLanguage"`      // 釋義語言 (en, zh)
+	Lines         []GlossaryLine `json:"lines"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+}
+
+// VocabOccurrence 單字在某首歌中出現的位置
+type VocabOccurrence struct {
+	FileID    string `json:"fileId"`
+	Title     string `json:"title,omitempty"`
+	LineIndex int    `json:"lineIndex"`
+	Surface   string `json:"surface"`
+	Line      string `json:"line"` // 該句原文
+}
+
+// VocabWord 全域詞彙表中的一個詞（同語言、同原形的詞合併）
+type VocabWord struct {
+	Lemma       string            `json:"lemma"`
+	Language    string            `json:"language,omitempty"`
+	POS         []string          `json:"pos,omitempty"`
+	Glosses     []string          `json:"glosses,omitempty"`
+	Forms       []string          `json:"forms"` // 出現過的形式
+	Count       int               `json:"count"` // 出現次數
+	SongCount   int               `json:"songCount"`
+	Occurrences []VocabOccurrence `json:"occurrences"`
+}
@@ -0,0 +1,27 @@
+package romanize
+
+import "unicode"
+
+// cyrillicTable 俄文字母的拉丁轉寫（BGN/PCGN 簡化，不加變音符號），另含常見的烏克蘭與白俄羅斯字母
+var cyrillicTable = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "w",
+}
+
+// cyrillicToLatin 逐字轉寫西里爾字母，保留大寫
+func cyrillicToLatin(run []rune) string {
+	var out []byte
+	for _, r := range run {
+		latin, ok := cyrillicTable[unicode.ToLower(r)]
+		if !ok {
+			out = append(out, string(r)...)
+			continue
+		}
+		out = append(out, capitalize(latin, unicode.IsUpper(r))...)
+	}
+	return string(out)
+}
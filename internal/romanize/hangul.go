@@ -0,0 +1,104 @@
+package romanize
+
+import "strings"
+
+// 韓文音節區塊：音節 = 0xAC00 + (初聲*21 + 中聲)*28 + 終聲
+const (
+	hangulBase  = 0xAC00
+	hangulLast  = 0xD7A3
+	medialCount = 21
+	finalCount  = 28
+)
+
+// 初聲、中聲的文化觀光部式羅馬字（Revised Romanization）
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+)
+
+// hangulFinals 終聲在子音前或詞尾的發音（代表音）
+var hangulFinals = []string{
+	"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l",
+	"m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t",
+}
+
+// hangulLiaison 終聲後接 ㅇ 開頭的音節時連音：留在原音節的部分與移到下一個音節的初聲
+var hangulLiaison = [finalCount][2]string{
+	{"", ""}, {"", "g"}, {"", "kk"}, {"k", "s"}, {"", "n"}, {"n", "j"}, {"n", ""}, {"", "d"},
+	{"", "r"}, {"l", "g"}, {"l", "m"}, {"l", "b"}, {"l", "s"}, {"l", "t"}, {"l", "p"}, {"", "r"},
+	{"", "m"}, {"", "b"}, {"p", "s"}, {"", "s"}, {"", "ss"}, {"ng", ""}, {"", "j"}, {"", "ch"},
+	{"", "k"}, {"", "t"}, {"", "p"}, {"", ""},
+}
+
+// 常用的初聲與終聲索引
+const (
+	initialN     = 2  // ㄴ
+	initialR     = 5  // ㄹ
+	initialM     = 6  // ㅁ
+	initialIeung = 11 // ㅇ（無聲）
+	finalN       = 4  // ㄴ
+	finalL       = 8  // ㄹ
+	finalH       = 27 // ㅎ
+)
+
+// hangulToLatin 轉寫一段連續的韓文音節，處理連音、鼻音化、流音化與 ㅎ 送氣
+func hangulToLatin(run []rune) string {
+	type syllable struct{ initial, medial, final int }
+	syllables := make([]syllable, len(run))
+	for i, r := range run {
+		s := int(r - hangulBase)
+		syllables[i] = syllable{s / (medialCount * finalCount), s % (medialCount * finalCount) / finalCount, s % finalCount}
+	}
+
+	var sb strings.Builder
+	nextInitial := "" // 由前一個音節決定的初聲（連音或音變），空字串表示照表
+	overridden := false
+	for i, syl := range syllables {
+		initial := hangulInitials[syl.initial]
+		if overridden {
+			initial = nextInitial
+		}
+		final := hangulFinals[syl.final]
+		overridden = false
+
+		if i+1 < len(syllables) && syl.final != 0 {
+			next := syllables[i+1].initial
+			switch {
+			case next == initialIeung:
+				// 連音：終聲移到下一個音節
+				final, nextInitial = hangulLiaison[syl.final][0], hangulLiaison[syl.final][1]
+				overridden = nextInitial != ""
+			case syl.final == finalH && (next == 0 || next == 3 || next == 12):
+				// ㅎ + ㄱ/ㄷ/ㅈ → ㅋ/ㅌ/ㅊ
+				final, nextInitial, overridden = "", map[int]string{0: "k", 3: "t", 12: "ch"}[next], true
+			case (syl.final == finalN || syl.final == finalL) && next == initialR,
+				syl.final == finalL && next == initialN:
+				// 流音化：ㄴㄹ、ㄹㄴ、ㄹㄹ 都念成 ll
+				final, nextInitial, overridden = "l", "l", true
+			case next == initialN || next == initialM:
+				// 鼻音化：k → ng、t → n、p → m
+				switch final {
+				case "k":
+					final = "ng"
+				case "t":
+					final = "n"
+				case "p":
+					final = "m"
+				}
+			case next == initialR:
+				// ㄹ 在 ㄹ 以外的終聲後念成 ㄴ，且 k/p 終聲跟著鼻音化
+				nextInitial, overridden = "n", true
+				switch final {
+				case "k":
+					final = "ng"
+				case "p":
+					final = "m"
+				}
+			}
+		}
+		sb.WriteString(initial)
+		sb.WriteString(hangulMedials[syl.medial])
+		sb.WriteString(final)
+	}
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+package romanize
+
+import (
+	"strings"
+	"unicode"
+)
+
+// hanziWordMaxLen hanziWords 中最長的詞語字數
+const hanziWordMaxLen = 4
+
+// 由 hanzi_table.go 的資料建立的查詢表
+var (
+	hanziPinyin = make(map[rune][]string) // 字 → 讀音（多音字第一個為最常見的讀音）
+	hanziWord   = make(map[string][]string)
+)
+
+func init() {
+	for _, line := range strings.Split(hanziReadings, "\n") {
+		syllable, chars, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		for _, r := range chars {
+			hanziPinyin[r] = []string{syllable}
+		}
+	}
+	for _, line := range strings.Split(hanziPolyphones, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		r := []rune(fields[0])[0]
+		hanziPinyin[r] = fields[1:]
+	}
+	for _, line := range strings.Split(hanziWords, "\n") {
+		var words, syllables []string
+		for _, f := range strings.Fields(line) {
+			if HasHan(f) {
+				words = append(words, f)
+			} else {
+				syllables = append(syllables, f)
+			}
+		}
+		for _, w := range words {
+			hanziWord[w] = syllables
+		}
+	}
+}
+
+// Pinyin 以本地字表將中文轉成帶聲調符號的拼音，假名、韓文等其他文字與 Romanize 相同
+// 多音字先查常用詞語，查不到時使用最常見的讀音並將 ambiguous 設為 true（可交給翻譯模型確認）；
+// 含有字表沒有的漢字時 ok 為 false。不需要轉寫時回傳空字串
+func Pinyin(text string) (result string, ambiguous, ok bool) {
+	if !Needed(text) {
+		return "", false, true
+	}
+	ok = true
+	result = transliterate(text, func(run []rune) string {
+		syllables, amb, found := hanziToPinyin(run)
+		ambiguous = ambiguous || amb
+		ok = ok && found
+		return strings.Join(syllables, " ")
+	})
+	if !ok {
+		return "", false, false
+	}
+	return result, ambiguous, true
+}
+
+// hanziToPinyin 轉寫一段連續的漢字：由左到右優先比對最長的常用詞語，其餘逐字查表
+func hanziToPinyin(run []rune) (syllables []string, ambiguous, ok bool) {
+	for i := 0; i < len(run); {
+		matched := false
+		for n := min(hanziWordMaxLen, len(run)-i); n >= 2; n-- {
+			if word, found := hanziWord[string(run[i:i+n])]; found {
+				syllables = append(syllables, word...)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		r := run[i]
+		i++
+		if r == '々' {
+			// 重複前一個字的讀音
+			if len(syllables) > 0 {
+				syllables = append(syllables, syllables[len(syllables)-1])
+			}
+			continue
+		}
+		readings, found := hanziPinyin[r]
+		if !found {
+			return nil, false, false
+		}
+		if len(readings) > 1 {
+			ambiguous = true
+		}
+		syllables = append(syllables, readings[0])
+	}
+	for i, s := range syllables {
+		syllables[i] = markSyllable(s)
+	}
+	return syllables, ambiguous, true
+}
+
+// isAlnum 拼音前後接拉丁字母或數字時需要空格分開
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
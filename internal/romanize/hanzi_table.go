@@ -0,0 +1,1232 @@
+package romanize
+
+// hanziReadings 常用漢字（簡體與繁體）的拼音，每行為「數字標調的音節 該讀音的字」
+// 多音字只列最常見的讀音，其他讀音出現在 hanziWords 的詞語中；讀音要看上下文的多音字列在 hanziPolyphones
+const hanziReadings = `
+a1 阿
+a5 啊
+ai1 哀埃唉
+ai2 癌
+ai3 矮蔼藹
+ai4 爱愛碍礙艾隘暧曖
+an1 安氨鞍庵
+an3 俺
+an4 按案暗岸
+ang1 肮骯
+ang2 昂
+ao1 凹
+ao2 熬翱
+ao3 袄襖
+ao4 傲奥奧澳懊
+ba1 八巴叭扒芭疤笆
+ba2 拔
+ba3 把靶
+ba4 爸霸坝壩罢罷
+ba5 吧
+bai2 白
+bai3 百摆擺柏佰
+bai4 败敗拜
+ban1 班般颁頒斑搬
+ban3 板版
+ban4 办辦半伴扮瓣绊絆
+bang1 帮幫邦
+bang3 绑綁榜膀
+bang4 棒傍谤謗磅
+bao1 包胞苞褒
+bao2 雹
+bao3 宝寶饱飽保堡
+bao4 报報抱豹暴爆
+bei1 杯卑悲碑
+bei3 北
+bei4 贝貝备備倍被辈輩
+ben1 奔
+ben3 本
+ben4 笨
+beng1 崩绷繃
+beng4 蹦泵
+bi1 逼
+bi2 鼻
+bi3 比彼笔筆鄙
+bi4 必毕畢闭閉币幣壁避臂碧弊蔽毙斃庇
+bian1 边邊编編鞭
+bian3 扁贬貶
+bian4 变變遍辩辯辨辫辮便
+biao1 标標彪
+biao3 表錶
+bie1 憋
+bie2 别別
+bin1 宾賓滨濱彬缤繽
+bin4 鬓鬢
+bing1 冰兵
+bing3 丙饼餅秉柄
+bing4 病并並
+bo1 波玻拨撥播菠
+bo2 伯博脖膊勃驳駁
+bo3 跛
+bu3 补補捕哺
+bu4 不布步部怖
+ca1 擦
+cai1 猜
+cai2 才材财財裁纔
+cai3 采採彩踩睬
+cai4 菜蔡
+can1 餐
+can2 残殘蚕蠶惭慚
+can3 惨慘
+can4 灿燦
+cang1 仓倉苍蒼舱艙沧滄
+cang2 藏
+cao1 操糙
+cao2 曹槽
+cao3 草
+ce4 册冊侧側厕廁测測策
+ceng2 曾
+cha1 叉插
+cha2 茶查察
+cha4 诧詫岔刹剎
+chai1 拆
+chai2 柴
+chan2 缠纏蝉蟬馋饞
+chan3 产產铲鏟
+chan4 颤顫
+chang1 昌猖
+chang2 肠腸尝嘗偿償
+chang3 厂廠敞场場
+chang4 唱倡畅暢怅悵
+chao1 超抄钞鈔
+chao2 潮嘲巢
+chao3 吵炒
+che1 车車
+che3 扯
+che4 彻徹撤
+chen2 尘塵臣沉辰陈陳晨
+chen4 衬襯趁
+cheng1 撑撐
+cheng2 成城诚誠承程乘惩懲橙呈
+cheng3 逞
+cheng4 秤
+chi1 吃喫痴癡
+chi2 池迟遲持驰馳
+chi3 尺齿齒耻恥
+chi4 赤翅斥
+chong1 充
+chong2 虫蟲崇
+chong3 宠寵
+chou1 抽
+chou2 仇愁筹籌酬绸綢稠惆
+chou3 丑醜
+chou4 臭
+chu1 出初
+chu2 除厨廚锄鋤
+chu3 础礎楚储儲
+chu4 触觸畜
+chuan1 穿川
+chuan2 船传傳
+chuan3 喘
+chuan4 串
+chuang1 窗疮瘡
+chuang2 床牀
+chuang3 闯闖
+chuang4 创創
+chui1 吹炊
+chui2 垂锤錘捶
+chun1 春
+chun2 纯純唇脣
+chun3 蠢
+ci2 词詞辞辭磁雌慈瓷
+ci3 此
+ci4 次刺赐賜
+cong1 聪聰葱蔥匆
+cong2 从從丛叢
+cou4 凑湊
+cu1 粗
+cu4 促醋簇
+cui1 催摧
+cui4 脆翠粹悴
+cun1 村
+cun2 存
+cun4 寸
+cuo1 搓
+cuo4 错錯措挫
+da1 搭
+da2 达達答
+da3 打
+da4 大
+dai1 呆
+dai3 歹
+dai4 代带帶待袋戴贷貸逮怠
+dan1 单單担擔丹耽
+dan3 胆膽
+dan4 但旦蛋淡诞誕
+dang1 当當
+dang3 党黨挡擋
+dang4 荡蕩档檔
+dao1 刀
+dao3 导導岛島蹈
+dao4 到道盗盜悼稻
+de2 德
+de5 得的
+deng1 灯燈登
+deng3 等
+deng4 邓鄧凳瞪
+di1 低堤滴
+di2 敌敵笛
+di3 底抵
+di4 弟帝递遞第
+dian1 颠顛
+dian3 典点點
+dian4 电電店垫墊殿淀惦
+diao1 雕凋叼
+diao4 吊掉钓釣
+die1 爹跌
+die2 叠疊蝶碟
+ding1 丁盯钉釘叮
+ding3 顶頂
+ding4 定订訂
+diu1 丢丟
+dong1 东東冬
+dong3 懂董
+dong4 动動冻凍洞栋棟
+dou1 兜都
+dou3 抖陡
+dou4 豆逗鬥
+du1 督
+du2 读讀独獨毒
+du3 堵赌賭
+du4 肚度渡杜镀鍍
+duan1 端
+duan3 短
+duan4 段断斷锻鍛
+dui1 堆
+dui4 对對队隊兑兌
+dun1 吨噸蹲
+dun4 顿頓盾钝鈍
+duo1 多
+duo2 夺奪
+duo3 朵躲
+duo4 堕墮惰舵
+e2 鹅鵝额額俄娥
+e4 饿餓厄
+en1 恩
+er2 儿兒而
+er3 耳尔爾
+er4 二贰貳
+fa1 發发
+fa2 罚罰乏伐阀閥
+fa3 法
+fa4 髮
+fan1 番翻帆
+fan2 凡烦煩繁
+fan3 反返
+fan4 犯饭飯泛范範贩販
+fang1 方芳
+fang2 房防妨
+fang3 访訪仿纺紡
+fang4 放
+fei1 飞飛非啡
+fei2 肥
+fei4 费費肺废廢沸
+fen1 纷紛芬分
+fen2 坟墳焚
+fen3 粉
+fen4 份奋奮愤憤粪糞
+feng1 风風封丰豐疯瘋峰锋鋒蜂
+feng2 逢
+feng4 奉凤鳳
+fo2 佛
+fou3 否
+fu1 夫肤膚敷
+fu2 服福浮扶幅伏符俘彿
+fu3 府腐斧辅輔抚撫
+fu4 父付妇婦负負附富副复復複覆赴
+ga1 嘎
+gai1 该該
+gai3 改
+gai4 概盖蓋钙鈣
+gan1 甘肝杆竿乾
+gan3 赶趕敢感
+gan4 幹
+gang1 刚剛钢鋼纲綱缸
+gang3 港岗崗
+gao1 高膏糕
+gao3 搞稿
+gao4 告
+ge1 哥歌胳割搁擱鸽鴿
+ge2 格革隔阁閣
+ge4 个個各
+gei3 给給
+gen1 根跟
+geng1 耕
+geng4 更
+gong1 工公功攻弓宫宮恭
+gong3 巩鞏拱
+gong4 共贡貢
+gou1 沟溝钩鉤勾
+gou3 狗
+gou4 够夠构構购購
+gu1 姑孤估辜
+gu3 古股骨谷鼓
+gu4 故固顾顧雇
+gua1 瓜刮
+gua3 寡
+gua4 挂掛
+guai1 乖
+guai3 拐
+guai4 怪
+guan1 关關官观觀
+guan3 管馆館
+guan4 惯慣贯貫灌罐
+guang1 光
+guang3 广廣
+guang4 逛
+gui1 归歸规規龟龜闺閨瑰
+gui3 鬼轨軌诡詭
+gui4 贵貴柜櫃跪桂
+gun3 滚滾
+gun4 棍
+guo1 锅鍋郭
+guo2 国國
+guo3 果裹
+guo4 过過
+ha1 哈
+hai2 孩
+hai3 海
+hai4 害
+han2 含寒
+han3 喊罕
+han4 汉漢汗旱憾
+hang2 航
+hao2 豪毫
+hao3 好
+hao4 号號耗浩
+he1 喝
+he2 河何合盒核和
+he4 贺賀鹤鶴
+hei1 黑嘿
+hen2 痕
+hen3 很狠
+hen4 恨
+heng2 横橫衡恒
+hong1 轰轟烘
+hong2 红紅洪虹宏
+hou2 喉猴
+hou3 吼
+hou4 后後候厚
+hu1 呼忽乎
+hu2 湖胡壶壺糊蝴狐
+hu3 虎
+hu4 户戶护護互
+hua1 花
+hua2 划滑华華
+hua4 化话話画畫
+huai2 怀懷徊
+huai4 坏壞
+huan1 欢歡
+huan2 环環
+huan3 缓緩
+huan4 换換唤喚幻患
+huang1 荒慌
+huang2 黄黃皇煌徨
+huang3 谎謊恍
+hui1 灰挥揮辉輝
+hui2 回
+hui3 悔毁毀
+hui4 汇匯惠慧绘繪会會
+hun1 昏婚
+hun2 魂浑渾
+hun4 混
+huo2 活
+huo3 火伙夥
+huo4 或货貨获獲祸禍惑
+ji1 机機鸡雞积積基激饥飢肌击擊圾
+ji2 及级級即极極急集吉疾籍辑輯寂
+ji3 己挤擠几幾
+ji4 记記纪紀计計技际際寄季既继繼迹跡绩績济濟
+jia1 家加佳
+jia3 甲
+jia4 价價架驾駕嫁
+jian1 尖坚堅肩艰艱兼监監煎间間
+jian3 减減简簡检檢剪捡撿
+jian4 见見件建健剑劍渐漸箭践踐
+jiang1 江姜疆将將
+jiang3 讲講奖獎
+jiang4 酱醬匠降
+jiao1 交郊焦骄驕胶膠浇澆
+jiao3 脚腳饺餃搅攪角
+jiao4 叫较較轿轎
+jie1 接街阶階揭皆
+jie2 节節杰傑洁潔捷截结結
+jie3 姐解
+jie4 借界届屆介戒
+jin1 今金斤巾津筋
+jin3 仅僅紧緊锦錦儘
+jin4 进進近盡劲勁禁浸
+jing1 京经經精惊驚睛晶
+jing3 景井警颈頸憬
+jing4 静靜境敬镜鏡竞競净淨径徑
+jiong3 窘
+jiu1 究纠糾揪
+jiu3 九久酒
+jiu4 就旧舊救
+ju1 居拘
+ju2 局菊
+ju3 举舉
+ju4 句巨据據具剧劇聚拒距俱惧懼
+juan1 捐
+juan3 捲卷
+juan4 倦
+jue2 决決绝絕觉覺倔
+jun1 军軍均君
+jun4 俊
+ka1 咖
+ka3 卡
+kai1 开開
+kai3 凯凱
+kan1 刊
+kan3 砍
+kan4 看
+kang1 康
+kang2 扛
+kang4 抗
+kao3 考烤
+kao4 靠
+ke1 科颗顆棵
+ke2 咳壳殼
+ke3 可渴
+ke4 课課客刻克
+ken3 肯
+keng1 坑
+kong1 空
+kong3 恐孔
+kong4 控
+kou3 口
+kou4 扣
+ku1 哭枯
+ku3 苦
+ku4 库庫裤褲酷
+kua1 夸誇
+kua4 跨
+kuai4 快块塊筷
+kuan1 宽寬
+kuan3 款
+kuang2 狂
+kuang4 况況矿礦框
+kun1 坤
+kun4 困睏
+kuo4 扩擴阔闊括
+la1 拉啦
+la3 喇
+la4 辣蜡蠟
+lai2 来來
+lai4 赖賴
+lan2 蓝藍兰蘭拦攔篮籃阑闌
+lan3 懒懶揽攬
+lan4 烂爛滥濫
+lang2 狼郎
+lang3 朗
+lang4 浪
+lao2 劳勞牢
+lao3 老
+le5 了
+lei2 雷
+lei4 泪淚类類累
+leng3 冷
+li2 离離梨璃黎
+li3 理里裡裏礼禮李
+li4 力历歷曆立利例丽麗厉厲励勵粒
+lia3 俩倆
+lian2 连連联聯怜憐莲蓮帘簾
+lian3 脸臉
+lian4 练練恋戀炼煉
+liang2 良凉涼粮糧梁
+liang3 两兩
+liang4 亮辆輛谅諒
+liao2 聊疗療辽遼
+liao3 瞭
+liao4 料
+lie4 列烈裂劣猎獵
+lin2 林临臨邻鄰淋
+ling2 零灵靈铃鈴龄齡凌
+ling3 领領岭嶺
+ling4 另令
+liu2 流留刘劉
+liu3 柳
+liu4 六
+long2 龙龍笼籠聋聾
+long3 拢攏
+lou2 楼樓
+lou4 漏
+lu2 炉爐
+lu4 路陆陸录錄鹿
+luan4 乱亂
+lue4 略
+lun2 轮輪
+lun4 论論
+luo2 罗羅萝蘿
+luo4 络絡落
+lv2 驴驢
+lv3 旅铝鋁屡屢
+lv4 律虑慮绿綠
+ma1 妈媽
+ma2 麻
+ma3 马馬码碼
+ma4 骂罵
+ma5 吗嗎嘛
+mai2 埋
+mai3 买買
+mai4 卖賣麦麥迈邁
+man2 瞒瞞
+man3 满滿
+man4 慢漫
+mang2 忙盲茫
+mao1 猫貓
+mao2 毛矛
+mao4 冒帽貌贸貿
+me5 么麼
+mei2 眉梅煤媒没沒玫
+mei3 美每
+mei4 妹魅
+men2 门門
+men5 们們
+meng2 蒙盟萌
+meng3 猛
+meng4 梦夢
+mi2 迷谜謎
+mi3 米
+mi4 密秘蜜
+mian2 棉眠绵綿
+mian3 免勉
+mian4 面麵
+miao2 苗
+miao3 秒
+miao4 妙庙廟
+mie4 灭滅
+min2 民
+min3 敏
+ming2 名明鸣鳴
+ming4 命
+mo1 摸
+mo2 磨魔摩
+mo4 末莫墨默寞沫漠
+mou2 眸谋謀
+mou3 某
+mu3 母亩畝
+mu4 木目幕慕暮墓
+na2 拿
+na3 哪
+na4 那
+nai3 奶
+nai4 耐
+nan2 男南难難
+nao3 脑腦恼惱
+nao4 闹鬧
+ne5 呢
+nei4 内內
+neng2 能
+ni2 泥
+ni3 你妳
+ni4 逆
+nian2 年
+nian4 念
+niang2 娘
+niao3 鸟鳥
+nin2 您
+ning2 宁寧凝
+niu2 牛
+niu3 扭
+nong2 农農浓濃
+nong4 弄
+nu3 努
+nu4 怒
+nuan3 暖
+nuo4 诺諾
+nv3 女
+o5 哦
+ou1 欧歐
+ou3 偶
+pa1 趴
+pa2 爬
+pa4 怕
+pai1 拍
+pai2 排牌徘
+pai4 派
+pan2 盘盤
+pan4 盼判
+pang2 旁彷
+pang4 胖
+pao3 跑
+pao4 炮泡
+pei2 陪培赔賠
+pei4 配佩
+pen1 喷噴
+pen2 盆
+peng2 朋棚蓬
+peng4 碰
+pi1 批披
+pi2 皮疲啤脾
+pi4 屁
+pian1 篇偏
+pian4 片骗騙
+piao1 飘飄
+piao4 票漂
+pin1 拼
+pin2 贫貧频頻
+pin3 品
+ping2 平评評凭憑瓶苹蘋屏
+po1 坡泼潑
+po2 婆
+po4 破迫
+pu1 扑撲
+pu2 葡仆
+pu3 普谱譜朴
+qi1 七期妻欺漆戚
+qi2 其骑騎齐齊旗棋奇淇
+qi3 起启啟乞
+qi4 气氣汽器弃棄契泣
+qia4 恰洽
+qian1 千牵牽签簽铅鉛迁遷谦謙
+qian2 前钱錢潜潛
+qian3 浅淺遣
+qian4 欠歉
+qiang1 枪槍腔
+qiang2 墙牆强強
+qiang3 抢搶
+qiao1 敲悄
+qiao2 桥橋瞧憔
+qiao3 巧
+qie3 且
+qin1 亲親侵
+qin2 琴勤秦
+qing1 青轻輕清
+qing2 情晴
+qing3 请請
+qing4 庆慶
+qiong2 穷窮
+qiu1 秋
+qiu2 求球
+qu1 区區趋趨
+qu2 渠
+qu3 取娶
+qu4 去趣
+quan1 圈
+quan2 全权權泉拳
+quan4 劝勸
+que1 缺
+que4 却卻确確雀
+qun2 群裙
+ran2 然燃
+ran3 染
+rang4 让讓
+rao4 绕繞
+re3 惹
+re4 热熱
+ren2 人仁
+ren3 忍
+ren4 认認任
+reng1 扔
+reng2 仍
+ri4 日
+rong2 容荣榮融
+rou2 柔
+rou4 肉
+ru2 如
+ru4 入
+ruan3 软軟
+rui4 锐銳
+run4 润潤
+ruo4 若弱
+sa1 撒
+sa3 洒灑
+sai1 塞
+sai4 赛賽
+san1 三
+san3 伞傘
+sang1 桑
+sang3 嗓
+sang4 丧喪
+sao3 扫掃
+se4 色
+sen1 森
+sha1 杀殺沙纱紗
+sha3 傻
+shai4 晒曬
+shan1 山衫珊
+shan3 闪閃
+shan4 善
+shang1 伤傷商
+shang3 赏賞
+shang4 上尚
+shao1 烧燒稍
+shao3 少
+she2 舌蛇
+she3 捨
+she4 设設社射摄攝
+shei2 谁誰
+shen1 身深申伸
+shen2 什神
+shen3 审審
+shen4 甚慎肾腎
+sheng1 生声聲升牲
+sheng2 绳繩
+sheng4 胜勝剩圣聖盛
+shi1 师師诗詩失施湿濕狮獅
+shi2 十时時实實识識石食拾
+shi3 使始史屎
+shi4 是事世市式室视視试試适適势勢示士饰飾释釋逝誓
+shou1 收
+shou3 手首守
+shou4 受授售瘦兽獸
+shu1 书書输輸舒叔梳疏
+shu2 熟
+shu3 属屬暑鼠
+shu4 树樹术術束述
+shua1 刷
+shuai1 摔
+shuai4 帅帥
+shuang1 双雙霜
+shui3 水
+shui4 睡税稅
+shun4 顺順瞬
+shuo1 说說
+si1 丝絲思私司撕斯
+si3 死
+si4 四寺似
+song1 松鬆
+song4 送宋诵誦
+sou1 搜
+su1 苏蘇
+su2 俗
+su4 诉訴速宿素塑
+suan1 酸
+suan4 算
+sui1 虽雖
+sui2 随隨
+sui4 岁歲碎
+sun1 孙孫
+sun3 损損
+suo1 缩縮
+suo3 所锁鎖索
+ta1 他她它牠塌
+ta3 塔
+ta4 踏
+tai2 台臺抬
+tai4 太态態泰
+tan1 摊攤贪貪滩灘
+tan2 谈談坛壇
+tan3 坦毯
+tan4 叹嘆探碳
+tang1 汤湯
+tang2 糖堂唐
+tang3 躺
+tang4 趟烫燙
+tao1 掏
+tao2 逃桃陶
+tao3 讨討
+tao4 套
+te4 特
+teng2 疼腾騰
+ti1 踢梯
+ti2 提题題
+ti3 体體
+ti4 替
+tian1 天添
+tian2 田甜填
+tiao1 挑
+tiao2 条條
+tiao4 跳
+tie1 贴貼
+tie3 铁鐵
+ting1 听聽厅廳
+ting2 停庭
+ting3 挺
+tong1 通
+tong2 同童铜銅
+tong3 统統桶筒
+tong4 痛
+tou1 偷
+tou2 头頭投
+tou4 透
+tu1 突
+tu2 图圖途徒涂塗
+tu3 土
+tu4 兔
+tuan2 团團
+tui1 推
+tui3 腿
+tui4 退
+tun1 吞
+tuo1 拖脱脫托託
+wa1 挖
+wa2 娃
+wa3 瓦
+wai4 外
+wan1 弯彎湾灣
+wan2 完玩顽頑
+wan3 晚碗
+wan4 万萬
+wang2 王亡
+wang3 往网網
+wang4 望忘旺妄
+wei1 危威微
+wei2 围圍维維唯违違
+wei3 伟偉尾委
+wei4 未位味卫衛胃喂谓謂为為
+wen1 温溫
+wen2 文闻聞纹紋
+wen3 稳穩吻
+wen4 问問
+wo3 我
+wo4 握卧臥
+wu1 屋污乌烏呜嗚
+wu2 无無
+wu3 五午舞武伍
+wu4 物务務误誤雾霧悟勿
+xi1 西希息吸惜夕稀析牺犧溪熙膝曦
+xi2 习習席袭襲
+xi3 洗喜
+xi4 细細係戏戲系隙
+xia1 虾蝦瞎
+xia2 峡峽霞
+xia4 下夏吓嚇
+xian1 先仙掀鲜鮮
+xian2 闲閒嫌咸鹹弦
+xian3 显顯险險
+xian4 现現线線限县縣献獻羡羨陷
+xiang1 香乡鄉箱相
+xiang2 详詳祥
+xiang3 想响響享
+xiang4 向象像项項
+xiao1 消销銷萧蕭宵
+xiao3 小晓曉
+xiao4 笑校效孝
+xie1 些歇
+xie2 鞋协協斜
+xie3 写寫
+xie4 谢謝泄
+xin1 心新辛欣
+xin4 信
+xing1 星兴興
+xing2 形型
+xing3 醒
+xing4 性幸姓
+xiong1 兄凶胸
+xiong2 雄熊
+xiu1 休修
+xiu4 秀袖绣繡
+xu1 需虚虛须須
+xu3 许許
+xu4 续續序绪緒
+xuan1 宣
+xuan2 旋悬懸玄
+xuan3 选選
+xue2 学學
+xue3 雪
+xue4 血
+xun2 寻尋巡询詢
+xun4 讯訊训訓迅
+ya1 压壓
+ya2 牙芽崖涯
+ya3 哑啞雅
+ya4 亚亞
+ya5 呀
+yan1 烟煙淹
+yan2 言严嚴颜顏沿研盐鹽炎
+yan3 眼演
+yan4 燕验驗艳豔宴厌厭
+yang2 羊阳陽洋扬揚
+yang3 养養仰
+yang4 样樣
+yao1 腰邀
+yao2 摇搖遥遙
+yao3 咬
+yao4 药藥耀要
+ye2 爷爺
+ye3 也野
+ye4 夜业業叶葉页頁
+yi1 一衣医醫依
+yi2 移疑遗遺宜仪儀
+yi3 以已乙椅
+yi4 亿億义義艺藝忆憶议議易意益异異译譯
+yin1 因音阴陰
+yin2 银銀
+yin3 引饮飲隐隱
+yin4 印
+ying1 英鹰鷹应應
+ying2 迎营營赢贏
+ying3 影
+ying4 硬映
+yong1 拥擁
+yong3 永勇涌泳
+yong4 用
+you1 优優忧憂幽悠
+you2 由油游遊犹猶邮郵
+you3 有友
+you4 又右幼诱誘
+yu2 于於余鱼魚愉娱娛
+yu3 雨语語宇羽与與予
+yu4 玉遇育欲预預域御
+yuan1 冤
+yuan2 元园園原圆圓员員源缘緣
+yuan3 远遠
+yuan4 愿願院怨
+yue1 约約
+yue4 月越阅閱跃躍
+yun2 云雲
+yun3 允
+yun4 运運孕
+za2 杂雜砸
+zai1 灾災
+zai3 宰
+zai4 在再
+zan2 咱
+zan4 赞讚暂暫
+zang1 髒
+zang4 臟
+zao1 遭
+zao3 早澡
+zao4 造燥
+ze2 则則责責择擇
+zen3 怎
+zeng1 增
+zeng4 赠贈
+zha4 炸
+zhai1 摘
+zhai3 窄
+zhan1 沾粘
+zhan3 展
+zhan4 站战戰占
+zhang1 张張章
+zhang3 掌涨漲
+zhang4 丈帐帳账賬仗
+zhao1 招
+zhao3 找
+zhao4 照
+zhe1 遮
+zhe2 折哲
+zhe3 者
+zhe4 这這
+zhe5 着著
+zhen1 真针針珍
+zhen3 枕
+zhen4 阵陣震镇鎮
+zheng1 争爭睁睜征挣掙筝箏
+zheng3 整
+zheng4 证證政正
+zhi1 之知支汁织織枝隻
+zhi2 直值职職植
+zhi3 纸紙指止只
+zhi4 至制治志致智置
+zhong1 钟鐘终終忠中
+zhong3 肿腫
+zhong4 众眾
+zhou1 周舟州洲
+zhou4 皱皺
+zhu1 朱猪豬珠
+zhu2 竹逐
+zhu3 主煮
+zhu4 住注助祝驻駐柱
+zhua1 抓
+zhuan1 专專砖磚
+zhuan4 赚賺
+zhuang1 装裝庄莊妆妝
+zhuang4 状狀撞壮壯
+zhui1 追
+zhun3 准準
+zhuo1 桌捉
+zi1 资資姿
+zi3 子紫
+zi4 字自
+zong1 宗综綜
+zong3 总總
+zou3 走
+zou4 奏
+zu1 租
+zu2 足族
+zu3 组組祖阻
+zui3 嘴
+zui4 最醉罪
+zun1 尊遵
+zuo2 昨
+zuo3 左
+zuo4 做作坐座
+`
+
+// hanziPolyphones 讀音要看上下文的多音字，每行為「字 讀音...」，第一個讀音是最常見的讀音
+// 不在 hanziWords 詞語中時先用最常見的讀音，需要時再交給翻譯模型判斷
+const hanziPolyphones = `
+行 xing2 hang2
+长 chang2 zhang3
+長 chang2 zhang3
+乐 le4 yue4
+樂 le4 yue4
+还 hai2 huan2
+還 hai2 huan2
+地 di4 de5
+重 zhong4 chong2
+教 jiao4 jiao1
+干 gan4 gan1
+数 shu4 shu3 shuo4
+數 shu4 shu3 shuo4
+差 cha4 cha1 chai1
+调 diao4 tiao2
+調 diao4 tiao2
+处 chu4 chu3
+處 chu4 chu3
+种 zhong3 zhong4
+種 zhong3 zhong4
+转 zhuan3 zhuan4
+轉 zhuan3 zhuan4
+背 bei4 bei1
+薄 bao2 bo2
+朝 chao2 zhao1
+量 liang4 liang2
+露 lu4 lou4
+尽 jin4 jin3
+率 lv4 shuai4
+铺 pu1 pu4
+鋪 pu1 pu4
+切 qie4 qie1
+曲 qu3 qu1
+散 san4 san3
+舍 she4 she3
+省 sheng3 xing3
+载 zai4 zai3
+載 zai4 zai3
+脏 zang1 zang4
+模 mo2 mu2
+参 can1 shen1 cen1
+參 can1 shen1 cen1
+称 cheng1 chen4
+稱 cheng1 chen4
+冲 chong1 chong4
+衝 chong1 chong4
+供 gong1 gong4
+冠 guan1 guan4
+恶 e4 wu4 e3
+惡 e4 wu4 e3
+弹 tan2 dan4
+彈 tan2 dan4
+吐 tu3 tu4
+晃 huang3 huang4
+缝 feng2 feng4
+縫 feng2 feng4
+夹 jia1 jia2
+夾 jia1 jia2
+假 jia3 jia4
+扇 shan4 shan1
+剥 bo1 bao1
+扎 zha1 za1
+挨 ai1 ai2
+`
+
+// hanziWords 多音字在常用詞語中的讀音，每行為「詞語（可有簡繁兩種寫法） 各字讀音」
+const hanziWords = `
+快乐 快樂 kuai4 le4
+欢乐 歡樂 huan1 le4
+乐园 樂園 le4 yuan2
+音乐 音樂 yin1 yue4
+乐器 樂器 yue4 qi4
+乐队 樂隊 yue4 dui4
+了解 瞭解 liao3 jie3
+为了 為了 wei4 le5
+因为 因為 yin1 wei4
+成为 成為 cheng2 wei2
+以为 以為 yi3 wei2
+作为 作為 zuo4 wei2
+认为 認為 ren4 wei2
+行为 行為 xing2 wei2
+银行 銀行 yin2 hang2
+行业 行業 hang2 ye4
+长大 長大 zhang3 da4
+成长 成長 cheng2 zhang3
+生长 生長 sheng1 zhang3
+校长 校長 xiao4 zhang3
+还是 還是 hai2 shi4
+还有 還有 hai2 you3
+还要 還要 hai2 yao4
+还在 還在 hai2 zai4
+还会 還會 hai2 hui4
+归还 歸還 gui1 huan2
+还给 還給 huan2 gei3
+觉得 覺得 jue2 de5
+睡觉 睡覺 shui4 jiao4
+感觉 感覺 gan3 jue2
+记得 記得 ji4 de5
+值得 zhi2 de2
+得到 de2 dao4
+懂得 dong3 de5
+舍得 捨得 she3 de5
+获得 獲得 huo4 de2
+不得不 bu4 de2 bu4
+得不到 de2 bu4 dao4
+总得 總得 zong3 dei3
+目的 mu4 di4
+的确 的確 di2 que4
+地方 di4 fang1
+睡着 睡著 shui4 zhao2
+着急 著急 zhao2 ji2
+着迷 著迷 zhao2 mi2
+著名 zhu4 ming2
+重新 chong2 xin1
+重来 重來 chong2 lai2
+重复 重複 chong2 fu4
+重逢 chong2 feng2
+重叠 重疊 chong2 die2
+重演 chong2 yan3
+中毒 zhong4 du2
+中奖 中獎 zhong4 jiang3
+好奇 hao4 qi2
+爱好 愛好 ai4 hao4
+大夫 dai4 fu5
+便宜 pian2 yi2
+空白 kong4 bai2
+有空 you3 kong4
+空闲 空閒 kong4 xian2
+头发 頭髮 tou2 fa4
+长发 長髮 chang2 fa4
+发型 髮型 fa4 xing2
+教室 jiao4 shi4
+教会 教會 jiao1 hui4
+真相 zhen1 xiang4
+照相 zhao4 xiang4
+长相 長相 zhang3 xiang4
+少年 shao4 nian2
+少女 shao4 nv3
+多少 duo1 shao5
+数不清 數不清 shu3 bu4 qing1
+数一数 數一數 shu3 yi1 shu3
+差别 差別 cha1 bie2
+出差 chu1 chai1
+差异 差異 cha1 yi4
+偏差 pian1 cha1
+调皮 調皮 tiao2 pi2
+调整 調整 tiao2 zheng3
+协调 協調 xie2 tiao2
+自传 自傳 zi4 zhuan4
+处理 處理 chu3 li3
+相处 相處 xiang1 chu3
+种田 種田 zhong4 tian2
+种花 種花 zhong4 hua1
+种下 種下 zhong4 xia4
+种树 種樹 zhong4 shu4
+播种 播種 bo1 zhong3
+旋转 旋轉 xuan2 zhuan4
+转动 轉動 zhuan4 dong4
+打转 打轉 da3 zhuan4
+答应 答應 da1 ying5
+回应 回應 hui2 ying4
+反应 反應 fan3 ying4
+适应 適應 shi4 ying4
+上当 上當 shang4 dang4
+当作 當作 dang4 zuo4
+当成 當成 dang4 cheng2
+当做 當做 dang4 zuo4
+过分 過分 guo4 fen4
+背包 bei1 bao1
+背负 背負 bei1 fu4
+看守 kan1 shou3
+灾难 災難 zai1 nan4
+苦难 苦難 ku3 nan4
+患难 患難 huan4 nan4
+朝阳 朝陽 zhao1 yang2
+朝夕 zhao1 xi1
+朝气 朝氣 zhao1 qi4
+宝藏 寶藏 bao3 zang4
+西藏 xi1 zang4
+暖和 nuan3 huo5
+思量 si1 liang2
+商量 shang1 liang5
+露出 lou4 chu1
+露面 lou4 mian4
+落下 luo4 xia4
+丢三落四 丟三落四 diu1 san1 la4 si4
+将军 將軍 jiang1 jun1
+结实 結實 jie1 shi5
+几乎 幾乎 ji1 hu1
+尽管 儘管 jin3 guan3
+尽量 儘量 jin3 liang4
+角色 jue2 se4
+积累 積累 ji1 lei3
+累积 累積 lei3 ji1
+率领 率領 shuai4 ling3
+淹没 淹沒 yan1 mo4
+沉没 沉沒 chen2 mo4
+勉强 勉強 mian3 qiang3
+倔强 倔強 jue2 jiang4
+一切 yi2 qie4
+亲切 親切 qin1 qie4
+切开 切開 qie1 kai1
+弯曲 彎曲 wan1 qu1
+散文 san3 wen2
+散开 散開 san4 kai1
+舍不得 捨不得 she3 bu4 de5
+宿舍 su4 she4
+什么 什麼 shen2 me5
+为什么 為什麼 wei4 shen2 me5
+反省 fan3 xing3
+高兴 高興 gao1 xing4
+兴趣 興趣 xing4 qu4
+要求 yao1 qiu2
+参与 參與 can1 yu4
+人参 人參 ren2 shen1
+参差 參差 cen1 ci1
+心脏 xin1 zang4
+炸弹 炸彈 zha4 dan4
+正月 zheng1 yue4
+对称 對稱 dui4 chen4
+冲动 衝動 chong1 dong4
+供应 供應 gong1 ying4
+冠军 冠軍 guan4 jun1
+给予 給予 ji3 yu3
+恶心 惡心 e3 xin1
+厌恶 厭惡 yan4 wu4
+可恶 可惡 ke3 wu4
+子弹 子彈 zi3 dan4
+更新 geng1 xin1
+变更 變更 bian4 geng1
+摇晃 搖晃 yao2 huang4
+缝隙 縫隙 feng4 xi4
+假期 jia4 qi1
+放假 fang4 jia4
+卡片 ka3 pian4
+发卡 髮卡 fa4 qia3
+会计 會計 kuai4 ji4
+挣扎 掙扎 zheng1 zha2
+一会儿 一會兒 yi2 hui4 er5
+模样 模樣 mu2 yang4
+模糊 mo2 hu5
+奇数 奇數 ji1 shu4
+和平 he2 ping2
+附和 fu4 he4
+干净 乾淨 gan1 jing4
+干杯 乾杯 gan1 bei1
+干燥 乾燥 gan1 zao4
+乾坤 qian2 kun1
+相同 xiang1 tong2
+间隔 間隔 jian4 ge2
+间断 間斷 jian4 duan4
+降落 jiang4 luo4
+投降 tou2 xiang2
+地上 di4 shang4
+慢慢地 man4 man4 de5
+静静地 靜靜地 jing4 jing4 de5
+轻轻地 輕輕地 qing1 qing1 de5
+默默地 mo4 mo4 de5
+深深地 shen1 shen1 de5
+悄悄地 qiao1 qiao1 de5
+好好地 hao3 hao3 de5
+彷彿 fang3 fu2
+仿佛 fang3 fu2
+似的 shi4 de5
+首都 shou3 du1
+不了 bu4 liao3
+了不起 liao3 bu4 qi3
+化为 化為 hua4 wei2
+变为 變為 bian4 wei2
+称为 稱為 cheng1 wei2
+击中 擊中 ji1 zhong4
+猜中 cai1 zhong4
+一觉 一覺 yi1 jiao4
+增长 增長 zeng1 zhang3
+长辈 長輩 zhang3 bei4
+`
@@ -0,0 +1,132 @@
+package romanize
+
+import "strings"
+
+// kanaTable 平假名的平文式（Hepburn）羅馬字，片假名先轉成平假名再查表
+var kanaTable = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o", 'ゎ': "wa",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo",
+}
+
+// 片假名與平假名的編碼差距（ァ U+30A1 對應 ぁ U+3041）
+const katakanaOffset = 0x60
+
+// toHiragana 片假名轉平假名，其他字元不變
+func toHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - katakanaOffset
+	}
+	return r
+}
+
+// kanaToRomaji 轉寫一段連續的假名
+// 處理拗音（きゃ）、外來語的小寫母音（ファ、ティ）、促音（っ）、撥音（ん）與長音符號（ー）
+func kanaToRomaji(run []rune) string {
+	var sb strings.Builder
+	geminate := false // 前一個字是促音
+	for i := 0; i < len(run); i++ {
+		r := toHiragana(run[i])
+
+		switch r {
+		case 'っ':
+			geminate = true
+			continue
+		case 'ー':
+			if last := lastVowel(sb.String()); last != 0 {
+				sb.WriteByte(last)
+			}
+			continue
+		}
+
+		syllable, ok := kanaTable[r]
+		if !ok {
+			sb.WriteRune(run[i])
+			geminate = false
+			continue
+		}
+		// 後面接小寫假名時合成一個音節
+		if i+1 < len(run) {
+			if combined, ok := combineSmall(syllable, toHiragana(run[i+1])); ok {
+				syllable = combined
+				i++
+			}
+		}
+
+		if r == 'ん' && i+1 < len(run) {
+			// 後面接母音或 y 時加上撇號避免誤讀（例如 ん+あ 寫成 n'a）
+			if next, ok := kanaTable[toHiragana(run[i+1])]; ok && strings.ContainsRune("aiueoy", rune(next[0])) {
+				syllable = "n'"
+			}
+		}
+		if geminate {
+			syllable = doubleConsonant(syllable)
+			geminate = false
+		}
+		sb.WriteString(syllable)
+	}
+	return sb.String()
+}
+
+// combineSmall 將音節與後面的小寫假名合成，例如 ki+ゃ → kya、shi+ゃ → sha、fu+ァ → fa
+func combineSmall(syllable string, small rune) (string, bool) {
+	switch small {
+	case 'ゃ', 'ゅ', 'ょ':
+		if len(syllable) < 2 || !strings.HasSuffix(syllable, "i") {
+			return "", false
+		}
+		vowel := kanaTable[small][1:]
+		stem := syllable[:len(syllable)-1]
+		switch stem {
+		case "sh", "ch", "j":
+			return stem + vowel, true
+		}
+		return stem + "y" + vowel, true
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		vowel := kanaTable[small]
+		if syllable == "u" {
+			return "w" + vowel, true // ウィ → wi
+		}
+		if len(syllable) < 2 {
+			return "", false
+		}
+		return syllable[:len(syllable)-1] + vowel, true // ファ → fa、ティ → ti、シェ → she
+	}
+	return "", false
+}
+
+// doubleConsonant 促音：重複下一個音節的子音，ch 寫成 tch
+func doubleConsonant(syllable string) string {
+	switch {
+	case syllable == "" || strings.ContainsRune("aiueon", rune(syllable[0])):
+		return syllable
+	case strings.HasPrefix(syllable, "ch"):
+		return "t" + syllable
+	}
+	return syllable[:1] + syllable
+}
+
+// lastVowel 目前結果的最後一個字母是母音時回傳它（長音符號重複該母音）
+func lastVowel(s string) byte {
+	if s == "" {
+		return 0
+	}
+	if c := s[len(s)-1]; strings.IndexByte("aiueo", c) >= 0 {
+		return c
+	}
+	return 0
+}
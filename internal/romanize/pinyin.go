@@ -0,0 +1,63 @@
+package romanize
+
+import (
+	"regexp"
+	"strings"
+)
+
+// numberedSyllable 數字標調的拼音音節，例如 ni3、lv4、nu:3
+var numberedSyllable = regexp.MustCompile(`(?i)[a-zü:]+[0-5]`)
+
+// toneVowels 各母音的一至四聲
+var toneVowels = map[rune][4]rune{
+	'a': {'ā', 'á', 'ǎ', 'à'},
+	'e': {'ē', 'é', 'ě', 'è'},
+	'i': {'ī', 'í', 'ǐ', 'ì'},
+	'o': {'ō', 'ó', 'ǒ', 'ò'},
+	'u': {'ū', 'ú', 'ǔ', 'ù'},
+	'ü': {'ǖ', 'ǘ', 'ǚ', 'ǜ'},
+	'A': {'Ā', 'Á', 'Ǎ', 'À'},
+	'E': {'Ē', 'É', 'Ě', 'È'},
+	'I': {'Ī', 'Í', 'Ǐ', 'Ì'},
+	'O': {'Ō', 'Ó', 'Ǒ', 'Ò'},
+	'U': {'Ū', 'Ú', 'Ǔ', 'Ù'},
+	'Ü': {'Ǖ', 'Ǘ', 'Ǚ', 'Ǜ'},
+}
+
+// ToneMarks 將數字標調的拼音（ni3 hao3）轉成聲調符號（nǐ hǎo），v 與 u: 轉成 ü
+// 沒有數字的文字原樣回傳，因此已經帶聲調符號的拼音不受影響
+func ToneMarks(text string) string {
+	return numberedSyllable.ReplaceAllStringFunc(text, markSyllable)
+}
+
+// markSyllable 標調規則：有 a 或 e 標在其上，ou 標在 o，否則標在最後一個母音；輕聲（5 或 0）不標
+func markSyllable(syllable string) string {
+	tone := int(syllable[len(syllable)-1] - '0')
+	body := syllable[:len(syllable)-1]
+	body = strings.NewReplacer("u:", "ü", "U:", "Ü", "v", "ü", "V", "Ü").Replace(body)
+	if tone < 1 || tone > 4 {
+		return body
+	}
+
+	runes := []rune(body)
+	target := -1
+	lower := strings.ToLower(body)
+	switch {
+	case strings.ContainsAny(lower, "ae"):
+		target = strings.IndexFunc(body, func(r rune) bool { return strings.ContainsRune("aeAE", r) })
+	case strings.Contains(lower, "ou"):
+		target = strings.IndexAny(body, "oO")
+	default:
+		for i := len(runes) - 1; i >= 0; i-- {
+			if _, ok := toneVowels[runes[i]]; ok {
+				target = len(string(runes[:i]))
+				break
+			}
+		}
+	}
+	if target < 0 {
+		return syllable // 沒有母音（例如 m2、mp3）不是可標調的音節，原樣保留
+	}
+	vowel := []rune(body[target:])[0]
+	return body[:target] + string(toneVowels[vowel][tone-1]) + body[target+len(string(vowel)):]
+}
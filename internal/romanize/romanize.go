@@ -0,0 +1,134 @@
+// Package romanize 將歌詞原文轉寫成拉丁字母：俄文（西里爾字母）、日文假名（平文式羅馬字）、
+// 韓文（文化觀光部式）直接查表轉換；中文以常用字與詞語表轉成漢語拼音，
+// 日文漢字與無法判斷的多音字交給翻譯模型處理
+package romanize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// script 需要轉寫的文字類型
+type script int
+
+const (
+	scriptOther    script = iota // 拉丁字母、數字、標點等，原樣保留
+	scriptCyrillic               // 西里爾字母
+	scriptKana                   // 平假名、片假名與長音符號
+	scriptHangul                 // 韓文音節
+	scriptHan                    // 漢字
+)
+
+func classify(r rune) script {
+	switch {
+	case unicode.Is(unicode.Cyrillic, r):
+		return scriptCyrillic
+	case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r), r == 'ー':
+		return scriptKana
+	case r >= hangulBase && r <= hangulLast:
+		return scriptHangul
+	case unicode.Is(unicode.Han, r), r == '々':
+		return scriptHan
+	}
+	return scriptOther
+}
+
+// cjkPunct 中日韓標點轉成對應的 ASCII 標點
+var cjkPunct = strings.NewReplacer(
+	"、", ", ", "。", ". ", "，", ", ", "「", "\"", "」", "\"", "『", "\"", "』", "\"",
+	"・", " ", "〜", "~", "…", "...", "（", "(", "）", ")",
+)
+
+// Needed 文字是否包含需要轉寫的字元
+func Needed(text string) bool {
+	for _, r := range text {
+		if classify(r) != scriptOther {
+			return true
+		}
+	}
+	return false
+}
+
+// HasHan 文字是否包含漢字（無法在本地轉寫）
+func HasHan(text string) bool {
+	for _, r := range text {
+		if classify(r) == scriptHan {
+			return true
+		}
+	}
+	return false
+}
+
+// HasKana 文字是否包含假名（用來判斷含漢字的句子是日文還是中文）
+func HasKana(text string) bool {
+	for _, r := range text {
+		if classify(r) == scriptKana {
+			return true
+		}
+	}
+	return false
+}
+
+// Romanize 在本地轉寫文字，拉丁字母與標點原樣保留
+// 不需要轉寫時回傳空字串；含有漢字時 ok 為 false，中文改用 Pinyin，日文需要翻譯模型
+func Romanize(text string) (result string, ok bool) {
+	if !Needed(text) {
+		return "", true
+	}
+	if HasHan(text) {
+		return "", false
+	}
+	return transliterate(text, nil), true
+}
+
+// transliterate 依文字類型分段轉寫，漢字交給 han 處理（nil 時原樣保留）
+func transliterate(text string, han func(run []rune) string) string {
+	runes := []rune(norm.NFKC.String(text))
+	var sb strings.Builder
+	spaceNext := false // 前一段是拼音，接著的拉丁字母或數字要空格分開
+	for i := 0; i < len(runes); {
+		kind := classify(runes[i])
+		j := i + 1
+		for j < len(runes) && classify(runes[j]) == kind {
+			j++
+		}
+		run := runes[i:j]
+		if spaceNext && isAlnum(run[0]) {
+			sb.WriteByte(' ')
+		}
+		spaceNext = false
+		switch kind {
+		case scriptCyrillic:
+			sb.WriteString(cyrillicToLatin(run))
+		case scriptKana:
+			sb.WriteString(kanaToRomaji(run))
+		case scriptHangul:
+			sb.WriteString(hangulToLatin(run))
+		case scriptHan:
+			if han == nil {
+				sb.WriteString(string(run))
+				break
+			}
+			if last, _ := utf8.DecodeLastRuneInString(sb.String()); sb.Len() > 0 && !unicode.IsSpace(last) && !strings.ContainsRune("(\"", last) {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(han(run))
+			spaceNext = true
+		default:
+			sb.WriteString(string(run))
+		}
+		i = j
+	}
+	return strings.Join(strings.Fields(cjkPunct.Replace(sb.String())), " ")
+}
+
+// capitalize 原字為大寫時將轉寫結果的第一個字母改成大寫
+func capitalize(s string, upper bool) string {
+	if !upper || s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
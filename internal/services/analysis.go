@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"multilang-learner/internal/audio"
+	"multilang-learner/internal/models"
+)
+
+// 音訊分析的預設值與上限
+const (
+	analysisSampleRate        = 22050
+	defaultWaveformBuckets    = 500
+	maxWaveformBuckets        = 5000
+	defaultSilenceThresholdDB = -40.0
+	defaultMinSilence         = 300 * time.Millisecond
+)
+
+// ErrInvalidBuckets 波形格數超出範圍
+var ErrInvalidBuckets = errors.New("波形格數必須介於 1 到 5000")
+
+// AnalysisOptions 音訊分析選項，零值使用預設
+type AnalysisOptions struct {
+	Buckets    int           // 波形格數
+	SilenceDB  float64       // 低於此音量視為靜音 (dBFS)
+	MinSilence time.Duration // 靜音區段的最短長度
+}
+
+// Analyze 分析整首原曲（segIdx < 0）或段落音訊的音量、靜音與波形
+// 音訊以單聲道 22.05 kHz 解碼後直接在 Go 中計算
+func (s *ProcessService) Analyze(fileID string, segIdx int, kind string, opts AnalysisOptions) (*models.AudioAnalysis, error) {
+	if opts.Buckets == 0 {
+		opts.Buckets = defaultWaveformBuckets
+	}
+	if opts.Buckets < 1 || opts.Buckets > maxWaveformBuckets {
+		return nil, ErrInvalidBuckets
+	}
+	if opts.SilenceDB == 0 {
+		opts.SilenceDB = defaultSilenceThresholdDB
+	}
+	if opts.MinSilence == 0 {
+		opts.MinSilence = defaultMinSilence
+	}
+
+	path, err := s.analysisPath(fileID, segIdx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := audio.NewProcessor(false).DecodePCM(context.Background(), path, analysisSampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := buf.Analyze()
+	result := &models.AudioAnalysis{
+		Duration:   buf.Duration().Seconds(),
+		SampleRate: analysisSampleRate,
+		PeakDB:     stats.PeakDB,
+		RMSDB:      stats.RMSDB,
+		Silences:   []models.TimeRange{},
+		Waveform:   []models.WaveformBucket{},
+	}
+	for _, r := range buf.Silences(opts.SilenceDB, opts.MinSilence) {
+		result.Silences = append(result.Silences, models.TimeRange{Start: r.Start.Seconds(), End: r.End.Seconds()})
+	}
+	for _, b := range buf.Waveform(opts.Buckets) {
+		result.Waveform = append(result.Waveform, models.WaveformBucket{Min: float64(b.Min), Max: float64(b.Max), RMS: float64(b.RMS)})
+	}
+	return result, nil
+}
+
+// analysisPath 取得要分析的音檔路徑
+func (s *ProcessService) analysisPath(fileID string, segIdx int, kind string) (string, error) {
+	if segIdx < 0 {
+		file, err := s.fileService.GetFile(fileID)
+		if err != nil {
+			return "", err
+		}
+		return file.Filepath, nil
+	}
+
+	segments, err := s.GetSegmentsData(fileID)
+	if err != nil {
+		return "", err
+	}
+	if segIdx >= len(segments.Segments) {
+		return "", fmt.Errorf("無效的段落索引: %d", segIdx)
+	}
+	path := segments.Segments[segIdx].AudioPath
+	if kind == ClipTTS {
+		path = segments.Segments[segIdx].TTSPath
+	}
+	if path == "" {
+		return "", ErrSegmentAudioNotFound
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "", ErrSegmentAudioNotFound
+	}
+	return path, nil
+}
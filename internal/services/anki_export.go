@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"multilang-learner/internal/anki"
+	"multilang-learner/internal/models"
+)
+
+var ErrNoAnkiNotes = errors.New("沒有可導出的段落（請先完成處理）")
+
+// ankiModel 每個段落一則筆記：正面為原曲段落與原文，背面為翻譯與 TTS
+var ankiModel = anki.Model{
+	Name:   "Multilang Learner Segment",
+	Fields: []string{"Original", "Audio", "Translation", "TTS", "Song"},
+	Front:  `{{Audio}}<div class="original">{{Original}}</div>`,
+	Back:   `{{FrontSide}}<hr id="answer"><div class="translation">{{Translation}}</div>{{TTS}}<div class="song">{{Song}}</div>`,
+	CSS: `.card { font-family: sans-serif; font-size: 24px; text-align: center; }
+.translation { color: #2563eb; }
+.song { margin-top: 1em; font-size: 14px; color: #888; }`,
+}
+
+// AnkiPackage 將檔案的段落做成 Anki 牌組（牌組名稱為歌名）
+func (s *ExportService) AnkiPackage(fileID string) (*anki.Package, error) {
+	file, err := s.fileService.GetFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &anki.Package{Deck: exportBaseName(file), Model: ankiModel}
+	if err := s.addAnkiNotes(pkg, file); err != nil {
+		return nil, err
+	}
+	if len(pkg.Notes) == 0 {
+		return nil, ErrNoAnkiNotes
+	}
+	return pkg, nil
+}
+
+// AnkiCollectionPackage 將多首歌的段落做成同一個牌組，依歌名加上標籤
+// 尚未處理的檔案略過並記錄在 failed
+func (s *ExportService) AnkiCollectionPackage(deck string, fileIDs []string) (*anki.Package, map[string]string, error) {
+	pkg := &anki.Package{Deck: deck, Model: ankiModel}
+	failed := make(map[string]string)
+	for _, fileID := range fileIDs {
+		file, err := s.fileService.GetFile(fileID)
+		if err == nil {
+			err = s.addAnkiNotes(pkg, file)
+		}
+		if err != nil {
+			failed[fileID] = err.Error()
+		}
+	}
+	if len(pkg.Notes) == 0 {
+		return nil, failed, ErrNoAnkiNotes
+	}
+	return pkg, failed, nil
+}
+
+// addAnkiNotes 加入檔案中每個有意義的段落，媒體檔以檔案 ID 與段落編號命名避免衝突
+func (s *ExportService) addAnkiNotes(pkg *anki.Package, file *models.MusicFile) error {
+	segments, err := s.processService.GetSegmentsData(file.ID)
+	if err != nil {
+		return fmt.Errorf("尚未處理: %w", err)
+	}
+
+	song := exportBaseName(file)
+	tag := strings.Join(strings.Fields(song), "_")
+	for _, seg := range segments.Segments {
+		if !seg.IsMeaningful {
+			continue
+		}
+		base := fmt.Sprintf("mll_%s_%03d", file.ID, seg.Index)
+		audio := addAnkiMedia(pkg, base, seg.AudioPath)
+		if audio == "" {
+			continue
+		}
+		tts := addAnkiMedia(pkg, base+"_tts", seg.TTSPath)
+		pkg.Notes = append(pkg.Notes, anki.Note{
+			GUID: anki.GUID(fmt.Sprintf("%s/%d", file.ID, seg.Index)),
+			Fields: []string{
+				html.EscapeString(seg.OriginalText),
+				audio,
+				html.EscapeString(seg.TTSText),
+				tts,
+				html.EscapeString(song),
+			},
+			Tags: []string{"multilang-learner", tag},
+		})
+	}
+	return nil
+}
+
+// addAnkiMedia 加入媒體檔並回傳欄位中的播放標記，檔案不存在時回傳空字串
+func addAnkiMedia(pkg *anki.Package, base, path string) string {
+	if path == "" {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	name := base + filepath.Ext(path)
+	pkg.Media = append(pkg.Media, anki.Media{Name: name, Path: path})
+	return anki.Sound(name)
+}
@@ -0,0 +1,498 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multilang-learner/internal/anki"
+	"multilang-learner/internal/models"
+)
+
+var (
+	ErrCollectionNotFound   = errors.New("集合不存在")
+	ErrEmptyCollection      = errors.New("集合內沒有檔案")
+	ErrCollectionExportBusy = errors.New("集合導出進行中")
+	ErrNoCollectionExport   = errors.New("集合沒有導出紀錄")
+)
+
+// CollectionService 集合服務
+type CollectionService struct {
+	dataDir        string
+	fileService    *FileService
+	processService *ProcessService
+	exportService  *ExportService
+	collections    map[string]*models.Collection
+	exports        map[string]*models.CollectionExportJob // 集合 ID -> 最近一次導出工作
+	mu             sync.RWMutex
+}
+
+// NewCollectionService 建立集合服務
+func NewCollectionService(dataDir string, fileService *FileService, processService *ProcessService, exportService *ExportService) *CollectionService {
+	s := &CollectionService{
+		dataDir:        dataDir,
+		fileService:    fileService,
+		processService: processService,
+		exportService:  exportService,
+		collections:    make(map[string]*models.Collection),
+		exports:        make(map[string]*models.CollectionExportJob),
+	}
+	s.load()
+	fileService.OnReset(s.discardExports)
+	return s
+}
+
+// load 載入 collections.json
+func (s *CollectionService) load() {
+	data, err := os.ReadFile(filepath.Join(s.dataDir, "collections.json"))
+	if err != nil {
+		return
+	}
+	var list []*models.Collection
+	if json.Unmarshal(data, &list) != nil {
+		return
+	}
+	for _, c := range list {
+		s.collections[c.ID] = c
+	}
+}
+
+// save 儲存 collections.json（呼叫者需持有寫鎖）
+func (s *CollectionService) save() error {
+	list := s.sorted()
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dataDir, "collections.json"), data, 0644)
+}
+
+// sorted 依名稱排序的集合列表
+func (s *CollectionService) sorted() []*models.Collection {
+	list := make([]*models.Collection, 0, len(s.collections))
+	for _, c := range s.collections {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+	return list
+}
+
+// List 列出所有集合
+func (s *CollectionService) List() []*models.Collection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := s.sorted()
+	for i, c := range list {
+		list[i] = cloneCollection(c)
+	}
+	return list
+}
+
+// Get 獲取集合（回傳複本，呼叫者可以在鎖外安全地讀取 FileIDs）
+func (s *CollectionService) Get(id string) (*models.Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if c, ok := s.collections[id]; ok {
+		return cloneCollection(c), nil
+	}
+	return nil, ErrCollectionNotFound
+}
+
+// cloneCollection 複製集合與檔案清單（呼叫者需持有鎖）
+func cloneCollection(c *models.Collection) *models.Collection {
+	copied := *c
+	copied.FileIDs = append([]string{}, c.FileIDs...)
+	return &copied
+}
+
+// Create 建立集合
+func (s *CollectionService) Create(name, description string) (*models.Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = strings.TrimSpace(name)
+	if err := s.checkName("", name); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c := &models.Collection{
+		ID:          generateID(),
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		FileIDs:     []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.collections[c.ID] = c
+	return cloneCollection(c), s.save()
+}
+
+// Update 更新集合名稱與說明
+func (s *CollectionService) Update(id, name, description string) (*models.Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[id]
+	if !ok {
+		return nil, ErrCollectionNotFound
+	}
+	name = strings.TrimSpace(name)
+	if err := s.checkName(id, name); err != nil {
+		return nil, err
+	}
+
+	c.Name = name
+	c.Description = strings.TrimSpace(description)
+	c.UpdatedAt = time.Now()
+	return cloneCollection(c), s.save()
+}
+
+// Delete 刪除集合（不會刪除其中的檔案）
+func (s *CollectionService) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.collections[id]; !ok {
+		return ErrCollectionNotFound
+	}
+	delete(s.collections, id)
+	os.RemoveAll(filepath.Join(s.dataDir, "collections", id))
+	return s.save()
+}
+
+// AddFiles 將檔案加入集合
+func (s *CollectionService) AddFiles(id string, fileIDs []string) (*models.Collection, error) {
+	for _, fileID := range fileIDs {
+		if _, err := s.fileService.GetFile(fileID); err != nil {
+			return nil, fmt.Errorf("檔案不存在: %s", fileID)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[id]
+	if !ok {
+		return nil, ErrCollectionNotFound
+	}
+	for _, fileID := range fileIDs {
+		if !c.HasFile(fileID) {
+			c.FileIDs = append(c.FileIDs, fileID)
+		}
+	}
+	c.UpdatedAt = time.Now()
+	return cloneCollection(c), s.save()
+}
+
+// RemoveFile 將檔案移出集合
+func (s *CollectionService) RemoveFile(id, fileID string) (*models.Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[id]
+	if !ok {
+		return nil, ErrCollectionNotFound
+	}
+	c.FileIDs = removeString(c.FileIDs, fileID)
+	c.UpdatedAt = time.Now()
+	return cloneCollection(c), s.save()
+}
+
+// RemoveFileEverywhere 檔案刪除時從所有集合移除
+func (s *CollectionService) RemoveFileEverywhere(fileID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for _, c := range s.collections {
+		if c.HasFile(fileID) {
+			c.FileIDs = removeString(c.FileIDs, fileID)
+			c.UpdatedAt = time.Now()
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.save()
+}
+
+// discardExports 檔案內容被取代時，刪除包含該檔案的集合導出（ZIP 內還是舊的音檔）
+func (s *CollectionService) discardExports(fileID string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.collections {
+		if c.HasFile(fileID) {
+			os.Remove(filepath.Join(s.dataDir, "collections", c.ID, "export.zip"))
+		}
+	}
+}
+
+// ProcessCollection 對集合內所有檔案開始處理
+// 回傳已開始處理的檔案與失敗原因
+func (s *CollectionService) ProcessCollection(id string) ([]string, map[string]string, error) {
+	c, err := s.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	started := []string{}
+	failed := make(map[string]string)
+	for _, fileID := range c.FileIDs {
+		if err := s.processService.StartProcess(fileID, nil); err != nil {
+			failed[fileID] = err.Error()
+			continue
+		}
+		started = append(started, fileID)
+	}
+	return started, failed, nil
+}
+
+// StartExport 建立集合導出工作並在背景執行，同一個集合同時只能有一個導出
+func (s *CollectionService) StartExport(id string) (*models.CollectionExportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[id]
+	if !ok {
+		return nil, ErrCollectionNotFound
+	}
+	if len(c.FileIDs) == 0 {
+		return nil, ErrEmptyCollection
+	}
+	if job, ok := s.exports[id]; ok && (job.Status == models.ExportQueued || job.Status == models.ExportRunning) {
+		return nil, ErrCollectionExportBusy
+	}
+
+	job := &models.CollectionExportJob{
+		ID:           generateID(),
+		CollectionID: id,
+		Status:       models.ExportQueued,
+		Message:      "等待中",
+		Total:        len(c.FileIDs),
+		Failed:       make(map[string]string),
+		CreatedAt:    time.Now(),
+	}
+	s.exports[id] = job
+	// 檔案清單在建立工作時固定下來，導出期間集合的增減不影響這次導出
+	go s.runExport(job, append([]string{}, c.FileIDs...))
+	return copyExportJob(job), nil
+}
+
+// GetExport 集合最近一次導出工作的狀態（服務重新啟動後只剩 ZIP，沒有工作紀錄）
+func (s *CollectionService) GetExport(id string) (*models.CollectionExportJob, error) {
+	s.mu.RLock()
+	job, ok := s.exports[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrNoCollectionExport
+	}
+	return s.exportSnapshot(job), nil
+}
+
+// exportSnapshot 複製工作狀態，避免呼叫者讀到背景執行中的修改
+func (s *CollectionService) exportSnapshot(job *models.CollectionExportJob) *models.CollectionExportJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyExportJob(job)
+}
+
+// copyExportJob 複製工作與失敗清單（呼叫者需持有鎖）
+func copyExportJob(job *models.CollectionExportJob) *models.CollectionExportJob {
+	copied := *job
+	copied.Failed = make(map[string]string, len(job.Failed))
+	for k, v := range job.Failed {
+		copied.Failed[k] = v
+	}
+	return &copied
+}
+
+// updateExport 在鎖內更新工作狀態
+func (s *CollectionService) updateExport(job *models.CollectionExportJob, fn func(job *models.CollectionExportJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(job)
+}
+
+// runExport 逐首導出並打包成 ZIP
+// 先寫到暫存檔，完成後才改名成 export.zip，下載中的舊 ZIP 與失敗的導出都不會留下半個檔案
+func (s *CollectionService) runExport(job *models.CollectionExportJob, fileIDs []string) {
+	s.updateExport(job, func(job *models.CollectionExportJob) {
+		job.Status = models.ExportRunning
+		job.Message = "導出中"
+	})
+
+	size, err := s.writeExportZip(job, fileIDs)
+
+	s.updateExport(job, func(job *models.CollectionExportJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		if err != nil {
+			job.Status = models.ExportFailed
+			job.Message = err.Error()
+			return
+		}
+		job.Status = models.ExportDone
+		job.Progress = 100
+		job.Message = "完成"
+		job.Size = size
+	})
+}
+
+// writeExportZip 導出所有檔案並寫入暫存的 ZIP，成功時改名為 export.zip 並回傳大小
+func (s *CollectionService) writeExportZip(job *models.CollectionExportJob, fileIDs []string) (int64, error) {
+	exportDir := filepath.Join(s.dataDir, "collections", job.CollectionID)
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return 0, err
+	}
+	out, err := os.CreateTemp(exportDir, "export-*.zip.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	zw := zip.NewWriter(out)
+
+	added := 0
+	for i, fileID := range fileIDs {
+		if err := s.addExportToZip(zw, i, fileID); err != nil {
+			s.updateExport(job, func(job *models.CollectionExportJob) { job.Failed[fileID] = err.Error() })
+		} else {
+			added++
+		}
+		s.updateExport(job, func(job *models.CollectionExportJob) {
+			job.Completed = i + 1
+			job.Progress = float64(i+1) / float64(len(fileIDs)) * 100
+		})
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	if added == 0 {
+		return 0, errors.New("沒有可導出的檔案（請先完成處理）")
+	}
+	info, err := out.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if err := out.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(out.Name(), filepath.Join(exportDir, "export.zip")); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// addExportToZip 導出一首歌並加入 ZIP，檔名以集合內的順序編號
+func (s *CollectionService) addExportToZip(zw *zip.Writer, i int, fileID string) error {
+	file, err := s.fileService.GetFile(fileID)
+	if err != nil {
+		return err
+	}
+	exportJob, err := s.exportService.ExportSync(fileID, models.ExportOptions{})
+	if err != nil {
+		return err
+	}
+
+	base := fmt.Sprintf("%02d - %s", i+1, exportBaseName(file))
+	if err := addFileToZip(zw, exportJob.Path, base+filepath.Ext(exportJob.Path)); err != nil {
+		return err
+	}
+	// 同名 LRC 讓播放器自動載入歌詞
+	if exportJob.LyricsPath != "" {
+		addFileToZip(zw, exportJob.LyricsPath, base+".lrc")
+	}
+	return nil
+}
+
+// AnkiPackage 將集合內所有已處理的檔案做成一個 Anki 牌組（牌組名稱為集合名稱）
+func (s *CollectionService) AnkiPackage(id string) (*anki.Package, map[string]string, error) {
+	c, err := s.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(c.FileIDs) == 0 {
+		return nil, nil, ErrEmptyCollection
+	}
+	return s.exportService.AnkiCollectionPackage(c.Name, c.FileIDs)
+}
+
+// GetExportPath 獲取集合導出檔案路徑
+func (s *CollectionService) GetExportPath(id string) (string, error) {
+	zipPath := filepath.Join(s.dataDir, "collections", id, "export.zip")
+	if _, err := os.Stat(zipPath); err != nil {
+		return "", errors.New("導出檔案不存在")
+	}
+	return zipPath, nil
+}
+
+// checkName 檢查集合名稱（不可空白、不可重複）
+func (s *CollectionService) checkName(id, name string) error {
+	if name == "" {
+		return errors.New("集合名稱不可為空")
+	}
+	for _, c := range s.collections {
+		if c.ID != id && strings.EqualFold(c.Name, name) {
+			return fmt.Errorf("集合名稱已存在: %s", name)
+		}
+	}
+	return nil
+}
+
+// exportBaseName 導出檔案名稱（優先使用歌曲標題）
+func exportBaseName(file *models.MusicFile) string {
+	name := file.Metadata.Title
+	if name != "" && file.Metadata.Artist != "" {
+		name = file.Metadata.Artist + " - " + name
+	}
+	if name == "" {
+		name = strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename))
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// addFileToZip 將檔案寫入 ZIP
+func addFileToZip(zw *zip.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// removeString 移除字串
+func removeString(list []string, target string) []string {
+	result := list[:0]
+	for _, v := range list {
+		if v != target {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"multilang-learner/internal/models"
+)
+
+// DuplicatePolicy 上傳到重複歌曲時的處理方式
+type DuplicatePolicy string
+
+const (
+	DuplicateReject  DuplicatePolicy = ""        // 回傳 DuplicateFileError，由使用者決定
+	DuplicateLink    DuplicatePolicy = "link"    // 不建立新檔案，直接使用既有檔案
+	DuplicateReplace DuplicatePolicy = "replace" // 以新上傳的音檔取代既有檔案（保留 ID、標籤與設定）
+	DuplicateKeep    DuplicatePolicy = "keep"    // 兩者都保留
+)
+
+// ParseDuplicatePolicy 解析重複處理方式
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch p := DuplicatePolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case DuplicateReject, DuplicateLink, DuplicateReplace, DuplicateKeep:
+		return p, nil
+	}
+	return "", fmt.Errorf("無效的重複處理方式: %s", s)
+}
+
+// 判斷為同一首歌的門檻
+const (
+	duplicateDurationTolerance = 2.0 // 秒
+	duplicateMinScore          = 0.8
+)
+
+// DuplicateMatch 重複偵測結果
+type DuplicateMatch struct {
+	Existing *models.MusicFile
+	Reason   string  // "hash": 內容完全相同；"similar": 時長與標籤相近
+	Score    float64 // 相似度 0~1，內容相同時為 1
+}
+
+// DuplicateFileError 上傳的檔案可能與既有檔案重複
+type DuplicateFileError struct {
+	Match *DuplicateMatch
+}
+
+func (e *DuplicateFileError) Error() string {
+	if e.Match.Reason == "hash" {
+		return "檔案已存在: " + e.Match.Existing.ID
+	}
+	return "可能與既有檔案重複: " + e.Match.Existing.ID
+}
+
+// findDuplicate 先比對內容雜湊，再以時長與標題/歌手相似度找出最可能的重複檔案
+func (s *FileService) findDuplicate(staged *stagedUpload) *DuplicateMatch {
+	if existing := s.FindByHash(staged.contentHash); existing != nil {
+		return &DuplicateMatch{Existing: existing, Reason: "hash", Score: 1}
+	}
+
+	tags := staged.probe.tags()
+	title := firstTag(tags, "title")
+	artist := firstTag(tags, "artist", "album_artist", "performer")
+	duration := staged.probe.duration()
+	if title == "" || duration <= 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var best *DuplicateMatch
+	for _, f := range s.files {
+		if f.Duration <= 0 || math.Abs(f.Duration-duration) > duplicateDurationTolerance {
+			continue
+		}
+		score := tagSimilarity(title, f.Metadata.Title)
+		if artist != "" && f.Metadata.Artist != "" {
+			score = (score*2 + tagSimilarity(artist, f.Metadata.Artist)) / 3
+		}
+		if score >= duplicateMinScore && (best == nil || score > best.Score) {
+			best = &DuplicateMatch{Existing: f, Reason: "similar", Score: score}
+		}
+	}
+	return best
+}
+
+// tagSimilarity 以字詞 Jaccard 係數比較兩個標籤（忽略大小寫與標點）
+func tagSimilarity(a, b string) float64 {
+	ta, tb := tagTokens(a), tagTokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	inter := 0
+	for t := range ta {
+		if tb[t] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(ta)+len(tb)-inter)
+}
+
+// tagTokens 將標籤拆成字詞集合，中日韓文字每個字視為一個詞
+func tagTokens(s string) map[string]bool {
+	tokens := make(map[string]bool)
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			tokens[string(word)] = true
+			word = word[:0]
+		}
+	}
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			tokens[string(r)] = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// replace 以新上傳的音檔取代既有檔案
+// 保留 ID、標籤、上傳時間與語言設定，舊的歌詞、段落與導出檔案會被清除
+// 取代期間檔案標記為忙碌，不能開始處理；新音檔先移進檔案目錄，移動失敗時舊內容完整保留
+func (s *FileService) replace(id, filename string, staged *stagedUpload) (*models.MusicFile, error) {
+	release, err := s.acquire(id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	s.mu.RLock()
+	updated := *s.files[id]
+	s.mu.RUnlock()
+
+	fileDir := filepath.Join(s.dataDir, id)
+	incoming := *staged
+	incoming.path = filepath.Join(fileDir, "incoming"+staged.ext)
+	if err := moveFile(staged.path, incoming.path); err != nil {
+		os.Remove(incoming.path)
+		return nil, err
+	}
+
+	// 先讓其他服務停止使用舊內容（取消導出、清除卡片與詞彙表），再清除舊的處理結果
+	s.notifyReset(id)
+	entries, err := os.ReadDir(fileDir)
+	if err != nil {
+		os.Remove(incoming.path)
+		return nil, err
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); name != "meta.json" && name != filepath.Base(incoming.path) {
+			os.RemoveAll(filepath.Join(fileDir, name))
+		}
+	}
+
+	// 新歌詞的行數可能不同，起點重新設定
+	updated.Settings.StartLineIndex = models.DefaultSettings().StartLineIndex
+	updated.Metadata = models.SongMetadata{}
+	s.index.updateLyrics(id, &models.LyricsData{})
+	storeErr := s.store(&updated, filename, &incoming)
+	if storeErr != nil {
+		// 舊內容已清除，紀錄標記為錯誤而不是指向已刪除的原檔，重新上傳即可再次取代
+		os.Remove(incoming.path)
+		updated.Status = models.StatusError
+		updated.LyricCount = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saveFileMeta(&updated)
+	s.files[id] = &updated
+	s.index.updateInfo(&updated)
+
+	if storeErr != nil {
+		return nil, storeErr
+	}
+	return &updated, nil
+}
+
+// OnReset 註冊檔案內容被取代時的回呼，用來清除其他服務中屬於舊內容的狀態
+// 回呼在刪除舊的處理結果之前執行，可以在其中等待仍在使用舊檔案的背景工作結束
+func (s *FileService) OnReset(fn func(fileID string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resetHooks = append(s.resetHooks, fn)
+}
+
+// notifyReset 依註冊順序呼叫檔案重設的回呼
+func (s *FileService) notifyReset(id string) {
+	s.mu.RLock()
+	hooks := append([]func(string){}, s.resetHooks...)
+	s.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(id)
+	}
+}
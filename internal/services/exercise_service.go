@@ -0,0 +1,354 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"multilang-learner/internal/exercise"
+	"multilang-learner/internal/models"
+)
+
+// 練習題的預設值與上限
+const (
+	defaultExerciseCount = 10
+	defaultMatchCount    = 5
+	maxExerciseCount     = 50
+	maxClozeBlanks       = 5
+	maxStoredExercises   = 20 // 每個檔案保留最近的練習題數
+)
+
+var (
+	ErrInvalidExerciseType = errors.New("練習類型必須是 cloze、dictation 或 match")
+	ErrInvalidExercise     = errors.New("題數必須介於 1 到 50，挖空數必須介於 1 到 5")
+	ErrNoExerciseLines     = errors.New("沒有可以出題的歌詞")
+	ErrExerciseNotFound    = errors.New("練習題不存在")
+)
+
+// ExerciseResult 一組練習題的批改結果
+type ExerciseResult struct {
+	ExerciseID string               `json:"exerciseId"`
+	Correct    int                  `json:"correct"` // 完全答對的題數
+	Total      int                  `json:"total"`
+	Score      float64              `json:"score"` // 所有空格相似度的平均（0~1）
+	Items      []ExerciseItemResult `json:"items"`
+}
+
+// ExerciseItemResult 一道題目的批改結果
+type ExerciseItemResult struct {
+	ID      string            `json:"id"`
+	Correct bool              `json:"correct"`
+	Answers []string          `json:"answers"` // 正解原文
+	Blanks  []exercise.Result `json:"blanks"`
+}
+
+// ExerciseService 從歌詞產生練習題並批改
+// 練習題（含正解）存於 data/{file_id}/exercises.json，只保留最近 20 組
+type ExerciseService struct {
+	dataDir        string
+	fileService    *FileService
+	lyricService   *LyricService
+	processService *ProcessService
+	exercises      map[string][]*models.Exercise // fileID -> 練習題（依建立時間排序）
+	mu             sync.RWMutex
+}
+
+// NewExerciseService 建立練習題服務
+func NewExerciseService(dataDir string, fileService *FileService, lyricService *LyricService, processService *ProcessService) *ExerciseService {
+	s := &ExerciseService{
+		dataDir:        dataDir,
+		fileService:    fileService,
+		lyricService:   lyricService,
+		processService: processService,
+		exercises:      make(map[string][]*models.Exercise),
+	}
+	s.load()
+	fileService.OnReset(s.RemoveFile)
+	return s
+}
+
+// load 載入各檔案的練習題
+func (s *ExerciseService) load() {
+	entries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dataDir, entry.Name(), "exercises.json"))
+		if err != nil {
+			continue
+		}
+		var list []*models.Exercise
+		if json.Unmarshal(data, &list) != nil {
+			continue
+		}
+		s.exercises[entry.Name()] = list
+	}
+}
+
+// save 儲存檔案的練習題（呼叫者需持有寫鎖）
+func (s *ExerciseService) save(fileID string) error {
+	data, err := json.MarshalIndent(s.exercises[fileID], "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dataDir, fileID, "exercises.json"), data, 0644)
+}
+
+// normalizeExerciseOptions 檢查選項並填入預設值
+func normalizeExerciseOptions(opts *models.ExerciseOptions) error {
+	switch opts.Type {
+	case models.ExerciseCloze, models.ExerciseDictation:
+		if opts.Count == 0 {
+			opts.Count = defaultExerciseCount
+		}
+	case models.ExerciseMatch:
+		if opts.Count == 0 {
+			opts.Count = defaultMatchCount
+		}
+	default:
+		return ErrInvalidExerciseType
+	}
+	if opts.Type != models.ExerciseCloze {
+		opts.Blanks, opts.ContentWords = 0, false
+	} else if opts.Blanks == 0 {
+		opts.Blanks = 1
+	}
+	if opts.Count < 1 || opts.Count > maxExerciseCount || opts.Blanks < 0 || opts.Blanks > maxClozeBlanks {
+		return ErrInvalidExercise
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	return nil
+}
+
+// Create 依選項從歌詞產生一組練習題（回傳的題目不含正解）
+func (s *ExerciseService) Create(fileID string, opts models.ExerciseOptions) (*models.Exercise, error) {
+	if err := normalizeExerciseOptions(&opts); err != nil {
+		return nil, err
+	}
+	file, err := s.fileService.GetFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	lyrics, err := s.lyricService.GetLyricsData(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	ex := &models.Exercise{
+		ID:        generateID(),
+		FileID:    fileID,
+		Type:      opts.Type,
+		Options:   opts,
+		CreatedAt: time.Now(),
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	lang := file.Settings.PrimaryLanguage
+	switch opts.Type {
+	case models.ExerciseCloze:
+		ex.Items = clozeItems(lyrics.GetActiveLyrics(), opts, lang, rng)
+	case models.ExerciseDictation:
+		segments, err := s.processService.GetSegmentsData(fileID)
+		if err != nil {
+			return nil, err
+		}
+		ex.Items = dictationItems(fileID, segments.Segments, opts, rng)
+	case models.ExerciseMatch:
+		ex.Items, ex.Choices = matchItems(lyrics.GetActiveLyrics(), opts, lang, rng)
+	}
+	if len(ex.Items) == 0 {
+		return nil, ErrNoExerciseLines
+	}
+	for i := range ex.Items {
+		ex.Items[i].ID = strconv.Itoa(i + 1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := append(s.exercises[fileID], ex)
+	if len(list) > maxStoredExercises {
+		list = list[len(list)-maxStoredExercises:]
+	}
+	s.exercises[fileID] = list
+	if err := s.save(fileID); err != nil {
+		return nil, err
+	}
+	return ex.WithoutAnswers(), nil
+}
+
+// clozeItems 隨機挑選歌詞行挖空，依歌詞順序排列
+func clozeItems(lines []models.LyricLine, opts models.ExerciseOptions, lang string, rng *rand.Rand) []models.ExerciseItem {
+	var items []models.ExerciseItem
+	for _, i := range rng.Perm(len(lines)) {
+		if len(items) == opts.Count {
+			break
+		}
+		line := lines[i]
+		prompt, answers, ok := exercise.Cloze(line.Original, opts.Blanks, opts.ContentWords, rng)
+		if !ok {
+			continue
+		}
+		items = append(items, models.ExerciseItem{
+			LineIndex: line.Index,
+			Prompt:    prompt,
+			Hint:      lineTranslation(line, lang),
+			Blanks:    len(answers),
+			Answers:   answers,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].LineIndex < items[j].LineIndex })
+	return items
+}
+
+// dictationItems 隨機挑選有音訊的段落，聽完寫出整句
+func dictationItems(fileID string, segments []models.Segment, opts models.ExerciseOptions, rng *rand.Rand) []models.ExerciseItem {
+	var items []models.ExerciseItem
+	for _, i := range rng.Perm(len(segments)) {
+		if len(items) == opts.Count {
+			break
+		}
+		seg := segments[i]
+		if !seg.IsMeaningful || seg.AudioPath == "" || seg.OriginalText == "" {
+			continue
+		}
+		item := models.ExerciseItem{
+			SegmentIndex: &seg.Index,
+			Hint:         seg.TTSText,
+			AudioURL:     fmt.Sprintf(segmentAudioURL, fileID, seg.Index),
+			Blanks:       1,
+			Answers:      []string{seg.OriginalText},
+		}
+		if len(seg.LineIndices) > 0 {
+			item.LineIndex = seg.LineIndices[0]
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return *items[i].SegmentIndex < *items[j].SegmentIndex })
+	return items
+}
+
+// matchItems 隨機挑選有翻譯的歌詞行，翻譯打亂後作為選項
+// 原文或翻譯重複的行只取一次，避免有兩個正解
+func matchItems(lines []models.LyricLine, opts models.ExerciseOptions, lang string, rng *rand.Rand) ([]models.ExerciseItem, []string) {
+	var items []models.ExerciseItem
+	seen := make(map[string]bool)
+	for _, i := range rng.Perm(len(lines)) {
+		if len(items) == opts.Count {
+			break
+		}
+		line := lines[i]
+		translation := lineTranslation(line, lang)
+		original, normalized := exercise.Normalize(line.Original), exercise.Normalize(translation)
+		if original == "" || normalized == "" || seen["o:"+original] || seen["t:"+normalized] {
+			continue
+		}
+		seen["o:"+original], seen["t:"+normalized] = true, true
+		items = append(items, models.ExerciseItem{
+			LineIndex: line.Index,
+			Prompt:    line.Original,
+			Blanks:    1,
+			Answers:   []string{translation},
+		})
+	}
+	if len(items) < 2 {
+		return nil, nil
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].LineIndex < items[j].LineIndex })
+
+	choices := make([]string, len(items))
+	for i, p := range rng.Perm(len(items)) {
+		choices[i] = items[p].Answers[0]
+	}
+	return items, choices
+}
+
+// lineTranslation 歌詞行的翻譯，優先使用主要語言
+func lineTranslation(line models.LyricLine, lang string) string {
+	if dt := line.GetDisplayText(lang, false); dt.Primary != "" {
+		return dt.Primary
+	}
+	for _, t := range []string{line.Translations.En, line.Translations.Zh, line.Translations.Embedded} {
+		if t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// find 找出練習題（呼叫者需持有鎖）
+func (s *ExerciseService) find(fileID, exerciseID string) *models.Exercise {
+	for _, ex := range s.exercises[fileID] {
+		if ex.ID == exerciseID {
+			return ex
+		}
+	}
+	return nil
+}
+
+// Get 獲取練習題（不含正解）
+func (s *ExerciseService) Get(fileID, exerciseID string) (*models.Exercise, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ex := s.find(fileID, exerciseID)
+	if ex == nil {
+		return nil, ErrExerciseNotFound
+	}
+	return ex.WithoutAnswers(), nil
+}
+
+// Grade 批改答案，answers 的鍵為題目 ID，值依序為每個空格的答案（沒作答的空格視為空白）
+func (s *ExerciseService) Grade(fileID, exerciseID string, answers map[string][]string) (*ExerciseResult, error) {
+	s.mu.RLock()
+	ex := s.find(fileID, exerciseID)
+	s.mu.RUnlock()
+	if ex == nil {
+		return nil, ErrExerciseNotFound
+	}
+
+	result := &ExerciseResult{ExerciseID: ex.ID, Total: len(ex.Items), Items: []ExerciseItemResult{}}
+	var scoreSum float64
+	var blanks int
+	for _, item := range ex.Items {
+		given := answers[item.ID]
+		itemResult := ExerciseItemResult{ID: item.ID, Correct: true, Answers: item.Answers}
+		for i, expected := range item.Answers {
+			var answer string
+			if i < len(given) {
+				answer = given[i]
+			}
+			graded := exercise.Grade(expected, answer)
+			itemResult.Correct = itemResult.Correct && graded.Correct
+			itemResult.Blanks = append(itemResult.Blanks, graded)
+			scoreSum += graded.Score
+			blanks++
+		}
+		if itemResult.Correct {
+			result.Correct++
+		}
+		result.Items = append(result.Items, itemResult)
+	}
+	if blanks > 0 {
+		result.Score = math.Round(scoreSum/float64(blanks)*100) / 100
+	}
+	return result, nil
+}
+
+// RemoveFile 移除檔案的練習題（刪除或取代檔案時呼叫）
+func (s *ExerciseService) RemoveFile(fileID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.exercises, fileID)
+}
@@ -0,0 +1,993 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"multilang-learner/internal/audio"
+	"multilang-learner/internal/audio/id3"
+	"multilang-learner/internal/models"
+	"multilang-learner/internal/subtitle"
+)
+
+// exportCodec 各導出格式的編碼設定
+type exportCodec struct {
+	codec          string
+	muxer          string
+	defaultBitrate int    // kbps，0 代表無損格式
+	sampleRate     int    // 格式限定的取樣率（Opus 只支援 48 kHz）
+	lyricsKey      string // 歌詞標籤名稱，空白代表不透過 ffmpeg 寫入（MP3 另外寫 USLT/SYLT 框架）
+	video          bool
+}
+
+var exportCodecs = map[models.ExportFormat]exportCodec{
+	models.ExportMP3:  {codec: "libmp3lame", muxer: "mp3", defaultBitrate: 192},
+	models.ExportM4A:  {codec: "aac", muxer: "ipod", defaultBitrate: 128, lyricsKey: "lyrics"},
+	models.ExportM4B:  {codec: "aac", muxer: "ipod", defaultBitrate: 128, lyricsKey: "lyrics"},
+	models.ExportOpus: {codec: "libopus", muxer: "ogg", defaultBitrate: 96, sampleRate: 48000, lyricsKey: "LYRICS"},
+	models.ExportWAV:  {codec: "pcm_s16le", muxer: "wav"},
+	models.ExportFLAC: {codec: "flac", muxer: "flac", lyricsKey: "LYRICS"},
+	models.ExportMP4:  {codec: "aac", muxer: "mp4", defaultBitrate: 128, lyricsKey: "lyrics", video: true},
+}
+
+// 學習影片的解析度與背景色
+const (
+	videoWidth      = 1280
+	videoHeight     = 720
+	videoBackground = "0x1e1e2e"
+)
+
+// 導出選項預設值與上限
+const (
+	defaultExportSampleRate = 44100
+	defaultExportChannels   = 2
+	minExportBitrate        = 32
+	maxExportBitrate        = 320
+	maxExportRepeat         = 10
+	maxExportGap            = 30 * time.Second
+	maxExportCrossfade      = 2 * time.Second
+	maxConcurrentExports    = 2
+)
+
+// ParseExportFormat 解析導出格式，空字串視為 MP3，aac 視為 m4a
+func ParseExportFormat(s string) (models.ExportFormat, error) {
+	f := models.ExportFormat(strings.ToLower(strings.TrimSpace(s)))
+	switch f {
+	case "":
+		return models.ExportMP3, nil
+	case "aac":
+		return models.ExportM4A, nil
+	}
+	if _, ok := exportCodecs[f]; !ok {
+		return "", fmt.Errorf("不支援的導出格式: %s", s)
+	}
+	return f, nil
+}
+
+// NormalizeExportOptions 檢查導出選項並補上預設值
+func NormalizeExportOptions(opts *models.ExportOptions) error {
+	format, err := ParseExportFormat(string(opts.Format))
+	if err != nil {
+		return err
+	}
+	opts.Format = format
+	codec := exportCodecs[format]
+
+	if codec.defaultBitrate == 0 {
+		opts.Bitrate = 0
+	} else if opts.Bitrate == 0 {
+		opts.Bitrate = codec.defaultBitrate
+	} else if opts.Bitrate < minExportBitrate || opts.Bitrate > maxExportBitrate {
+		return fmt.Errorf("位元率必須介於 %d 到 %d kbps", minExportBitrate, maxExportBitrate)
+	}
+
+	switch {
+	case codec.sampleRate != 0:
+		opts.SampleRate = codec.sampleRate
+	case opts.SampleRate == 0:
+		opts.SampleRate = defaultExportSampleRate
+	case opts.SampleRate != 22050 && opts.SampleRate != 44100 && opts.SampleRate != 48000:
+		return errors.New("取樣率必須是 22050、44100 或 48000")
+	}
+
+	if opts.Channels == 0 {
+		opts.Channels = defaultExportChannels
+	} else if opts.Channels != 1 && opts.Channels != 2 {
+		return errors.New("聲道數必須是 1 或 2")
+	}
+
+	if opts.Repeat < 0 || opts.Repeat > maxExportRepeat {
+		return fmt.Errorf("重複次數必須介於 1 到 %d", maxExportRepeat)
+	}
+	if opts.Repeat == 0 {
+		opts.Repeat = 1
+	}
+	if opts.Gap != "" {
+		gap, err := time.ParseDuration(opts.Gap)
+		if err != nil {
+			return fmt.Errorf("無效的段落間隔: %s", opts.Gap)
+		}
+		if gap < 0 || gap > maxExportGap {
+			return fmt.Errorf("段落間隔必須介於 0 到 %s", maxExportGap)
+		}
+	}
+	if opts.Crossfade != "" {
+		crossfade, err := time.ParseDuration(opts.Crossfade)
+		if err != nil {
+			return fmt.Errorf("無效的交叉淡化長度: %s", opts.Crossfade)
+		}
+		if crossfade < 0 || crossfade > maxExportCrossfade {
+			return fmt.Errorf("交叉淡化長度必須介於 0 到 %s", maxExportCrossfade)
+		}
+	}
+	if opts.Pattern != "" {
+		if _, err := audio.ParsePattern(opts.Pattern); err != nil {
+			return fmt.Errorf("無效的播放模式: %w", err)
+		}
+	}
+	switch {
+	case !codec.video && opts.Subtitles != "":
+		return errors.New("字幕模式只適用於影片導出")
+	case codec.video && opts.Subtitles == "":
+		opts.Subtitles = models.SubtitlesBurn
+	case codec.video && opts.Subtitles != models.SubtitlesBurn && opts.Subtitles != models.SubtitlesTrack:
+		return errors.New("字幕模式必須是 burn 或 track")
+	}
+	return nil
+}
+
+// ExportService 導出服務
+// 導出以背景工作執行，完成的檔案保留在 data/{file_id}/exports/，紀錄存於 exports.json
+type ExportService struct {
+	dataDir        string
+	fileService    *FileService
+	processService *ProcessService
+	jobs           map[string][]*models.ExportJob // fileID -> 導出紀錄（依建立時間排序）
+	cancels        map[string]context.CancelFunc  // jobID -> 取消執行中的工作
+	done           map[string]chan struct{}       // jobID -> 工作結束時關閉
+	sem            chan struct{}
+	mu             sync.RWMutex
+}
+
+// NewExportService 建立導出服務
+func NewExportService(dataDir string, fileService *FileService, processService *ProcessService) *ExportService {
+	s := &ExportService{
+		dataDir:        dataDir,
+		fileService:    fileService,
+		processService: processService,
+		jobs:           make(map[string][]*models.ExportJob),
+		cancels:        make(map[string]context.CancelFunc),
+		done:           make(map[string]chan struct{}),
+		sem:            make(chan struct{}, maxConcurrentExports),
+	}
+	s.load()
+	fileService.OnReset(s.RemoveFile)
+	return s
+}
+
+// load 載入各檔案的導出紀錄，上次未完成的工作標記為失敗
+func (s *ExportService) load() {
+	entries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dataDir, entry.Name(), "exports.json"))
+		if err != nil {
+			continue
+		}
+		var jobs []*models.ExportJob
+		if json.Unmarshal(data, &jobs) != nil {
+			continue
+		}
+		for _, job := range jobs {
+			if job.Status == models.ExportQueued || job.Status == models.ExportRunning {
+				job.Status = models.ExportFailed
+				job.Message = "服務重新啟動，導出中斷"
+			}
+		}
+		s.jobs[entry.Name()] = jobs
+	}
+}
+
+// save 儲存檔案的導出紀錄（呼叫者需持有鎖）
+func (s *ExportService) save(fileID string) error {
+	data, err := json.MarshalIndent(s.jobs[fileID], "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dataDir, fileID, "exports.json"), data, 0644)
+}
+
+// StartExport 建立導出工作並在背景執行
+func (s *ExportService) StartExport(fileID string, opts models.ExportOptions) (*models.ExportJob, error) {
+	job, err := s.newJob(fileID, opts)
+	if err != nil {
+		return nil, err
+	}
+	go s.run(job)
+	return s.snapshot(job), nil
+}
+
+// ExportSync 建立導出工作並等待完成（集合導出使用）
+func (s *ExportService) ExportSync(fileID string, opts models.ExportOptions) (*models.ExportJob, error) {
+	job, err := s.newJob(fileID, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.run(job)
+	job = s.snapshot(job)
+	if job.Status != models.ExportDone {
+		return job, errors.New(job.Message)
+	}
+	return job, nil
+}
+
+// Export 以預設選項（MP3）導出並回傳檔案路徑
+func (s *ExportService) Export(fileID string) (string, error) {
+	job, err := s.ExportSync(fileID, models.ExportOptions{})
+	if err != nil {
+		return "", err
+	}
+	return job.Path, nil
+}
+
+// newJob 檢查選項與段落資料後建立工作紀錄
+func (s *ExportService) newJob(fileID string, opts models.ExportOptions) (*models.ExportJob, error) {
+	if err := NormalizeExportOptions(&opts); err != nil {
+		return nil, err
+	}
+	if _, err := s.fileService.GetFile(fileID); err != nil {
+		return nil, err
+	}
+	if _, err := s.processService.GetSegmentsData(fileID); err != nil {
+		return nil, err
+	}
+
+	job := &models.ExportJob{
+		ID:        generateID(),
+		FileID:    fileID,
+		Options:   opts,
+		Status:    models.ExportQueued,
+		Message:   "等待中",
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[fileID] = append(s.jobs[fileID], job)
+	s.save(fileID)
+	return job, nil
+}
+
+// update 更新工作狀態
+func (s *ExportService) update(job *models.ExportJob, fn func(job *models.ExportJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(job)
+	// 檔案已刪除或被取代時紀錄已清除，不再寫回 exports.json
+	if (job.Status == models.ExportDone || job.Status == models.ExportFailed) && s.tracked(job) {
+		s.save(job.FileID)
+	}
+}
+
+// snapshot 複製工作狀態，避免呼叫者讀到背景執行中的修改
+func (s *ExportService) snapshot(job *models.ExportJob) *models.ExportJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	copied := *job
+	return &copied
+}
+
+// run 執行導出工作，檔案被刪除時會取消
+func (s *ExportService) run(job *models.ExportJob) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.cancels[job.ID] = cancel
+	s.done[job.ID] = done
+	s.mu.Unlock()
+	defer func() {
+		cancel()
+		s.mu.Lock()
+		delete(s.cancels, job.ID)
+		delete(s.done, job.ID)
+		s.mu.Unlock()
+		close(done)
+	}()
+
+	select {
+	case s.sem <- struct{}{}:
+		defer func() { <-s.sem }()
+	case <-ctx.Done():
+	}
+
+	s.update(job, func(job *models.ExportJob) {
+		job.Status = models.ExportRunning
+		job.Message = "計算時間軸"
+	})
+
+	err := ctx.Err()
+	if err == nil {
+		err = s.export(ctx, job)
+	}
+	if errors.Is(err, context.Canceled) {
+		err = errors.New("導出已取消")
+	}
+
+	s.update(job, func(job *models.ExportJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		if err != nil {
+			job.Status = models.ExportFailed
+			job.Message = err.Error()
+			return
+		}
+		job.Status = models.ExportDone
+		job.Progress = 100
+		job.Message = "完成"
+	})
+}
+
+// export 合併音檔並依選項重新編碼
+// 同時依合併後的時間軸產生 LRC（原曲段落顯示原文、TTS 顯示翻譯），並為每個段落建立一個章節
+func (s *ExportService) export(ctx context.Context, job *models.ExportJob) error {
+	file, err := s.fileService.GetFile(job.FileID)
+	if err != nil {
+		return err
+	}
+	segments, err := s.processService.GetSegmentsData(job.FileID)
+	if err != nil {
+		return err
+	}
+
+	exportDir := filepath.Join(s.dataDir, job.FileID, "exports")
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return err
+	}
+
+	plan, err := s.buildExportPlan(file, segments, job.Options, exportDir, func(progress float64) {
+		// 計算時間軸佔 10%
+		s.update(job, func(job *models.ExportJob) { job.Progress = progress * 10 })
+	})
+	if err != nil {
+		return err
+	}
+
+	// 有交叉淡化時先混成一個中間檔，再交給後面的編碼流程
+	clips := plan.Clips
+	encodeStart := 10.0
+	if plan.hasOverlap() {
+		s.update(job, func(job *models.ExportJob) { job.Message = "交叉淡化" })
+		mixPath, err := s.mixCrossfade(ctx, job, plan, exportDir)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(mixPath)
+		clips = []string{mixPath}
+		encodeStart = 50
+	}
+
+	// 建立合併列表
+	listPath := filepath.Join(exportDir, job.ID+"_concat.txt")
+	var listContent strings.Builder
+	for _, clip := range clips {
+		listContent.WriteString(fmt.Sprintf("file '%s'\n", clip))
+	}
+	if err := os.WriteFile(listPath, []byte(listContent.String()), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(listPath)
+
+	// 側載 LRC
+	lrc := subtitle.NewParser().GenerateEnhancedLRC(plan.Lyrics)
+	lrcPath := filepath.Join(exportDir, job.ID+".lrc")
+	if err := os.WriteFile(lrcPath, []byte(lrc), 0644); err != nil {
+		return err
+	}
+
+	// 標籤與章節透過 ffmetadata 檔傳給 ffmpeg
+	codec := exportCodecs[job.Options.Format]
+	metaPath := filepath.Join(exportDir, job.ID+"_metadata.txt")
+	if err := os.WriteFile(metaPath, []byte(plan.ffmetadata(lrc, codec.lyricsKey)), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(metaPath)
+
+	// 影片導出另外產生 ASS 字幕
+	var assPath string
+	if codec.video {
+		assPath = filepath.Join(exportDir, job.ID+".ass")
+		ass := subtitle.GenerateASS(plan.Lyrics.Title, plan.Subtitles, videoWidth, videoHeight)
+		if err := os.WriteFile(assPath, []byte(ass), 0644); err != nil {
+			return err
+		}
+	}
+
+	exportPath := filepath.Join(exportDir, job.ID+"."+string(job.Options.Format))
+	var args []string
+	if codec.video {
+		args = videoExportArgs(job.Options, listPath, metaPath, assPath, file.Metadata.CoverPath)
+	} else {
+		args = audioExportArgs(job.Options, listPath, metaPath)
+	}
+	args = append(args, "-f", codec.muxer, exportPath)
+
+	s.update(job, func(job *models.ExportJob) { job.Message = "編碼中" })
+	total := plan.cursor
+	if err := audio.NewRunner(false).FFmpegProgress(ctx, args, func(done time.Duration) {
+		if total <= 0 {
+			return
+		}
+		progress := encodeStart + (100-encodeStart)*float64(done)/float64(total)
+		if progress > 99 {
+			progress = 99
+		}
+		s.update(job, func(job *models.ExportJob) { job.Progress = progress })
+	}); err != nil {
+		os.Remove(exportPath)
+		return err
+	}
+
+	// ffmpeg 只會把自訂標籤寫成 TXXX，MP3 的歌詞框架在編碼後另外寫入
+	if job.Options.Format == models.ExportMP3 {
+		if err := id3.AddLyrics(exportPath, plan.id3Lyrics(file.Metadata.Language)); err != nil {
+			os.Remove(exportPath)
+			return fmt.Errorf("寫入歌詞標籤失敗: %w", err)
+		}
+	}
+
+	info, err := os.Stat(exportPath)
+	if err != nil {
+		return err
+	}
+	s.update(job, func(job *models.ExportJob) {
+		job.Path = exportPath
+		job.LyricsPath = lrcPath
+		job.SubtitlePath = assPath
+		job.Size = info.Size()
+		job.Duration = total.Seconds()
+	})
+	return nil
+}
+
+// mixCrossfade 依計畫的重疊長度以 acrossfade 串接所有片段，輸出 FLAC 中間檔
+// 片段可能上百個，濾鏡圖寫入檔案再以 -filter_complex_script 讀取，避免命令列過長
+func (s *ExportService) mixCrossfade(ctx context.Context, job *models.ExportJob, plan *exportPlan, exportDir string) (string, error) {
+	scriptPath := filepath.Join(exportDir, job.ID+"_crossfade.txt")
+	graph := plan.crossfadeGraph(job.Options.SampleRate, job.Options.Channels)
+	if err := os.WriteFile(scriptPath, []byte(graph), 0644); err != nil {
+		return "", err
+	}
+	defer os.Remove(scriptPath)
+
+	mixPath := filepath.Join(exportDir, job.ID+"_mix.flac")
+	args := []string{
+		"-y",
+		"-filter_complex_script", scriptPath,
+		"-map", "[out]",
+		"-c:a", "flac",
+		mixPath,
+	}
+	total := plan.cursor
+	if err := audio.NewRunner(false).FFmpegProgress(ctx, args, func(done time.Duration) {
+		if total <= 0 {
+			return
+		}
+		progress := 10 + 40*float64(done)/float64(total)
+		if progress > 50 {
+			progress = 50
+		}
+		s.update(job, func(job *models.ExportJob) { job.Progress = progress })
+	}); err != nil {
+		os.Remove(mixPath)
+		return "", err
+	}
+	return mixPath, nil
+}
+
+// audioExportArgs 音訊導出的 ffmpeg 參數
+// 段落來自原曲、TTS 與靜音，取樣率不一定相同，統一重新取樣與編碼
+func audioExportArgs(opts models.ExportOptions, listPath, metaPath string) []string {
+	codec := exportCodecs[opts.Format]
+	args := []string{
+		"-y",
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listPath,
+		"-i", metaPath,
+		"-map", "0:a",
+		"-map_metadata", "1",
+		"-map_chapters", "1",
+	}
+	args = append(args, audioEncodeArgs(opts, codec)...)
+	if opts.Format == models.ExportMP3 {
+		// 章節寫成 ID3v2 CHAP/CTOC，歌詞框架在編碼後另外寫入
+		args = append(args, "-id3v2_version", "3")
+	}
+	return args
+}
+
+// videoExportArgs 學習影片的 ffmpeg 參數
+// 畫面為封面（等比縮放置中）或純色背景，字幕依 opts.Subtitles 燒進畫面或加入字幕軌
+func videoExportArgs(opts models.ExportOptions, listPath, metaPath, assPath, coverPath string) []string {
+	args := []string{
+		"-y",
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listPath,
+	}
+	if coverPath != "" {
+		args = append(args, "-loop", "1", "-framerate", "1", "-i", coverPath)
+	} else {
+		args = append(args, "-f", "lavfi", "-i", fmt.Sprintf("color=c=%s:s=%dx%d:r=1", videoBackground, videoWidth, videoHeight))
+	}
+	args = append(args, "-i", metaPath)
+
+	filter := fmt.Sprintf("[1:v]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:color=%s,fps=25,format=yuv420p",
+		videoWidth, videoHeight, videoWidth, videoHeight, videoBackground)
+	if opts.Subtitles == models.SubtitlesTrack {
+		args = append(args, "-i", assPath)
+	} else {
+		filter += ",subtitles=" + escapeFilterPath(assPath)
+	}
+	filter += "[v]"
+
+	args = append(args,
+		"-filter_complex", filter,
+		"-map", "0:a",
+		"-map", "[v]",
+	)
+	if opts.Subtitles == models.SubtitlesTrack {
+		args = append(args, "-map", "3:s", "-c:s", "mov_text")
+	}
+	args = append(args,
+		"-map_metadata", "2",
+		"-map_chapters", "2",
+		"-c:v", "libx264",
+		"-preset", "veryfast",
+		"-tune", "stillimage",
+		"-shortest",
+		"-movflags", "+faststart",
+	)
+	return append(args, audioEncodeArgs(opts, exportCodecs[opts.Format])...)
+}
+
+// audioEncodeArgs 音訊編碼參數
+func audioEncodeArgs(opts models.ExportOptions, codec exportCodec) []string {
+	args := []string{
+		"-af", "aresample=" + strconv.Itoa(opts.SampleRate),
+		"-ar", strconv.Itoa(opts.SampleRate),
+		"-ac", strconv.Itoa(opts.Channels),
+		"-c:a", codec.codec,
+	}
+	if opts.Bitrate > 0 {
+		args = append(args, "-b:a", strconv.Itoa(opts.Bitrate)+"k")
+	}
+	return args
+}
+
+// escapeFilterPath 跳脫 ffmpeg filter 參數中的路徑
+func escapeFilterPath(path string) string {
+	path = filepath.ToSlash(path)
+	r := strings.NewReplacer(`\`, `\\`, `:`, `\:`, `'`, `\'`, `,`, `\,`, `[`, `\[`, `]`, `\]`)
+	return r.Replace(path)
+}
+
+// GetJob 獲取導出工作
+func (s *ExportService) GetJob(fileID, jobID string) (*models.ExportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, job := range s.jobs[fileID] {
+		if job.ID == jobID {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("導出紀錄不存在")
+}
+
+// ListExports 列出檔案的導出紀錄（新的在前）
+func (s *ExportService) ListExports(fileID string) []*models.ExportJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]*models.ExportJob, 0, len(s.jobs[fileID]))
+	for _, job := range s.jobs[fileID] {
+		copied := *job
+		list = append(list, &copied)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// DeleteExport 刪除導出紀錄與檔案（執行中的工作不可刪除）
+func (s *ExportService) DeleteExport(fileID, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := s.jobs[fileID]
+	for i, job := range jobs {
+		if job.ID != jobID {
+			continue
+		}
+		if job.Status == models.ExportQueued || job.Status == models.ExportRunning {
+			return errors.New("導出進行中，無法刪除")
+		}
+		if job.Path != "" {
+			os.Remove(job.Path)
+		}
+		if job.LyricsPath != "" {
+			os.Remove(job.LyricsPath)
+		}
+		if job.SubtitlePath != "" {
+			os.Remove(job.SubtitlePath)
+		}
+		s.jobs[fileID] = append(jobs[:i], jobs[i+1:]...)
+		return s.save(fileID)
+	}
+	return errors.New("導出紀錄不存在")
+}
+
+// Latest 獲取指定格式最近一次完成的導出
+func (s *ExportService) Latest(fileID string, format models.ExportFormat) (*models.ExportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jobs := s.jobs[fileID]
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if jobs[i].Status == models.ExportDone && (format == "" || jobs[i].Options.Format == format) {
+			copied := *jobs[i]
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("導出檔案不存在，請先導出")
+}
+
+// RemoveFile 取消檔案執行中的導出並移除紀錄（刪除或取代檔案時呼叫）
+func (s *ExportService) RemoveFile(fileID string) {
+	s.mu.Lock()
+	var running []chan struct{}
+	for _, job := range s.jobs[fileID] {
+		if cancel, ok := s.cancels[job.ID]; ok {
+			cancel()
+			running = append(running, s.done[job.ID])
+		}
+	}
+	delete(s.jobs, fileID)
+	s.mu.Unlock()
+
+	// 等待取消的工作結束，避免刪除或取代檔案後仍寫入導出目錄
+	for _, done := range running {
+		<-done
+	}
+}
+
+// tracked 工作是否仍在導出紀錄中（呼叫者需持有鎖）
+func (s *ExportService) tracked(job *models.ExportJob) bool {
+	for _, j := range s.jobs[job.FileID] {
+		if j == job {
+			return true
+		}
+	}
+	return false
+}
+
+// exportChapter 一個段落在合併音檔中的範圍
+type exportChapter struct {
+	Start time.Duration
+	End   time.Duration
+	Title string
+}
+
+// exportPlan 合併音檔的片段清單與時間軸
+type exportPlan struct {
+	Clips     []string
+	Overlaps  []time.Duration // 每個片段與前一個片段交叉淡化的長度
+	Lyrics    *subtitle.Lyrics
+	Subtitles []subtitle.Line // 影片字幕：每個片段同時顯示原文與翻譯
+	Chapters  []exportChapter
+	crossfade time.Duration
+	lastClip  time.Duration
+	cursor    time.Duration
+}
+
+// add 加入一個片段，text 不為空時產生對應的歌詞行
+// 有設定交叉淡化時片段會提前開始，與前一個片段重疊（最多各自長度的一半）
+func (p *exportPlan) add(path, text string, d time.Duration) {
+	var overlap time.Duration
+	if p.crossfade > 0 && len(p.Clips) > 0 {
+		overlap = min(p.crossfade, p.lastClip/2, d/2)
+	}
+	p.cursor -= overlap
+	p.Clips = append(p.Clips, path)
+	p.Overlaps = append(p.Overlaps, overlap)
+	p.lastClip = d
+	if text != "" {
+		p.Lyrics.Lines = append(p.Lyrics.Lines, subtitle.Line{StartTime: p.cursor, EndTime: p.cursor + d, Text: text})
+	}
+	p.cursor += d
+}
+
+// addSubtitle 為最後加入的片段建立影片字幕
+func (p *exportPlan) addSubtitle(d time.Duration, original, translation string) {
+	if original == "" && translation == "" {
+		return
+	}
+	p.Subtitles = append(p.Subtitles, subtitle.Line{StartTime: p.cursor - d, EndTime: p.cursor, Text: original, Translation: translation})
+}
+
+// hasOverlap 是否有任何片段需要交叉淡化
+func (p *exportPlan) hasOverlap() bool {
+	for _, o := range p.Overlaps {
+		if o > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// crossfadeGraph 產生串接所有片段的濾鏡圖
+// 每個片段先統一取樣率與聲道，有重疊的接點用 acrossfade，其餘用 concat
+func (p *exportPlan) crossfadeGraph(sampleRate, channels int) string {
+	layout := "stereo"
+	if channels == 1 {
+		layout = "mono"
+	}
+	var sb strings.Builder
+	for i, clip := range p.Clips {
+		sb.WriteString(fmt.Sprintf("amovie=%s,aresample=%d,aformat=sample_fmts=fltp:channel_layouts=%s[c%d];\n",
+			escapeFilterPath(clip), sampleRate, layout, i))
+	}
+	prev := "c0"
+	for i := 1; i < len(p.Clips); i++ {
+		out := fmt.Sprintf("x%d", i)
+		if p.Overlaps[i] > 0 {
+			sb.WriteString(fmt.Sprintf("[%s][c%d]acrossfade=d=%.3f:c1=tri:c2=tri[%s];\n", prev, i, p.Overlaps[i].Seconds(), out))
+		} else {
+			sb.WriteString(fmt.Sprintf("[%s][c%d]concat=n=2:v=0:a=1[%s];\n", prev, i, out))
+		}
+		prev = out
+	}
+	sb.WriteString(fmt.Sprintf("[%s]anull[out]\n", prev))
+	return sb.String()
+}
+
+// resolvePattern 解析播放模式，未指定時為原曲 + TTS × 設定的重複次數
+func resolvePattern(opts models.ExportOptions, settings models.FileSettings) (audio.Pattern, error) {
+	if opts.Pattern != "" {
+		return audio.ParsePattern(opts.Pattern)
+	}
+	pattern := audio.Pattern{{Kind: audio.StepOriginal}}
+	for i := 0; i < settings.TTSRepeatCount; i++ {
+		pattern = append(pattern, audio.Step{Kind: audio.StepTTS})
+	}
+	return pattern, nil
+}
+
+// buildExportPlan 依播放模式排出每個段落的片段順序，
+// 並以每個片段的實際長度計算歌詞與章節時間
+func (s *ExportService) buildExportPlan(file *models.MusicFile, segments *models.SegmentsData, opts models.ExportOptions, exportDir string, onProgress func(float64)) (*exportPlan, error) {
+	pattern, err := resolvePattern(opts, file.Settings)
+	if err != nil {
+		return nil, err
+	}
+	var gap time.Duration
+	if opts.Gap != "" {
+		gap, _ = time.ParseDuration(opts.Gap)
+	}
+	var crossfade time.Duration
+	if opts.Crossfade != "" {
+		crossfade, _ = time.ParseDuration(opts.Crossfade)
+	}
+	repeat := opts.Repeat
+	if repeat == 0 {
+		repeat = 1
+	}
+
+	audioProcessor := audio.NewProcessor(false)
+	merger := audio.NewMerger(false)
+	plan := &exportPlan{
+		crossfade: crossfade,
+		Lyrics: &subtitle.Lyrics{
+			Title:  file.Metadata.Title,
+			Artist: file.Metadata.Artist,
+			Album:  file.Metadata.Album,
+			By:     "multilang-learner",
+		},
+	}
+	if plan.Lyrics.Title == "" {
+		plan.Lyrics.Title = strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename))
+	}
+
+	// 同一個片段可能重複多次，長度只讀一次
+	durations := make(map[string]time.Duration)
+	duration := func(path string, fallback time.Duration) time.Duration {
+		if d, ok := durations[path]; ok {
+			return d
+		}
+		d, err := audioProcessor.Duration(path)
+		if err != nil {
+			d = fallback
+		}
+		durations[path] = d
+		return d
+	}
+	addSilence := func(d time.Duration) error {
+		path, err := merger.Silence(exportDir, d)
+		if err != nil {
+			return err
+		}
+		plan.add(path, "", d)
+		return nil
+	}
+
+	for i, seg := range segments.Segments {
+		if i > 0 && gap > 0 {
+			if err := addSilence(gap); err != nil {
+				return nil, err
+			}
+		}
+
+		start := plan.cursor
+		// 依段落與檔案的速度設定改用變速版本，產生失敗時退回原檔
+		origSpeed := clipSpeed(file, &seg, ClipOriginal)
+		segDuration := time.Duration(seg.Duration / origSpeed * float64(time.Second))
+		audioPath, ttsPath := seg.AudioPath, seg.TTSPath
+		if audioPath != "" {
+			if variant, err := speedVariant(audioPath, origSpeed); err == nil {
+				audioPath = variant
+			}
+		}
+		if ttsPath != "" {
+			if variant, err := speedVariant(ttsPath, clipSpeed(file, &seg, ClipTTS)); err == nil {
+				ttsPath = variant
+			}
+		}
+		// 伴奏版本只在播放模式用到時才產生
+		karaokePath := ""
+		if seg.AudioPath != "" && pattern.Has(audio.StepKaraoke) {
+			if variant, err := karaokeVariant(seg.AudioPath); err == nil {
+				if karaokePath, err = speedVariant(variant, origSpeed); err != nil {
+					karaokePath = variant
+				}
+			}
+		}
+		for r := 0; r < repeat; r++ {
+			for _, st := range pattern {
+				switch st.Kind {
+				case audio.StepOriginal:
+					if audioPath != "" {
+						d := duration(audioPath, segDuration)
+						plan.add(audioPath, seg.OriginalText, d)
+						plan.addSubtitle(d, seg.OriginalText, seg.TTSText)
+					}
+				case audio.StepKaraoke:
+					// 跟唱時顯示原文
+					if karaokePath != "" {
+						d := duration(karaokePath, segDuration)
+						plan.add(karaokePath, seg.OriginalText, d)
+						plan.addSubtitle(d, seg.OriginalText, seg.TTSText)
+					}
+				case audio.StepTTS:
+					if ttsPath != "" {
+						d := duration(ttsPath, 0)
+						plan.add(ttsPath, seg.TTSText, d)
+						plan.addSubtitle(d, seg.OriginalText, seg.TTSText)
+					}
+				case audio.StepPause:
+					if err := addSilence(st.Pause); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		if plan.cursor > start {
+			title := seg.OriginalText
+			if title == "" {
+				title = fmt.Sprintf("Segment %d", seg.Index)
+			}
+			plan.Chapters = append(plan.Chapters, exportChapter{Start: start, End: plan.cursor, Title: title})
+		}
+		onProgress(float64(i+1) / float64(len(segments.Segments)))
+	}
+
+	if opts.FullSong {
+		if err := s.addFullSong(plan, file, exportDir); err != nil {
+			return nil, err
+		}
+	}
+	if len(plan.Clips) == 0 {
+		return nil, errors.New("沒有可導出的音訊片段")
+	}
+	return plan, nil
+}
+
+// addFullSong 在最後加入完整原曲，歌詞沿用原本的時間戳
+func (s *ExportService) addFullSong(plan *exportPlan, file *models.MusicFile, exportDir string) error {
+	// 原始檔可能是 FLAC 等格式，轉成 MP3 才能與其他片段一起串接
+	fullPath := filepath.Join(exportDir, "full_song.mp3")
+	if _, err := os.Stat(fullPath); err != nil {
+		cut := audio.Cut{End: secondsToDuration(file.Duration), Output: fullPath}
+		if err := audio.NewProcessor(false).CutSegments(context.Background(), file.Filepath, []audio.Cut{cut}); err != nil {
+			return fmt.Errorf("轉換完整原曲失敗: %w", err)
+		}
+	}
+	d, err := audio.NewProcessor(false).Duration(fullPath)
+	if err != nil {
+		d = time.Duration(file.Duration * float64(time.Second))
+	}
+
+	// 交叉淡化時原曲會提前開始，先加入片段再以實際起點計算歌詞
+	plan.add(fullPath, "", d)
+	start := plan.cursor - d
+	if lyrics, err := s.fileService.loadLyrics(file.ID); err == nil {
+		for i, line := range lyrics.Lines {
+			if i < file.Settings.StartLineIndex || !line.IsMeaningful {
+				continue
+			}
+			l := subtitle.Line{
+				StartTime: start + time.Duration(line.StartTime*float64(time.Second)),
+				EndTime:   start + time.Duration(line.EndTime*float64(time.Second)),
+				Text:      line.Original,
+			}
+			plan.Lyrics.Lines = append(plan.Lyrics.Lines, l)
+			l.Translation = line.GetDisplayText(file.Settings.PrimaryLanguage, false).Primary
+			plan.Subtitles = append(plan.Subtitles, l)
+		}
+	}
+	plan.Chapters = append(plan.Chapters, exportChapter{Start: start, End: plan.cursor, Title: plan.Lyrics.Title})
+	return nil
+}
+
+// ffmetadata 產生 ffmpeg 的 FFMETADATA1 檔案內容
+func (p *exportPlan) ffmetadata(lrc, lyricsKey string) string {
+	var sb strings.Builder
+	sb.WriteString(";FFMETADATA1\n")
+	sb.WriteString("title=" + escapeFFMetadata(p.Lyrics.Title) + "\n")
+	sb.WriteString("artist=" + escapeFFMetadata(p.Lyrics.Artist) + "\n")
+	sb.WriteString("album=" + escapeFFMetadata(p.Lyrics.Album) + "\n")
+	if lyricsKey != "" {
+		sb.WriteString(lyricsKey + "=" + escapeFFMetadata(lrc) + "\n")
+	}
+	for _, ch := range p.Chapters {
+		sb.WriteString("\n[CHAPTER]\nTIMEBASE=1/1000\n")
+		sb.WriteString(fmt.Sprintf("START=%d\nEND=%d\n", ch.Start.Milliseconds(), ch.End.Milliseconds()))
+		sb.WriteString("title=" + escapeFFMetadata(ch.Title) + "\n")
+	}
+	return sb.String()
+}
+
+// id3Lyrics MP3 的歌詞框架：USLT 放每行文字，SYLT 放每行的開始時間
+func (p *exportPlan) id3Lyrics(language string) id3.Lyrics {
+	lyrics := id3.Lyrics{Language: id3.LanguageCode(language)}
+	texts := make([]string, 0, len(p.Lyrics.Lines))
+	for _, line := range p.Lyrics.Lines {
+		texts = append(texts, line.Text)
+		lyrics.Synced = append(lyrics.Synced, id3.SyncedText{Time: line.StartTime, Text: line.Text})
+	}
+	lyrics.Text = strings.Join(texts, "\n")
+	return lyrics
+}
+
+// escapeFFMetadata 跳脫 ffmetadata 的特殊字元（= ; # \ 與換行）
+func escapeFFMetadata(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '=', ';', '#', '\\', '\n':
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
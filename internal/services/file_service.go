@@ -2,19 +2,24 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"multilang-learner/internal/audio"
 	"multilang-learner/internal/models"
+	"multilang-learner/internal/romanize"
+	"multilang-learner/internal/subtitle"
 )
 
 // generateID 生成隨機 ID
@@ -29,7 +34,12 @@ type FileService struct {
 	dataDir   string
 	uploadDir string
 	files     map[string]*models.MusicFile
+	index     *libraryIndex
 	mu        sync.RWMutex
+
+	resetHooks    []func(fileID string)    // 檔案內容被取代時通知其他服務
+	pendingHashes map[string]chan struct{} // 正在匯入的內容雜湊，完成時關閉 channel
+	busy          map[string]bool          // 正在處理或取代內容的檔案 ID
 }
 
 // NewFileService 建立檔案服務
@@ -42,6 +52,10 @@ func NewFileService(dataDir, uploadDir string) *FileService {
 		dataDir:   dataDir,
 		uploadDir: uploadDir,
 		files:     make(map[string]*models.MusicFile),
+		index:     newLibraryIndex(),
+
+		pendingHashes: make(map[string]chan struct{}),
+		busy:          make(map[string]bool),
 	}
 
 	// 載入已存在的檔案
@@ -63,23 +77,122 @@ func (s *FileService) loadExistingFiles() {
 			if data, err := os.ReadFile(metaPath); err == nil {
 				var file models.MusicFile
 				if json.Unmarshal(data, &file) == nil {
+					// 舊檔案沒有內容雜湊，補算一次以便去重
+					if file.ContentHash == "" {
+						if contentHash, err := hashFile(file.Filepath); err == nil {
+							file.ContentHash = contentHash
+							s.saveFileMeta(&file)
+						}
+					}
 					s.files[file.ID] = &file
+					s.index.updateInfo(&file)
+					if lyrics, err := s.loadLyrics(file.ID); err == nil {
+						s.index.updateLyrics(file.ID, lyrics)
+					}
 				}
 			}
 		}
 	}
 }
 
-// List 列出所有檔案
-func (s *FileService) List() ([]interface{}, error) {
+// ListQuery 檔案列表查詢條件
+type ListQuery struct {
+	Search   string   // 搜尋檔名、標題、歌手與歌詞
+	Status   string   // 依狀態篩選
+	Language string   // 依語言篩選（ISO 639-1）
+	Tag      string   // 依標籤篩選
+	FileIDs  []string // 限定檔案範圍（例如集合內的檔案），nil 表示不限定
+	Sort     string   // uploaded（預設）、title、practiced
+	Order    string   // asc 或 desc，未指定時依排序欄位決定
+	Cursor   string   // 上一頁回傳的 nextCursor
+	Limit    int      // 每頁筆數，0 表示不分頁
+}
+
+// ListResult 檔案列表查詢結果
+type ListResult struct {
+	Files      []models.FileListItem `json:"files"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+	Total      int                   `json:"total"` // 符合條件的總筆數
+}
+
+const maxListLimit = 200
+
+// List 列出檔案（搜尋、篩選、排序與分頁）
+func (s *FileService) List(query ListQuery) (*ListResult, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var list []interface{}
+	sortBy, desc, err := parseListSort(query.Sort, query.Order)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeListCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowed map[string]bool
+	if query.FileIDs != nil {
+		allowed = make(map[string]bool, len(query.FileIDs))
+		for _, id := range query.FileIDs {
+			allowed[id] = true
+		}
+	}
+
+	terms := searchTerms(query.Search)
+	var matched []*models.MusicFile
 	for _, f := range s.files {
-		list = append(list, f.ToListItem())
+		if allowed != nil && !allowed[f.ID] {
+			continue
+		}
+		if query.Tag != "" && !hasTag(f.Tags, query.Tag) {
+			continue
+		}
+		if query.Status != "" && string(f.Status) != query.Status {
+			continue
+		}
+		if query.Language != "" && !strings.EqualFold(f.Metadata.Language, query.Language) {
+			continue
+		}
+		if !s.index.match(f.ID, terms) {
+			continue
+		}
+		matched = append(matched, f)
+	}
+
+	// 依 (排序鍵, ID) 排序，確保游標位置穩定
+	keys := make(map[string]listCursor, len(matched))
+	for _, f := range matched {
+		keys[f.ID] = listCursor{Key: listSortKey(f, sortBy), ID: f.ID}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return keys[matched[i].ID].before(keys[matched[j].ID], desc)
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return after.before(keys[matched[i].ID], desc)
+		})
+	}
+
+	end := len(matched)
+	limit := query.Limit
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	if limit > 0 && start+limit < end {
+		end = start + limit
 	}
-	return list, nil
+
+	result := &ListResult{Files: []models.FileListItem{}, Total: len(matched)}
+	for _, f := range matched[start:end] {
+		result.Files = append(result.Files, f.ToListItem())
+	}
+	if end < len(matched) {
+		result.NextCursor = keys[matched[end-1].ID].encode()
+	}
+	return result, nil
 }
 
 // Get 獲取檔案
@@ -93,10 +206,74 @@ func (s *FileService) Get(id string) (interface{}, error) {
 	return nil, errors.New("檔案不存在")
 }
 
-// Upload 上傳檔案
-func (s *FileService) Upload(filename string, data []byte) (interface{}, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// ErrFileTooLarge 上傳檔案超過大小上限
+var ErrFileTooLarge = errors.New("檔案超過大小上限")
+
+// ErrFileBusy 檔案正在處理或取代內容
+var ErrFileBusy = errors.New("檔案處理中或正在取代，請稍後再試")
+
+// UnsupportedFormatError 不支援的音訊格式
+type UnsupportedFormatError struct {
+	Format string // ffprobe 偵測到的容器格式，無法辨識時為空
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	if e.Format == "" {
+		return "無法辨識的音訊格式"
+	}
+	return "不支援的音訊格式: " + e.Format
+}
+
+// UploadStream 以串流方式上傳檔案，maxSize 為 0 表示不限制大小
+// 偵測到重複時依 policy 處理，預設回傳 DuplicateFileError
+func (s *FileService) UploadStream(filename string, r io.Reader, maxSize int64, policy DuplicatePolicy) (*models.MusicFile, error) {
+	return s.ingest(filename, r, maxSize, policy)
+}
+
+// ImportFile 從本機路徑匯入檔案（串流複製，不會整檔讀入記憶體）
+func (s *FileService) ImportFile(path string) (*models.MusicFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return s.ingest(filepath.Base(path), f, 0, DuplicateReject)
+}
+
+// stagedUpload 已寫入暫存檔並通過格式檢查的上傳內容
+type stagedUpload struct {
+	path        string
+	size        int64
+	contentHash string
+	ext         string
+	probe       *probeResult
+}
+
+// ingest 將音訊串流寫入暫存檔並計算 SHA-256，確認格式與是否重複後
+// 移入資料目錄，再解析歌詞與歌曲資訊
+func (s *FileService) ingest(filename string, r io.Reader, maxSize int64, policy DuplicatePolicy) (*models.MusicFile, error) {
+	staged, err := s.stage(r, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(staged.path)
+
+	// 同樣內容的上傳（或匯入）同時只處理一個，後到的等前一個存好後再做重複偵測
+	release := s.reserveHash(staged.contentHash)
+	defer release()
+
+	// 重複偵測
+	if match := s.findDuplicate(staged); match != nil {
+		switch policy {
+		case DuplicateLink:
+			return match.Existing, nil
+		case DuplicateReplace:
+			return s.replace(match.Existing.ID, filename, staged)
+		case DuplicateKeep:
+		default:
+			return nil, &DuplicateFileError{Match: match}
+		}
+	}
 
 	// 生成 ID
 	id := generateID()
@@ -104,49 +281,216 @@ func (s *FileService) Upload(filename string, data []byte) (interface{}, error)
 	// 建立檔案目錄
 	fileDir := filepath.Join(s.dataDir, id)
 	os.MkdirAll(fileDir, 0755)
+
+	file := &models.MusicFile{
+		ID:         id,
+		UploadedAt: time.Now(),
+		Settings:   models.DefaultSettings(),
+	}
+	if err := s.store(file, filename, staged); err != nil {
+		os.RemoveAll(fileDir)
+		return nil, err
+	}
+
+	// 儲存元數據
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saveFileMeta(file)
+	s.files[id] = file
+	s.index.updateInfo(file)
+
+	return file, nil
+}
+
+// reserveHash 保留內容雜湊直到 release 被呼叫；已被保留時等待對方完成
+// release 需在檔案加入 s.files 之後才呼叫，等待的一方才找得到重複檔案
+func (s *FileService) reserveHash(contentHash string) (release func()) {
+	for {
+		s.mu.Lock()
+		pending, busy := s.pendingHashes[contentHash]
+		if !busy {
+			done := make(chan struct{})
+			s.pendingHashes[contentHash] = done
+			s.mu.Unlock()
+			return func() {
+				s.mu.Lock()
+				delete(s.pendingHashes, contentHash)
+				s.mu.Unlock()
+				close(done)
+			}
+		}
+		s.mu.Unlock()
+		<-pending
+	}
+}
+
+// acquire 標記檔案正在處理或取代內容，同一檔案同時只能進行一項；結束時呼叫 release
+// 只記在記憶體中，程式中斷後留下的 processing 狀態不會讓檔案一直無法處理
+func (s *FileService) acquire(id string) (release func(), err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[id]; !ok {
+		return nil, errors.New("檔案不存在")
+	}
+	if s.busy[id] {
+		return nil, ErrFileBusy
+	}
+	s.busy[id] = true
+	return func() {
+		s.mu.Lock()
+		delete(s.busy, id)
+		s.mu.Unlock()
+	}, nil
+}
+
+// stage 將上傳內容寫入暫存目錄，並以 ffprobe 確認實際的音訊格式
+func (s *FileService) stage(r io.Reader, maxSize int64) (*stagedUpload, error) {
+	tmp, err := os.CreateTemp(s.uploadDir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	staged := &stagedUpload{path: tmp.Name()}
+	tmp.Close()
+
+	fail := func(err error) (*stagedUpload, error) {
+		os.Remove(staged.path)
+		return nil, err
+	}
+
+	if maxSize > 0 {
+		// 多讀一個位元組用來判斷是否超過上限
+		r = io.LimitReader(r, maxSize+1)
+	}
+	staged.size, staged.contentHash, err = writeWithHash(staged.path, r)
+	if err != nil {
+		return fail(err)
+	}
+	if maxSize > 0 && staged.size > maxSize {
+		return fail(ErrFileTooLarge)
+	}
+
+	// 不信任用戶端的副檔名
+	staged.probe, err = s.probe(staged.path)
+	if err != nil {
+		return fail(&UnsupportedFormatError{})
+	}
+	staged.ext, err = staged.probe.audioExt()
+	if err != nil {
+		return fail(err)
+	}
+	return staged, nil
+}
+
+// store 將暫存檔移入檔案目錄，解析歌詞與歌曲資訊並填入 file
+func (s *FileService) store(file *models.MusicFile, filename string, staged *stagedUpload) error {
+	fileDir := filepath.Join(s.dataDir, file.ID)
 	os.MkdirAll(filepath.Join(fileDir, "segments"), 0755)
 	os.MkdirAll(filepath.Join(fileDir, "tts"), 0755)
 
 	// 儲存原始檔案
-	ext := filepath.Ext(filename)
-	originalPath := filepath.Join(fileDir, "original"+ext)
-	if err := os.WriteFile(originalPath, data, 0644); err != nil {
-		return nil, err
+	originalPath := filepath.Join(fileDir, "original"+staged.ext)
+	if err := moveFile(staged.path, originalPath); err != nil {
+		return err
 	}
 
-	// 獲取音訊時長
-	duration := s.getAudioDuration(originalPath)
+	file.Filename = filename
+	file.Filepath = originalPath
+	file.Duration = staged.probe.duration()
+	file.ContentHash = staged.contentHash
+	file.Status = models.StatusUploaded
+	file.LyricCount = 0
 
-	// 建立檔案記錄
-	file := &models.MusicFile{
-		ID:         id,
-		Filename:   filename,
-		Filepath:   originalPath,
-		Duration:   duration,
-		UploadedAt: time.Now(),
-		Status:     models.StatusUploaded,
-		Settings:   models.DefaultSettings(),
+	// 嘗試解析歌詞
+	parsed, err := s.parseLyrics(originalPath)
+	var lyrics []models.LyricLine
+	if err == nil && len(parsed.Lines) > 0 {
+		lyrics = s.toLyricLines(parsed)
 	}
 
-	// 嘗試解析歌詞
-	lyrics, err := s.parseLyrics(originalPath)
-	if err == nil && len(lyrics) > 0 {
+	// 整理歌曲資訊
+	file.Metadata = s.extractMetadata(fileDir, originalPath, staged.probe, parsed, lyrics)
+	romanizeChineseLines(lyrics, file.Metadata.Language)
+
+	if len(lyrics) > 0 {
 		file.Status = models.StatusParsed
 		file.LyricCount = len(lyrics)
 
 		// 儲存歌詞
 		lyricsData := &models.LyricsData{
-			FileID: id,
-			Lines:  lyrics,
+			FileID:       file.ID,
+			Lines:        lyrics,
+			DetectedLang: file.Metadata.Language,
+			Warnings:     toParseWarnings(parsed.Warnings),
 		}
-		s.saveLyrics(id, lyricsData)
+		s.saveLyrics(file.ID, lyricsData)
 	}
+	return nil
+}
 
-	// 儲存元數據
-	s.saveFileMeta(file)
-	s.files[id] = file
+// FindByHash 依內容雜湊尋找已存在的檔案
+func (s *FileService) FindByHash(contentHash string) *models.MusicFile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return file, nil
+	for _, f := range s.files {
+		if f.ContentHash != "" && f.ContentHash == contentHash {
+			return f
+		}
+	}
+	return nil
+}
+
+// writeWithHash 串流寫入檔案並回傳大小與 SHA-256
+func writeWithHash(path string, r io.Reader) (int64, string, error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return 0, "", err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), r)
+	if err != nil {
+		out.Close()
+		return 0, "", err
+	}
+	if err := out.Close(); err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// moveFile 移動檔案，跨磁碟時改用複製
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// hashFile 計算檔案 SHA-256
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // Delete 刪除檔案
@@ -163,9 +507,13 @@ func (s *FileService) Delete(id string) error {
 	os.RemoveAll(fileDir)
 
 	delete(s.files, id)
+	s.index.remove(id)
 	return nil
 }
 
+// maxFadeMs 段落淡入淡出長度上限（毫秒）
+const maxFadeMs = 1000
+
 // UpdateSettings 更新設定
 func (s *FileService) UpdateSettings(id string, settings interface{}) error {
 	s.mu.Lock()
@@ -194,11 +542,106 @@ func (s *FileService) UpdateSettings(id string, settings interface{}) error {
 	if showChinese, ok := settingsMap["showChineseTranslation"].(bool); ok {
 		file.Settings.ShowChineseTranslation = showChinese
 	}
+	if speed, ok := settingsMap["playbackSpeed"].(float64); ok {
+		if err := validateSpeed(speed); err != nil {
+			return err
+		}
+		file.Settings.PlaybackSpeed = speed
+	}
+	if speed, ok := settingsMap["ttsSpeed"].(float64); ok {
+		if err := validateSpeed(speed); err != nil {
+			return err
+		}
+		file.Settings.TTSSpeed = speed
+	}
+	if target, ok := settingsMap["loudnessTarget"].(float64); ok {
+		if err := validateLoudnessTarget(target); err != nil {
+			return err
+		}
+		file.Settings.LoudnessTarget = target
+	}
+	if fade, ok := settingsMap["fadeMs"].(float64); ok {
+		if fade < 0 || fade > maxFadeMs {
+			return errors.New("淡入淡出長度必須介於 0 到 1000 毫秒")
+		}
+		file.Settings.FadeMs = int(fade)
+	}
+	if extract, ok := settingsMap["extractVocabulary"].(bool); ok {
+		file.Settings.ExtractVocabulary = extract
+	}
+	if roman, ok := settingsMap["romanize"].(bool); ok {
+		file.Settings.Romanize = roman
+	}
 
 	s.saveFileMeta(file)
 	return nil
 }
 
+// SetTags 設定檔案標籤（去除空白與重複，不分大小寫）
+func (s *FileService) SetTags(id string, tags []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[id]
+	if !ok {
+		return nil, errors.New("檔案不存在")
+	}
+
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || hasTag(normalized, tag) {
+			continue
+		}
+		normalized = append(normalized, tag)
+	}
+
+	file.Tags = normalized
+	s.saveFileMeta(file)
+	s.index.updateInfo(file)
+	return normalized, nil
+}
+
+// ListTags 列出所有標籤與使用次數
+func (s *FileService) ListTags() []models.TagCount {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]*models.TagCount)
+	for _, f := range s.files {
+		for _, tag := range f.Tags {
+			key := strings.ToLower(tag)
+			if tc, ok := counts[key]; ok {
+				tc.Count++
+			} else {
+				counts[key] = &models.TagCount{Tag: tag, Count: 1}
+			}
+		}
+	}
+
+	result := []models.TagCount{}
+	for _, tc := range counts {
+		result = append(result, *tc)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return strings.ToLower(result[i].Tag) < strings.ToLower(result[j].Tag)
+	})
+	return result
+}
+
+// hasTag 檢查標籤是否存在（不分大小寫）
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetFilePath 獲取檔案路徑
 func (s *FileService) GetFilePath(id string) (string, error) {
 	s.mu.RLock()
@@ -250,74 +693,90 @@ func (s *FileService) saveLyrics(id string, lyrics *models.LyricsData) {
 	lyricsPath := filepath.Join(s.dataDir, id, "lyrics.json")
 	data, _ := json.MarshalIndent(lyrics, "", "  ")
 	os.WriteFile(lyricsPath, data, 0644)
+	s.index.updateLyrics(id, lyrics)
 }
 
-// getAudioDuration 獲取音訊時長
-func (s *FileService) getAudioDuration(filePath string) float64 {
-	cmd := exec.Command("ffprobe",
-		"-v", "error",
-		"-show_entries", "format=duration",
-		"-of", "default=noprint_wrappers=1:nokey=1",
-		filePath,
-	)
-	output, err := cmd.Output()
+// loadLyrics 讀取歌詞
+func (s *FileService) loadLyrics(id string) (*models.LyricsData, error) {
+	data, err := os.ReadFile(filepath.Join(s.dataDir, id, "lyrics.json"))
 	if err != nil {
-		return 0
+		return nil, err
+	}
+	var lyrics models.LyricsData
+	if err := json.Unmarshal(data, &lyrics); err != nil {
+		return nil, err
 	}
-	duration, _ := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
-	return duration
+	return &lyrics, nil
+}
+
+// ReindexLyrics 歌詞更新後同步搜尋索引
+func (s *FileService) ReindexLyrics(id string, lyrics *models.LyricsData) {
+	s.index.updateLyrics(id, lyrics)
 }
 
-// parseLyrics 解析歌詞
-func (s *FileService) parseLyrics(filePath string) ([]models.LyricLine, error) {
+// MarkPracticed 記錄最後練習時間
+func (s *FileService) MarkPracticed(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[id]
+	if !ok {
+		return errors.New("檔案不存在")
+	}
+	now := time.Now()
+	file.LastPracticedAt = &now
+	s.saveFileMeta(file)
+	return nil
+}
+
+// parseLyrics 提取並解析內嵌歌詞
+func (s *FileService) parseLyrics(filePath string) (*subtitle.Lyrics, error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	// 根據檔案類型選擇不同的提取方式
+	runner := audio.NewRunner(false)
+	ctx := context.Background()
 	var output []byte
 	var err error
 
 	switch ext {
 	case ".flac":
 		// FLAC 檔案：使用 format_tags=lyrics
-		cmd := exec.Command("ffprobe",
+		output, err = runner.FFprobe(ctx,
 			"-v", "error",
 			"-show_entries", "format_tags=lyrics",
 			"-of", "default=noprint_wrappers=1:nokey=1",
 			filePath,
 		)
-		output, err = cmd.Output()
 
 	case ".mp3":
 		// MP3 檔案：嘗試多種方式提取歌詞
 		// 方式 1: 使用 format_tags=lyrics
-		cmd := exec.Command("ffprobe",
+		output, err = runner.FFprobe(ctx,
 			"-v", "error",
 			"-show_entries", "format_tags=lyrics",
 			"-of", "default=noprint_wrappers=1:nokey=1",
 			filePath,
 		)
-		output, err = cmd.Output()
 
 		// 如果 format_tags=lyrics 失敗或為空，嘗試 USLT (Unsynchronized Lyrics)
 		if err != nil || len(bytes.TrimSpace(output)) == 0 {
-			cmd = exec.Command("ffprobe",
+			output, err = runner.FFprobe(ctx,
 				"-v", "error",
 				"-show_entries", "format_tags=lyrics-xxx", // ID3v2 USLT tag
 				"-of", "default=noprint_wrappers=1:nokey=1",
 				filePath,
 			)
-			output, err = cmd.Output()
 		}
 
 		// 如果還是失敗，嘗試讀取所有 format_tags
 		if err != nil || len(bytes.TrimSpace(output)) == 0 {
-			cmd = exec.Command("ffprobe",
+			jsonOutput, jsonErr := runner.FFprobe(ctx,
 				"-v", "error",
 				"-show_entries", "format_tags",
 				"-of", "json",
 				filePath,
 			)
-			jsonOutput, jsonErr := cmd.Output()
 			if jsonErr == nil {
 				output = s.extractLyricsFromJSON(jsonOutput)
 			}
@@ -325,13 +784,12 @@ func (s *FileService) parseLyrics(filePath string) ([]models.LyricLine, error) {
 
 	default:
 		// 其他格式：使用通用方式
-		cmd := exec.Command("ffprobe",
+		output, err = runner.FFprobe(ctx,
 			"-v", "error",
 			"-show_entries", "format_tags=lyrics",
 			"-of", "default=noprint_wrappers=1:nokey=1",
 			filePath,
 		)
-		output, err = cmd.Output()
 	}
 
 	if err != nil || len(bytes.TrimSpace(output)) == 0 {
@@ -339,7 +797,7 @@ func (s *FileService) parseLyrics(filePath string) ([]models.LyricLine, error) {
 	}
 
 	// 解析 LRC 格式
-	return s.parseLRC(string(output))
+	return subtitle.NewParser().Parse(string(output))
 }
 
 // extractLyricsFromJSON 從 ffprobe JSON 輸出中提取歌詞
@@ -382,105 +840,74 @@ func (s *FileService) extractLyricsFromJSON(jsonData []byte) []byte {
 	return nil
 }
 
-// parseLRC 解析 LRC 格式歌詞
-// 支援格式：日文行 + 中文翻譯行（相同時間戳）
-func (s *FileService) parseLRC(content string) ([]models.LyricLine, error) {
-	rawLines := strings.Split(content, "\n")
-
-	// 第一遍：解析所有行
-	type parsedLine struct {
-		timestamp string
-		startTime float64
-		text      string
-	}
-	var parsed []parsedLine
-
-	for _, line := range rawLines {
-		line = strings.TrimSpace(line)
-		if line == "" || !strings.HasPrefix(line, "[") {
-			continue
+// toLyricLines 將 subtitle.Parser 的解析結果轉換為歌詞行
+// 支援格式：日文行 + 中文翻譯行（相同時間戳），第三行以後保留在 Extras
+func (s *FileService) toLyricLines(parsed *subtitle.Lyrics) []models.LyricLine {
+	var lines []models.LyricLine
+	for i, l := range parsed.Lines {
+		texts := l.Texts()
+
+		// 判斷哪個是原文，哪個是翻譯
+		// 通常中文翻譯包含漢字，原文取第一個非中文行
+		origIdx := 0
+		if len(texts) > 1 && s.isChinese(texts[0]) {
+			for j, t := range texts {
+				if !s.isChinese(t) {
+					origIdx = j
+					break
+				}
+			}
 		}
+		original := texts[origIdx]
 
-		// 跳過元數據標籤
-		if strings.HasPrefix(line, "[ti:") || strings.HasPrefix(line, "[ar:") ||
-			strings.HasPrefix(line, "[al:") || strings.HasPrefix(line, "[by:") ||
-			strings.HasPrefix(line, "[offset:") || strings.HasPrefix(line, "[kana:") {
-			continue
+		var rest []string
+		for j, t := range texts {
+			if j != origIdx {
+				rest = append(rest, t)
+			}
 		}
 
-		// 解析時間戳 [mm:ss.xx]
-		closeBracket := strings.Index(line, "]")
-		if closeBracket == -1 {
-			continue
+		// 內嵌翻譯優先取中文行，否則保持原順序
+		var embedded string
+		for j, t := range rest {
+			if s.isChinese(t) {
+				embedded = t
+				rest = append(rest[:j:j], rest[j+1:]...)
+				break
+			}
 		}
-
-		timestamp := line[1:closeBracket]
-		text := strings.TrimSpace(line[closeBracket+1:])
-
-		// 跳過空行和純符號行
-		if text == "" || text == "//" {
-			continue
+		if embedded == "" && len(rest) > 0 {
+			embedded, rest = rest[0], rest[1:]
 		}
 
-		startTime := s.parseTimestamp(timestamp)
-		parsed = append(parsed, parsedLine{
-			timestamp: timestamp,
-			startTime: startTime,
-			text:      text,
-		})
-	}
-
-	// 第二遍：合併相同時間戳的行（日文 + 中文翻譯）
-	var lines []models.LyricLine
-	index := 0
-
-	for i := 0; i < len(parsed); i++ {
-		p := parsed[i]
-
-		var original, embedded string
-		original = p.text
-
-		// 檢查下一行是否是相同時間戳（翻譯行）
-		if i+1 < len(parsed) && parsed[i+1].startTime == p.startTime {
-			// 判斷哪個是原文，哪個是翻譯
-			// 通常中文翻譯包含漢字
-			if s.isChinese(parsed[i+1].text) && !s.isChinese(p.text) {
-				embedded = parsed[i+1].text
-			} else if s.isChinese(p.text) && !s.isChinese(parsed[i+1].text) {
-				original = parsed[i+1].text
-				embedded = p.text
-			} else {
-				// 兩者都是同語言，保持原順序
-				embedded = parsed[i+1].text
-			}
-			i++ // 跳過下一行（已處理）
-		}
+		// 俄文、假名、韓文可在本地轉寫；中文等判斷出語言後以字表轉寫，日文漢字留給處理流程呼叫翻譯模型
+		romanization, _ := romanize.Romanize(original)
 
-		lyricLine := models.LyricLine{
-			Index:     index,
-			Timestamp: p.timestamp,
-			StartTime: p.startTime,
-			Original:  original,
+		lines = append(lines, models.LyricLine{
+			Index:        i,
+			Timestamp:    subtitle.FormatTime(l.StartTime),
+			StartTime:    l.StartTime.Seconds(),
+			EndTime:      l.EndTime.Seconds(),
+			Original:     original,
+			Romanization: romanization,
 			Translations: models.Translations{
 				Embedded: embedded,
 				Zh:       embedded, // 假設內嵌翻譯是中文
 			},
+			Extras:       rest,
 			IsMeaningful: len(strings.TrimSpace(original)) > 0 && original != "//" && !s.isMetadataLine(original),
-		}
-
-		lines = append(lines, lyricLine)
-		index++
+		})
 	}
+	return lines
+}
 
-	// 計算結束時間
-	for i := 0; i < len(lines)-1; i++ {
-		lines[i].EndTime = lines[i+1].StartTime
-	}
-	if len(lines) > 0 {
-		lines[len(lines)-1].EndTime = lines[len(lines)-1].StartTime + 5
+// toParseWarnings 轉換解析警告
+func toParseWarnings(warnings []subtitle.Warning) []models.ParseWarning {
+	var result []models.ParseWarning
+	for _, w := range warnings {
+		result = append(result, models.ParseWarning{Line: w.Line, Content: w.Content, Message: w.Message})
 	}
-
-	return lines, nil
+	return result
 }
 
 // isChinese 判斷是否包含中文字符
@@ -501,28 +928,3 @@ func (s *FileService) isMetadataLine(text string) bool {
 		strings.Contains(text, "编曲") || strings.Contains(text, "lyrics") ||
 		strings.HasPrefix(text, "lemon -") || strings.Contains(text, " - ")
 }
-
-// parseTimestamp 解析時間戳
-func (s *FileService) parseTimestamp(ts string) float64 {
-	// 格式: mm:ss.xx 或 mm:ss:xx
-	ts = strings.Replace(ts, ":", ".", 1) // 只替換第一個 : 為 .
-	parts := strings.Split(ts, ".")
-	if len(parts) < 2 {
-		return 0
-	}
-
-	minutes, _ := strconv.ParseFloat(parts[0], 64)
-	seconds, _ := strconv.ParseFloat(parts[1], 64)
-
-	var ms float64
-	if len(parts) >= 3 {
-		ms, _ = strconv.ParseFloat(parts[2], 64)
-		if len(parts[2]) == 2 {
-			ms = ms / 100
-		} else if len(parts[2]) == 3 {
-			ms = ms / 1000
-		}
-	}
-
-	return minutes*60 + seconds + ms
-}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// importAudioExts 匯入時掃描的音訊副檔名
+var importAudioExts = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".m4a":  true,
+	".aac":  true,
+	".ogg":  true,
+	".opus": true,
+	".wav":  true,
+}
+
+// importSettleTime 檔案最後修改後需經過的時間，避免匯入仍在複製中的檔案
+const importSettleTime = 5 * time.Second
+
+// ImportConfig 匯入設定
+type ImportConfig struct {
+	Dir          string        // 監看的資料夾
+	PollInterval time.Duration // 輪詢間隔，0 表示只在手動觸發時掃描
+	AutoProcess  bool          // 匯入後自動開始處理
+}
+
+// importedEntry 已處理過的來源檔案
+type importedEntry struct {
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	ContentHash string    `json:"contentHash"`
+	FileID      string    `json:"fileId"`
+	Error       string    `json:"error,omitempty"` // 匯入失敗的原因，檔案大小或修改時間改變前不再重試
+}
+
+// ImportReport 單次掃描結果
+type ImportReport struct {
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Scanned    int               `json:"scanned"`              // 掃描到的音訊檔數
+	Imported   []string          `json:"imported"`             // 新匯入的檔案 ID
+	Duplicates map[string]string `json:"duplicates"`           // 來源路徑 -> 已存在的檔案 ID
+	Failed     map[string]string `json:"failed"`               // 來源路徑 -> 錯誤訊息
+	Processing []string          `json:"processing,omitempty"` // 已自動開始處理的檔案 ID
+}
+
+// ImportStatus 匯入狀態
+type ImportStatus struct {
+	Dir          string        `json:"dir"`
+	Watching     bool          `json:"watching"`
+	PollInterval string        `json:"pollInterval,omitempty"`
+	AutoProcess  bool          `json:"autoProcess"`
+	Running      bool          `json:"running"`
+	LastReport   *ImportReport `json:"lastReport,omitempty"`
+}
+
+// ImportService 批次匯入服務
+type ImportService struct {
+	config         ImportConfig
+	dataDir        string
+	fileService    *FileService
+	processService *ProcessService
+	seen           map[string]*importedEntry // 來源路徑 -> 已處理資訊
+	lastReport     *ImportReport
+	running        bool
+	mu             sync.Mutex
+}
+
+// NewImportService 建立匯入服務
+func NewImportService(config ImportConfig, dataDir string, fileService *FileService, processService *ProcessService) *ImportService {
+	s := &ImportService{
+		config:         config,
+		dataDir:        dataDir,
+		fileService:    fileService,
+		processService: processService,
+		seen:           make(map[string]*importedEntry),
+	}
+	s.loadState()
+	return s
+}
+
+// Enabled 是否已設定匯入資料夾
+func (s *ImportService) Enabled() bool {
+	return s.config.Dir != ""
+}
+
+// Watch 依輪詢間隔持續掃描（在背景執行）
+func (s *ImportService) Watch() {
+	if !s.Enabled() || s.config.PollInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			s.Scan()
+			<-ticker.C
+		}
+	}()
+}
+
+// Status 獲取匯入狀態
+func (s *ImportService) Status() *ImportStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &ImportStatus{
+		Dir:         s.config.Dir,
+		Watching:    s.Enabled() && s.config.PollInterval > 0,
+		AutoProcess: s.config.AutoProcess,
+		Running:     s.running,
+		LastReport:  s.lastReport,
+	}
+	if status.Watching {
+		status.PollInterval = s.config.PollInterval.String()
+	}
+	return status
+}
+
+// StartScan 在背景開始掃描
+func (s *ImportService) StartScan() error {
+	if !s.Enabled() {
+		return errors.New("未設定匯入資料夾 (IMPORT_DIR)")
+	}
+	s.mu.Lock()
+	running := s.running
+	s.mu.Unlock()
+	if running {
+		return errors.New("匯入進行中")
+	}
+
+	go s.Scan()
+	return nil
+}
+
+// Scan 掃描匯入資料夾，匯入新的音訊檔
+func (s *ImportService) Scan() (*ImportReport, error) {
+	if !s.Enabled() {
+		return nil, errors.New("未設定匯入資料夾 (IMPORT_DIR)")
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, errors.New("匯入進行中")
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	report := &ImportReport{
+		StartedAt:  time.Now(),
+		Imported:   []string{},
+		Duplicates: make(map[string]string),
+		Failed:     make(map[string]string),
+	}
+
+	err := filepath.WalkDir(s.config.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			report.Failed[path] = err.Error()
+			return nil
+		}
+		if d.IsDir() || !importAudioExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		report.Scanned++
+		s.importOne(path, d, report)
+		return nil
+	})
+
+	report.FinishedAt = time.Now()
+
+	s.mu.Lock()
+	s.running = false
+	s.lastReport = report
+	s.saveState()
+	s.mu.Unlock()
+
+	return report, err
+}
+
+// importOne 匯入單一檔案
+func (s *ImportService) importOne(path string, d fs.DirEntry, report *ImportReport) {
+	info, err := d.Info()
+	if err != nil {
+		report.Failed[path] = err.Error()
+		return
+	}
+	// 仍在寫入中的檔案留到下次掃描
+	if time.Since(info.ModTime()) < importSettleTime {
+		return
+	}
+
+	// 大小與修改時間沒變就不重新計算雜湊，之前失敗的檔案也不重試
+	s.mu.Lock()
+	prev, ok := s.seen[path]
+	s.mu.Unlock()
+	if ok && prev.Size == info.Size() && prev.ModTime.Equal(info.ModTime()) {
+		if prev.Error != "" {
+			report.Failed[path] = prev.Error
+		}
+		return
+	}
+
+	entry := &importedEntry{Size: info.Size(), ModTime: info.ModTime()}
+	fail := func(err error) {
+		report.Failed[path] = err.Error()
+		entry.Error = err.Error()
+		s.mu.Lock()
+		s.seen[path] = entry
+		s.mu.Unlock()
+	}
+
+	contentHash, err := hashFile(path)
+	if err != nil {
+		fail(err)
+		return
+	}
+	entry.ContentHash = contentHash
+
+	if existing := s.fileService.FindByHash(contentHash); existing != nil {
+		entry.FileID = existing.ID
+		report.Duplicates[path] = existing.ID
+	} else {
+		file, err := s.fileService.ImportFile(path)
+		var dup *DuplicateFileError
+		if errors.As(err, &dup) {
+			// 時長與標籤相近的歌曲也視為重複，不自動匯入
+			entry.FileID = dup.Match.Existing.ID
+			report.Duplicates[path] = dup.Match.Existing.ID
+			s.mu.Lock()
+			s.seen[path] = entry
+			s.mu.Unlock()
+			return
+		}
+		if err != nil {
+			fail(err)
+			return
+		}
+		entry.FileID = file.ID
+		report.Imported = append(report.Imported, file.ID)
+
+		if s.config.AutoProcess && s.processService != nil {
+			if err := s.processService.StartProcess(file.ID, nil); err == nil {
+				report.Processing = append(report.Processing, file.ID)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.seen[path] = entry
+	s.mu.Unlock()
+}
+
+// loadState 載入已匯入紀錄
+func (s *ImportService) loadState() {
+	data, err := os.ReadFile(filepath.Join(s.dataDir, "import_state.json"))
+	if err != nil {
+		return
+	}
+	json.Unmarshal(data, &s.seen)
+	if s.seen == nil {
+		s.seen = make(map[string]*importedEntry)
+	}
+}
+
+// saveState 儲存已匯入紀錄（呼叫者需持有鎖）
+func (s *ImportService) saveState() {
+	data, _ := json.MarshalIndent(s.seen, "", "  ")
+	os.WriteFile(filepath.Join(s.dataDir, "import_state.json"), data, 0644)
+}
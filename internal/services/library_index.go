@@ -0,0 +1,198 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"multilang-learner/internal/models"
+)
+
+// indexEntry 單一檔案的搜尋資料（皆已轉小寫）
+type indexEntry struct {
+	info   string // 檔名、標題、歌手、專輯、標籤
+	lyrics string // 原文與翻譯歌詞
+}
+
+// libraryIndex 檔案庫的記憶體索引
+// 啟動時由 loadExistingFiles 重建，之後隨上傳、刪除與歌詞更新同步
+type libraryIndex struct {
+	entries map[string]*indexEntry
+	mu      sync.RWMutex
+}
+
+func newLibraryIndex() *libraryIndex {
+	return &libraryIndex{entries: make(map[string]*indexEntry)}
+}
+
+// updateInfo 更新檔案資訊部分
+func (idx *libraryIndex) updateInfo(file *models.MusicFile) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	entry := idx.entry(file.ID)
+	fields := []string{
+		file.Filename,
+		file.Metadata.Title,
+		file.Metadata.Artist,
+		file.Metadata.Album,
+	}
+	entry.info = strings.ToLower(strings.Join(append(fields, file.Tags...), "\n"))
+}
+
+// updateLyrics 更新歌詞部分
+func (idx *libraryIndex) updateLyrics(fileID string, lyrics *models.LyricsData) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var sb strings.Builder
+	for _, line := range lyrics.Lines {
+		sb.WriteString(line.Original)
+		sb.WriteString("\n")
+		for _, t := range []string{line.Translations.Embedded, line.Translations.En, line.Translations.Zh} {
+			if t != "" {
+				sb.WriteString(t)
+				sb.WriteString("\n")
+			}
+		}
+	}
+	idx.entry(fileID).lyrics = strings.ToLower(sb.String())
+}
+
+// remove 移除檔案
+func (idx *libraryIndex) remove(fileID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	delete(idx.entries, fileID)
+}
+
+// match 檢查檔案是否包含所有搜尋詞
+func (idx *libraryIndex) match(fileID string, terms []string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entry, ok := idx.entries[fileID]
+	if !ok {
+		return len(terms) == 0
+	}
+	for _, term := range terms {
+		if !strings.Contains(entry.info, term) && !strings.Contains(entry.lyrics, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// entry 取得或建立索引項目（呼叫者需持有寫鎖）
+func (idx *libraryIndex) entry(fileID string) *indexEntry {
+	entry, ok := idx.entries[fileID]
+	if !ok {
+		entry = &indexEntry{}
+		idx.entries[fileID] = entry
+	}
+	return entry
+}
+
+// searchTerms 將搜尋字串拆成小寫搜尋詞
+func searchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// 排序欄位
+const (
+	sortUploaded  = "uploaded"
+	sortTitle     = "title"
+	sortPracticed = "practiced"
+)
+
+// sortTimeLayout 可依字串順序比較的時間格式
+const sortTimeLayout = "20060102150405.000000000"
+
+// parseListSort 解析排序欄位與方向
+// 上傳日期與練習時間預設由新到舊，標題預設由 A 到 Z
+func parseListSort(sortBy, order string) (string, bool, error) {
+	if sortBy == "" {
+		sortBy = sortUploaded
+	}
+	var desc bool
+	switch sortBy {
+	case sortUploaded, sortPracticed:
+		desc = true
+	case sortTitle:
+		desc = false
+	default:
+		return "", false, fmt.Errorf("無效的排序欄位: %s", sortBy)
+	}
+
+	switch order {
+	case "":
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	default:
+		return "", false, fmt.Errorf("無效的排序方向: %s", order)
+	}
+	return sortBy, desc, nil
+}
+
+// listSortKey 取得檔案的排序鍵
+func listSortKey(f *models.MusicFile, sortBy string) string {
+	switch sortBy {
+	case sortTitle:
+		title := f.Metadata.Title
+		if title == "" {
+			title = f.Filename
+		}
+		return strings.ToLower(title)
+	case sortPracticed:
+		// 從未練習過的排在最後（由新到舊時）
+		if f.LastPracticedAt == nil {
+			return ""
+		}
+		return f.LastPracticedAt.UTC().Format(sortTimeLayout)
+	default:
+		return f.UploadedAt.UTC().Format(sortTimeLayout)
+	}
+}
+
+// listCursor 分頁游標：上一頁最後一筆的排序鍵與 ID
+type listCursor struct {
+	Key string `json:"k"`
+	ID  string `json:"id"`
+}
+
+// before 判斷 c 是否排在 other 之前
+func (c listCursor) before(other listCursor, desc bool) bool {
+	if c.Key != other.Key {
+		return (c.Key < other.Key) != desc
+	}
+	if c.ID == other.ID {
+		return false
+	}
+	return (c.ID < other.ID) != desc
+}
+
+func (c listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor 解析游標，空字串代表第一頁
+func decodeListCursor(s string) (*listCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("無效的分頁游標")
+	}
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, errors.New("無效的分頁游標")
+	}
+	return &c, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"os"
+
+	"multilang-learner/internal/audio"
+	"multilang-learner/internal/models"
+)
+
+// 目標響度範圍（LUFS）
+const (
+	minLoudnessTarget = -30.0
+	maxLoudnessTarget = -10.0
+)
+
+// validateLoudnessTarget 檢查目標響度，0 代表不做響度正規化
+func validateLoudnessTarget(target float64) error {
+	if target == 0 {
+		return nil
+	}
+	if target < minLoudnessTarget || target > maxLoudnessTarget {
+		return fmt.Errorf("目標響度必須介於 %.0f 到 %.0f LUFS", minLoudnessTarget, maxLoudnessTarget)
+	}
+	return nil
+}
+
+// loudnessTarget 取得檔案設定的目標響度，0 表示沿用峰值匹配
+func (s *ProcessService) loudnessTarget(fileID string) float64 {
+	file, err := s.fileService.GetFile(fileID)
+	if err != nil {
+		return 0
+	}
+	return file.Settings.LoudnessTarget
+}
+
+// normalizeSegmentAudio 將段落音訊原地正規化到目標響度，回傳正規化前的響度
+func (s *ProcessService) normalizeSegmentAudio(path string, target float64) (float64, error) {
+	tmpPath := path + ".loudnorm.mp3"
+	stats, err := audio.NewProcessor(false).NormalizeLoudness(path, tmpPath, target)
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	return stats.IntegratedLUFS, nil
+}
+
+// levelTTS 調整 TTS 音量後輸出到 ttsPath（tempPath 為 TTS 產生的 WAV）
+// 有設定目標響度時以 loudnorm 正規化並記錄響度，否則讓峰值與原曲段落一致
+// 調整失敗時直接轉檔使用原始 TTS
+func (s *ProcessService) levelTTS(seg *models.Segment, target float64, tempPath, ttsPath string) {
+	audioProcessor := audio.NewProcessor(false)
+	seg.TTSLoudness = 0
+
+	var err error
+	switch {
+	case target != 0:
+		var stats *audio.LoudnessStats
+		stats, err = audioProcessor.NormalizeLoudness(tempPath, ttsPath, target)
+		if err == nil {
+			seg.TTSLoudness = stats.IntegratedLUFS
+		}
+	case seg.AudioPath != "":
+		err = audioProcessor.MatchVolume(seg.AudioPath, tempPath, ttsPath)
+	default:
+		err = audioProcessor.EncodeMP3(tempPath, ttsPath)
+	}
+
+	if err != nil {
+		audioProcessor.EncodeMP3(tempPath, ttsPath)
+	}
+	os.Remove(tempPath)
+}
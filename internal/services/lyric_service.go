@@ -56,7 +56,11 @@ func (s *LyricService) SaveLyrics(fileID string, lyrics *models.LyricsData) erro
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(lyricsPath, data, 0644)
+	if err := os.WriteFile(lyricsPath, data, 0644); err != nil {
+		return err
+	}
+	s.fileService.ReindexLyrics(fileID, lyrics)
+	return nil
 }
 
 // DetectStartLine AI 判斷歌詞起點
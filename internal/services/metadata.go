@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"multilang-learner/internal/audio"
+	"multilang-learner/internal/langdetect"
+	"multilang-learner/internal/models"
+	"multilang-learner/internal/subtitle"
+)
+
+// probeStream ffprobe 串流資訊
+type probeStream struct {
+	Index       int    `json:"index"`
+	CodecType   string `json:"codec_type"`
+	CodecName   string `json:"codec_name"`
+	Disposition struct {
+		AttachedPic int `json:"attached_pic"`
+	} `json:"disposition"`
+	Tags map[string]string `json:"tags"`
+}
+
+// probeResult ffprobe JSON 輸出
+type probeResult struct {
+	Format struct {
+		FormatName string            `json:"format_name"`
+		Duration   string            `json:"duration"`
+		Tags       map[string]string `json:"tags"`
+	} `json:"format"`
+	Streams []probeStream `json:"streams"`
+}
+
+// probe 使用 ffprobe 讀取容器資訊、標籤與串流
+func (s *FileService) probe(filePath string) (*probeResult, error) {
+	output, err := audio.NewRunner(false).FFprobe(context.Background(),
+		"-v", "error",
+		"-show_entries", "format=format_name,duration:format_tags:stream=index,codec_type,codec_name:stream_disposition=attached_pic:stream_tags",
+		"-of", "json",
+		filePath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var result probeResult
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// audioExt 依 ffprobe 偵測的容器格式決定副檔名，不支援的格式回傳錯誤
+func (p *probeResult) audioExt() (string, error) {
+	var audioCodec string
+	for _, st := range p.Streams {
+		if st.CodecType == "audio" {
+			audioCodec = st.CodecName
+			break
+		}
+	}
+	if audioCodec == "" {
+		return "", &UnsupportedFormatError{Format: p.Format.FormatName}
+	}
+
+	// format_name 可能是逗號分隔的別名清單，例如 "mov,mp4,m4a,3gp,3g2,mj2"
+	for _, name := range strings.Split(p.Format.FormatName, ",") {
+		switch name {
+		case "mp3":
+			return ".mp3", nil
+		case "flac":
+			return ".flac", nil
+		case "wav":
+			return ".wav", nil
+		case "aac":
+			return ".aac", nil
+		case "ogg":
+			if audioCodec == "opus" {
+				return ".opus", nil
+			}
+			return ".ogg", nil
+		case "mp4", "m4a":
+			return ".m4a", nil
+		}
+	}
+	return "", &UnsupportedFormatError{Format: p.Format.FormatName}
+}
+
+// duration 解析時長（秒）
+func (p *probeResult) duration() float64 {
+	d, _ := strconv.ParseFloat(strings.TrimSpace(p.Format.Duration), 64)
+	return d
+}
+
+// tags 合併容器與音訊串流標籤，鍵值統一為小寫
+// Ogg/Opus 的 Vorbis comment 會出現在串流標籤中
+func (p *probeResult) tags() map[string]string {
+	tags := make(map[string]string)
+	for _, st := range p.Streams {
+		if st.CodecType != "audio" {
+			continue
+		}
+		for k, v := range st.Tags {
+			tags[strings.ToLower(k)] = v
+		}
+	}
+	for k, v := range p.Format.Tags {
+		tags[strings.ToLower(k)] = v
+	}
+	return tags
+}
+
+// coverStream 尋找內嵌封面串流
+func (p *probeResult) coverStream() *probeStream {
+	for i, st := range p.Streams {
+		if st.CodecType == "video" && st.Disposition.AttachedPic == 1 {
+			return &p.Streams[i]
+		}
+	}
+	// 部分 FLAC 沒有標記 attached_pic，但圖片串流仍是封面
+	for i, st := range p.Streams {
+		if st.CodecType == "video" && (st.CodecName == "mjpeg" || st.CodecName == "png") {
+			return &p.Streams[i]
+		}
+	}
+	return nil
+}
+
+// extractMetadata 整理歌曲資訊
+// 容器標籤優先，缺少時使用 LRC 的 [ti]/[ar]/[al]，語言最後以歌詞內容判斷
+func (s *FileService) extractMetadata(fileDir, filePath string, probe *probeResult, parsed *subtitle.Lyrics, lines []models.LyricLine) models.SongMetadata {
+	var meta models.SongMetadata
+
+	if probe != nil {
+		tags := probe.tags()
+		meta.Title = firstTag(tags, "title")
+		meta.Artist = firstTag(tags, "artist", "album_artist", "performer")
+		meta.Album = firstTag(tags, "album")
+		meta.Language = langdetect.NormalizeCode(firstTag(tags, "language", "lang"))
+		if track := firstTag(tags, "track", "tracknumber"); track != "" {
+			// 格式可能是 "3" 或 "3/12"
+			track, _, _ = strings.Cut(track, "/")
+			meta.TrackNumber, _ = strconv.Atoi(strings.TrimSpace(track))
+		}
+
+		if st := probe.coverStream(); st != nil {
+			if coverPath, err := s.extractCover(fileDir, filePath, st); err == nil {
+				meta.HasCover = true
+				meta.CoverPath = coverPath
+			}
+		}
+	}
+
+	if parsed != nil {
+		if meta.Title == "" {
+			meta.Title = parsed.Title
+		}
+		if meta.Artist == "" {
+			meta.Artist = parsed.Artist
+		}
+		if meta.Album == "" {
+			meta.Album = parsed.Album
+		}
+	}
+
+	if meta.Language == "" && len(lines) > 0 {
+		var texts []string
+		for _, line := range lines {
+			if line.IsMeaningful {
+				texts = append(texts, line.Original)
+			}
+		}
+		meta.Language = langdetect.NewDetector().DetectCode(strings.Join(texts, "\n"))
+	}
+
+	return meta
+}
+
+// extractCover 將內嵌封面輸出為圖片檔
+func (s *FileService) extractCover(fileDir, filePath string, st *probeStream) (string, error) {
+	args := []string{"-y", "-v", "error", "-i", filePath, "-map", "0:" + strconv.Itoa(st.Index), "-frames:v", "1"}
+
+	var coverPath string
+	switch st.CodecName {
+	case "mjpeg":
+		coverPath = filepath.Join(fileDir, "cover.jpg")
+		args = append(args, "-c", "copy")
+	case "png":
+		coverPath = filepath.Join(fileDir, "cover.png")
+		args = append(args, "-c", "copy")
+	default:
+		// 其他格式（bmp、gif 等）轉成 JPEG
+		coverPath = filepath.Join(fileDir, "cover.jpg")
+		args = append(args, "-c:v", "mjpeg")
+	}
+	args = append(args, coverPath)
+
+	if err := audio.NewRunner(false).FFmpeg(context.Background(), args...); err != nil {
+		return "", err
+	}
+	return coverPath, nil
+}
+
+// GetCoverPath 獲取封面圖片路徑
+func (s *FileService) GetCoverPath(id string) (string, error) {
+	file, err := s.GetFile(id)
+	if err != nil {
+		return "", err
+	}
+	if !file.Metadata.HasCover || file.Metadata.CoverPath == "" {
+		return "", errors.New("沒有封面圖片")
+	}
+	if _, err := os.Stat(file.Metadata.CoverPath); err != nil {
+		return "", errors.New("沒有封面圖片")
+	}
+	return file.Metadata.CoverPath, nil
+}
+
+// firstTag 依序取得第一個非空標籤
+func firstTag(tags map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := strings.TrimSpace(tags[k]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -6,9 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +25,7 @@ type ProcessService struct {
 	progress     map[string]*models.ProcessProgress
 	mu           sync.RWMutex
 	apiKey       string
+	vocabulary   *VocabularyService // 可選：設定 ExtractVocabulary 時在處理最後擷取詞彙
 }
 
 // NewProcessService 建立處理服務
@@ -41,10 +40,21 @@ func NewProcessService(dataDir string, fileService *FileService, lyricService *L
 	}
 }
 
+// SetVocabularyService 設定詞彙服務，啟用處理流程中的詞彙擷取
+func (s *ProcessService) SetVocabularyService(vocabulary *VocabularyService) {
+	s.vocabulary = vocabulary
+}
+
 // StartProcess 開始處理
 func (s *ProcessService) StartProcess(fileID string, settings interface{}) error {
+	// 同一檔案同時只能處理一次，也不能在取代內容時開始處理
+	release, err := s.fileService.acquire(fileID)
+	if err != nil {
+		return err
+	}
 	file, err := s.fileService.GetFile(fileID)
 	if err != nil {
+		release()
 		return err
 	}
 
@@ -61,7 +71,10 @@ func (s *ProcessService) StartProcess(fileID string, settings interface{}) error
 	s.mu.Unlock()
 
 	// 異步處理
-	go s.process(file)
+	go func() {
+		defer release()
+		s.process(file)
+	}()
 
 	return nil
 }
@@ -86,6 +99,15 @@ func (s *ProcessService) process(file *models.MusicFile) {
 		return
 	}
 
+	// 產生羅馬拼音（需在分割前完成，段落顯示文字才會帶到；失敗不中斷處理）
+	doneMsg := "處理完成！"
+	if file.Settings.Romanize {
+		s.updateProgress(fileID, "romanizing", 1, 50, "產生羅馬拼音...")
+		if _, _, err := s.RomanizeLyrics(context.Background(), fileID, false); err != nil {
+			doneMsg = "處理完成（羅馬拼音產生失敗: " + err.Error() + "）"
+		}
+	}
+
 	// Step 2: 分割段落
 	s.updateProgress(fileID, "segmenting", 2, 50, "分割音訊段落...")
 	if err := s.createSegments(fileID, file); err != nil {
@@ -100,8 +122,16 @@ func (s *ProcessService) process(file *models.MusicFile) {
 		return
 	}
 
+	// 擷取詞彙（失敗不影響練習，只在完成訊息中提示）
+	if s.vocabulary != nil && s.vocabulary.Available() && file.Settings.ExtractVocabulary {
+		s.updateProgress(fileID, "extracting_vocabulary", 3, 90, "擷取詞彙中...")
+		if _, err := s.vocabulary.Extract(context.Background(), fileID); err != nil {
+			doneMsg = "處理完成（詞彙擷取失敗: " + err.Error() + "）"
+		}
+	}
+
 	// Step 4: 完成
-	s.updateProgress(fileID, "done", 4, 100, "處理完成！")
+	s.updateProgress(fileID, "done", 4, 100, doneMsg)
 	s.fileService.UpdateStatus(fileID, models.StatusReady)
 }
 
@@ -223,13 +253,6 @@ func (s *ProcessService) createSegments(fileID string, file *models.MusicFile) e
 			// 生成段落文字
 			s.generateSegmentText(currentSegment, lyrics.Lines, file.Settings.PrimaryLanguage)
 
-			// 切割音訊
-			audioPath := filepath.Join(segmentDir, fmt.Sprintf("segment_%03d.mp3", segIndex))
-			if err := s.cutAudio(file.Filepath, audioPath, currentSegment.StartTime, currentSegment.EndTime); err != nil {
-				return err
-			}
-			currentSegment.AudioPath = audioPath
-
 			segments = append(segments, *currentSegment)
 			currentSegment = nil
 			segIndex++
@@ -239,14 +262,14 @@ func (s *ProcessService) createSegments(fileID string, file *models.MusicFile) e
 	// 處理最後一個段落
 	if currentSegment != nil {
 		s.generateSegmentText(currentSegment, lyrics.Lines, file.Settings.PrimaryLanguage)
-		audioPath := filepath.Join(segmentDir, fmt.Sprintf("segment_%03d.mp3", segIndex))
-		if err := s.cutAudio(file.Filepath, audioPath, currentSegment.StartTime, currentSegment.EndTime); err != nil {
-			return err
-		}
-		currentSegment.AudioPath = audioPath
 		segments = append(segments, *currentSegment)
 	}
 
+	// 一次切割所有段落音訊
+	if err := s.cutSegments(file, segments, segmentDir); err != nil {
+		return err
+	}
+
 	// 儲存段落資料
 	segmentsData := &models.SegmentsData{
 		FileID:   fileID,
@@ -280,19 +303,40 @@ func (s *ProcessService) generateSegmentText(seg *models.Segment, lines []models
 	seg.TTSText = strings.Join(translations, " ")
 }
 
-// cutAudio 切割音訊
-func (s *ProcessService) cutAudio(inputPath, outputPath string, start, end float64) error {
-	duration := end - start
-	cmd := exec.Command("ffmpeg",
-		"-y",
-		"-i", inputPath,
-		"-ss", strconv.FormatFloat(start, 'f', 3, 64),
-		"-t", strconv.FormatFloat(duration, 'f', 3, 64),
-		"-acodec", "libmp3lame",
-		"-b:a", "192k",
-		outputPath,
-	)
-	return cmd.Run()
+// cutSegments 切割所有段落音訊（依設定淡入淡出），有設定目標響度時一併正規化並記錄原始響度
+func (s *ProcessService) cutSegments(file *models.MusicFile, segments []models.Segment, segmentDir string) error {
+	fade := time.Duration(file.Settings.FadeMs) * time.Millisecond
+	cuts := make([]audio.Cut, len(segments))
+	for i := range segments {
+		cuts[i] = audio.Cut{
+			Start:  secondsToDuration(segments[i].StartTime),
+			End:    secondsToDuration(segments[i].EndTime),
+			Output: filepath.Join(segmentDir, fmt.Sprintf("segment_%03d.mp3", segments[i].Index)),
+			Fade:   fade,
+		}
+	}
+	if err := audio.NewProcessor(false).CutSegments(context.Background(), file.Filepath, cuts); err != nil {
+		return err
+	}
+
+	for i := range segments {
+		seg := &segments[i]
+		seg.AudioPath = cuts[i].Output
+		removeVariants(seg.AudioPath)
+
+		if target := file.Settings.LoudnessTarget; target != 0 {
+			// 正規化失敗（例如整段靜音）時保留原始切割
+			if lufs, err := s.normalizeSegmentAudio(seg.AudioPath, target); err == nil {
+				seg.Loudness = lufs
+			}
+		}
+	}
+	return nil
+}
+
+// secondsToDuration 將秒數轉為 time.Duration
+func secondsToDuration(sec float64) time.Duration {
+	return time.Duration(sec * float64(time.Second))
 }
 
 // generateTTS 生成 TTS
@@ -314,8 +358,8 @@ func (s *ProcessService) generateTTS(fileID, lang string) error {
 		}
 	}
 
-	// 建立音訊處理器（用於音量匹配）
-	audioProcessor := audio.NewProcessor(false)
+	// 目標響度（0 表示讓 TTS 峰值與原曲段落一致）
+	loudnessTarget := s.loudnessTarget(fileID)
 
 	ctx := context.Background()
 	totalSegments := 0
@@ -337,32 +381,19 @@ func (s *ProcessService) generateTTS(fileID, lang string) error {
 			fmt.Sprintf("生成 TTS... (%d/%d)", processedSegments, totalSegments))
 
 		ttsPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d.mp3", i))
-		ttsTempPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d_temp.mp3", i))
+		ttsTempPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d_temp.wav", i))
 		segments.Segments[i].TTSPath = ttsPath
+		removeVariants(ttsPath)
 
 		if ttsGen != nil {
 			// 使用真正的 TTS API 生成到暫存檔案
-			err := ttsGen.GenerateSpeech(ctx, seg.TTSText, ttsTempPath)
+			err := ttsGen.GenerateWAV(ctx, seg.TTSText, ttsTempPath)
 			if err != nil {
 				// TTS 失敗時，嘗試生成靜音檔案作為佔位
 				s.generateSilence(ttsPath, 2.0)
 			} else {
-				// TTS 成功，進行音量匹配
-				segmentAudioPath := seg.AudioPath
-				if segmentAudioPath != "" {
-					// 音量匹配：讓 TTS 音量與原曲段落一致
-					err = audioProcessor.MatchVolume(segmentAudioPath, ttsTempPath, ttsPath)
-					if err != nil {
-						// 音量匹配失敗，直接使用原始 TTS
-						os.Rename(ttsTempPath, ttsPath)
-					} else {
-						// 刪除暫存檔案
-						os.Remove(ttsTempPath)
-					}
-				} else {
-					// 沒有段落音訊，直接使用原始 TTS
-					os.Rename(ttsTempPath, ttsPath)
-				}
+				// TTS 成功，進行音量匹配或響度正規化
+				s.levelTTS(&segments.Segments[i], loudnessTarget, ttsTempPath, ttsPath)
 			}
 			// 延遲避免 API 限流
 			time.Sleep(500 * time.Millisecond)
@@ -377,7 +408,7 @@ func (s *ProcessService) generateTTS(fileID, lang string) error {
 
 // generateSilence 生成靜音音訊
 func (s *ProcessService) generateSilence(outputPath string, duration float64) error {
-	cmd := exec.Command("ffmpeg",
+	return audio.NewRunner(false).FFmpeg(context.Background(),
 		"-y",
 		"-f", "lavfi",
 		"-i", fmt.Sprintf("anullsrc=r=44100:cl=stereo:d=%f", duration),
@@ -385,7 +416,6 @@ func (s *ProcessService) generateSilence(outputPath string, duration float64) er
 		"-b:a", "128k",
 		outputPath,
 	)
-	return cmd.Run()
 }
 
 // GetProgress 獲取進度
@@ -430,57 +460,6 @@ func (s *ProcessService) saveSegments(fileID string, segments *models.SegmentsDa
 	return os.WriteFile(segmentsPath, data, 0644)
 }
 
-// Export 導出合併音檔
-func (s *ProcessService) Export(fileID string) (string, error) {
-	file, err := s.fileService.GetFile(fileID)
-	if err != nil {
-		return "", err
-	}
-
-	segments, err := s.GetSegmentsData(fileID)
-	if err != nil {
-		return "", err
-	}
-
-	// 建立合併列表
-	exportDir := filepath.Join(s.dataDir, fileID)
-	listPath := filepath.Join(exportDir, "concat_list.txt")
-	var listContent strings.Builder
-
-	for _, seg := range segments.Segments {
-		// 原曲段落
-		if seg.AudioPath != "" {
-			listContent.WriteString(fmt.Sprintf("file '%s'\n", seg.AudioPath))
-		}
-		// TTS（根據重複次數）
-		if seg.TTSPath != "" {
-			for i := 0; i < file.Settings.TTSRepeatCount; i++ {
-				listContent.WriteString(fmt.Sprintf("file '%s'\n", seg.TTSPath))
-			}
-		}
-	}
-
-	if err := os.WriteFile(listPath, []byte(listContent.String()), 0644); err != nil {
-		return "", err
-	}
-
-	// 合併音檔
-	exportPath := filepath.Join(exportDir, "export.mp3")
-	cmd := exec.Command("ffmpeg",
-		"-y",
-		"-f", "concat",
-		"-safe", "0",
-		"-i", listPath,
-		"-c", "copy",
-		exportPath,
-	)
-	if err := cmd.Run(); err != nil {
-		return "", err
-	}
-
-	return exportPath, nil
-}
-
 // updateProgress 更新進度
 func (s *ProcessService) updateProgress(fileID, status string, step int, progress float64, message string) {
 	s.mu.Lock()
@@ -577,30 +556,23 @@ func (s *ProcessService) RetranslateSegment(fileID string, segmentIndex int) (st
 	// 重新生成該段落的 TTS
 	ttsDir := filepath.Join(s.dataDir, fileID, "tts")
 	ttsPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d.mp3", segmentIndex))
-	ttsTempPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d_temp.mp3", segmentIndex))
+	ttsTempPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d_temp.wav", segmentIndex))
+	removeVariants(ttsPath)
 
 	ttsGen, err := tts.NewGeminiTTS(s.apiKey, false)
 	if err != nil {
 		return newTranslation, nil // 翻譯成功但 TTS 失敗，仍然回傳翻譯
 	}
 
-	err = ttsGen.GenerateSpeech(ctx, newTranslation, ttsTempPath)
+	err = ttsGen.GenerateWAV(ctx, newTranslation, ttsTempPath)
 	if err != nil {
 		return newTranslation, nil // 翻譯成功但 TTS 失敗
 	}
 
-	// 音量匹配
-	audioProcessor := audio.NewProcessor(false)
-	if seg.AudioPath != "" {
-		err = audioProcessor.MatchVolume(seg.AudioPath, ttsTempPath, ttsPath)
-		if err != nil {
-			os.Rename(ttsTempPath, ttsPath)
-		} else {
-			os.Remove(ttsTempPath)
-		}
-	} else {
-		os.Rename(ttsTempPath, ttsPath)
-	}
+	// 音量匹配或響度正規化，並記錄 TTS 響度
+	s.levelTTS(seg, s.loudnessTarget(fileID), ttsTempPath, ttsPath)
+	seg.TTSPath = ttsPath
+	s.saveSegments(fileID, segments)
 
 	return newTranslation, nil
 }
@@ -655,30 +627,23 @@ func (s *ProcessService) RetranslateSegmentWithInput(fileID string, segmentIndex
 	// 重新生成該段落的 TTS
 	ttsDir := filepath.Join(s.dataDir, fileID, "tts")
 	ttsPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d.mp3", segmentIndex))
-	ttsTempPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d_temp.mp3", segmentIndex))
+	ttsTempPath := filepath.Join(ttsDir, fmt.Sprintf("tts_%03d_temp.wav", segmentIndex))
+	removeVariants(ttsPath)
 
 	ttsGen, err := tts.NewGeminiTTS(s.apiKey, false)
 	if err != nil {
 		return englishTranslation, nil // 翻譯成功但 TTS 建立失敗
 	}
 
-	err = ttsGen.GenerateSpeech(ctx, englishTranslation, ttsTempPath)
+	err = ttsGen.GenerateWAV(ctx, englishTranslation, ttsTempPath)
 	if err != nil {
 		return englishTranslation, nil // 翻譯成功但 TTS 生成失敗
 	}
 
-	// 音量匹配
-	audioProcessor := audio.NewProcessor(false)
-	if seg.AudioPath != "" {
-		err = audioProcessor.MatchVolume(seg.AudioPath, ttsTempPath, ttsPath)
-		if err != nil {
-			os.Rename(ttsTempPath, ttsPath)
-		} else {
-			os.Remove(ttsTempPath)
-		}
-	} else {
-		os.Rename(ttsTempPath, ttsPath)
-	}
+	// 音量匹配或響度正規化，並記錄 TTS 響度
+	s.levelTTS(seg, s.loudnessTarget(fileID), ttsTempPath, ttsPath)
+	seg.TTSPath = ttsPath
+	s.saveSegments(fileID, segments)
 
 	return englishTranslation, nil
 }
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"multilang-learner/internal/models"
+)
+
+// 複習清單的預設值與上限
+const (
+	DefaultNewReviews  = 20  // 每天加入的新段落數
+	DefaultReviewLimit = 100 // 清單長度
+	MaxReviewLimit     = 500
+	reviewDateLayout   = "2006-01-02"
+)
+
+// 段落音訊的 API 網址
+const (
+	segmentAudioURL = "/api/files/%s/segments/%d/audio"
+	segmentTTSURL   = "/api/files/%s/segments/%d/tts"
+)
+
+var (
+	ErrInvalidGrade       = errors.New("評分必須介於 0 到 5")
+	ErrInvalidReviewLimit = errors.New("複習數量必須介於 0 到 500")
+	ErrSegmentNotFound    = errors.New("段落不存在")
+)
+
+// ReviewService 段落的間隔重複複習（SM-2）
+// 每個段落是一張卡片，紀錄存於 data/{file_id}/reviews.json
+type ReviewService struct {
+	dataDir        string
+	fileService    *FileService
+	processService *ProcessService
+	cards          map[string]map[int]*models.ReviewCard // fileID -> 段落索引 -> 卡片
+	mu             sync.RWMutex
+}
+
+// NewReviewService 建立複習服務
+func NewReviewService(dataDir string, fileService *FileService, processService *ProcessService) *ReviewService {
+	s := &ReviewService{
+		dataDir:        dataDir,
+		fileService:    fileService,
+		processService: processService,
+		cards:          make(map[string]map[int]*models.ReviewCard),
+	}
+	s.load()
+	fileService.OnReset(s.RemoveFile)
+	return s
+}
+
+// load 載入各檔案的複習紀錄
+func (s *ReviewService) load() {
+	entries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dataDir, entry.Name(), "reviews.json"))
+		if err != nil {
+			continue
+		}
+		var list []*models.ReviewCard
+		if json.Unmarshal(data, &list) != nil {
+			continue
+		}
+		cards := make(map[int]*models.ReviewCard, len(list))
+		for _, card := range list {
+			cards[card.SegmentIndex] = card
+		}
+		s.cards[entry.Name()] = cards
+	}
+}
+
+// save 儲存檔案的複習紀錄（呼叫者需持有寫鎖）
+func (s *ReviewService) save(fileID string) error {
+	list := make([]*models.ReviewCard, 0, len(s.cards[fileID]))
+	for _, card := range s.cards[fileID] {
+		list = append(list, card)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].SegmentIndex < list[j].SegmentIndex })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dataDir, fileID, "reviews.json"), data, 0644)
+}
+
+// card 取得段落目前有效的卡片，段落文字已改變（重新處理過）時視為沒有卡片
+func (s *ReviewService) card(fileID string, seg models.Segment) *models.ReviewCard {
+	card := s.cards[fileID][seg.Index]
+	if card == nil || card.Text != seg.OriginalText {
+		return nil
+	}
+	return card
+}
+
+// Grade 記錄段落的複習評分（0~5）並排定下次複習時間
+func (s *ReviewService) Grade(fileID string, segIdx, grade int) (*models.ReviewCard, error) {
+	if grade < 0 || grade > models.MaxReviewGrade {
+		return nil, ErrInvalidGrade
+	}
+	if _, err := s.fileService.GetFile(fileID); err != nil {
+		return nil, err
+	}
+	segments, err := s.processService.GetSegmentsData(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if segIdx < 0 || segIdx >= len(segments.Segments) || !segments.Segments[segIdx].IsMeaningful {
+		return nil, ErrSegmentNotFound
+	}
+	seg := segments.Segments[segIdx]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	card := s.card(fileID, seg)
+	if card == nil {
+		card = models.NewReviewCard(fileID, seg.Index, seg.OriginalText, now)
+		if s.cards[fileID] == nil {
+			s.cards[fileID] = make(map[int]*models.ReviewCard)
+		}
+		s.cards[fileID][seg.Index] = card
+	}
+	card.Review(grade, now)
+	if err := s.save(fileID); err != nil {
+		return nil, err
+	}
+	copied := *card
+	return &copied, nil
+}
+
+// Cards 列出檔案的複習卡片（依段落排序，不含已失效的卡片）
+func (s *ReviewService) Cards(fileID string) ([]*models.ReviewCard, error) {
+	segments, err := s.processService.GetSegmentsData(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := []*models.ReviewCard{}
+	for _, seg := range segments.Segments {
+		if card := s.card(fileID, seg); card != nil {
+			copied := *card
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+// Due 今天到期的複習清單（所有處理完成的歌曲）
+// 先排到期的卡片（越早到期越前面），再依歌名與段落順序補上新段落；
+// 今天已經第一次複習過的段落也算在 newLimit 內，重新整理清單不會再多出新段落
+func (s *ReviewService) Due(newLimit, limit int) (*models.ReviewQueue, error) {
+	if newLimit < 0 || newLimit > MaxReviewLimit || limit < 0 || limit > MaxReviewLimit {
+		return nil, ErrInvalidReviewLimit
+	}
+	files, err := s.fileService.List(ListQuery{Status: string(models.StatusReady), Sort: "title"})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var due, fresh []models.ReviewItem
+	introducedToday := 0
+	for _, file := range files.Files {
+		segments, err := s.processService.GetSegmentsData(file.ID)
+		if err != nil {
+			continue
+		}
+		for _, seg := range segments.Segments {
+			if !seg.IsMeaningful || seg.AudioPath == "" {
+				continue
+			}
+			item := models.ReviewItem{
+				FileID:       file.ID,
+				Filename:     file.Filename,
+				Title:        file.Title,
+				SegmentIndex: seg.Index,
+				OriginalText: seg.OriginalText,
+				TTSText:      seg.TTSText,
+				AudioURL:     fmt.Sprintf(segmentAudioURL, file.ID, seg.Index),
+				TTSURL:       fmt.Sprintf(segmentTTSURL, file.ID, seg.Index),
+			}
+			card := s.card(file.ID, seg)
+			if card != nil && card.FirstReviewAt != nil && !card.FirstReviewAt.Before(startOfDay) {
+				introducedToday++
+			}
+			switch {
+			case card == nil:
+				item.New = true
+				fresh = append(fresh, item)
+			case card.Due.Before(endOfDay):
+				copied := *card
+				item.Card = &copied
+				due = append(due, item)
+			}
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].Card.Due.Before(due[j].Card.Due) })
+
+	queue := &models.ReviewQueue{Date: now.Format(reviewDateLayout), DueCount: len(due)}
+	newLimit = max(newLimit-introducedToday, 0)
+	queue.Items = append(due, fresh[:min(newLimit, len(fresh))]...)
+	queue.Items = queue.Items[:min(limit, len(queue.Items))]
+	for _, item := range queue.Items {
+		if item.New {
+			queue.NewCount++
+		}
+	}
+	if queue.Items == nil {
+		queue.Items = []models.ReviewItem{}
+	}
+	return queue, nil
+}
+
+// RemoveFile 移除檔案的複習紀錄（刪除或取代檔案時呼叫）
+func (s *ReviewService) RemoveFile(fileID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cards, fileID)
+}
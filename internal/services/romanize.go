@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"multilang-learner/internal/models"
+	"multilang-learner/internal/romanize"
+	"multilang-learner/internal/translator"
+)
+
+// romanizeBatchLines 每次請翻譯模型轉寫的行數
+const romanizeBatchLines = 20
+
+// RomanizeLyrics 為原文歌詞產生羅馬拼音
+// 俄文、假名、韓文與中文在本地查表轉寫；日文漢字、字表沒有的漢字與無法由詞語判斷讀音的多音字
+// 改用翻譯模型（需要 API key），中文統一為聲調符號拼音。沒有 API key 或翻譯失敗時，
+// 多音字沿用本地以最常見讀音產生的結果
+// force 為 false 時保留已有的羅馬拼音，但上傳時以最常見讀音猜出的多音字行仍會交給翻譯模型確認；
+// 回傳更新的行數與仍缺少羅馬拼音的行數
+func (s *ProcessService) RomanizeLyrics(ctx context.Context, fileID string, force bool) (updated, missing int, err error) {
+	lyrics, err := s.lyricService.GetLyricsData(fileID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// 無法在本地確定的行，依原文語言分組；fallback 為多音字行的本地結果
+	pending := make(map[string][]int)
+	fallback := make(map[int]string)
+	var langs []string
+	for i := range lyrics.Lines {
+		line := &lyrics.Lines[i]
+		if !line.IsMeaningful {
+			continue
+		}
+		if roman, ok := romanize.Romanize(line.Original); ok {
+			if (force || line.Romanization == "") && roman != line.Romanization {
+				line.Romanization = roman
+				updated++
+			}
+			continue
+		}
+		lang := romanizeLanguage(line.Original, lyrics.DetectedLang)
+		local, ambiguous, localOK := "", false, false
+		if lang == "zh" {
+			local, ambiguous, localOK = romanize.Pinyin(line.Original)
+		}
+		if !force && line.Romanization != "" && !(ambiguous && line.Romanization == local) {
+			continue
+		}
+		if localOK {
+			if !ambiguous {
+				if local != line.Romanization {
+					line.Romanization = local
+					updated++
+				}
+				continue
+			}
+			fallback[i] = local
+		}
+		if _, seen := pending[lang]; !seen {
+			langs = append(langs, lang)
+		}
+		pending[lang] = append(pending[lang], i)
+	}
+
+	// useFallback 翻譯模型無法使用時改用本地結果，沒有本地結果的行記為缺少
+	useFallback := func(indices []int) {
+		for _, idx := range indices {
+			if roman, ok := fallback[idx]; ok {
+				if roman != lyrics.Lines[idx].Romanization {
+					lyrics.Lines[idx].Romanization = roman
+					updated++
+				}
+			} else {
+				missing++
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		var trans *translator.GeminiTranslator
+		if s.apiKey != "" {
+			trans, err = translator.NewGeminiTranslator(s.apiKey, false)
+			if err != nil {
+				return 0, 0, fmt.Errorf("建立翻譯器失敗: %w", err)
+			}
+		}
+		for _, lang := range langs {
+			indices := pending[lang]
+			if trans == nil {
+				useFallback(indices)
+				continue
+			}
+			for start := 0; start < len(indices); start += romanizeBatchLines {
+				batch := indices[start:min(start+romanizeBatchLines, len(indices))]
+				texts := make([]string, len(batch))
+				for j, idx := range batch {
+					texts[j] = lyrics.Lines[idx].Original
+				}
+				results, err := trans.Romanize(ctx, texts, lang)
+				if err != nil {
+					// 單批失敗只影響這一批，其餘批次照常處理
+					useFallback(batch)
+					continue
+				}
+				for j, idx := range batch {
+					if lang == "zh" {
+						results[j] = romanize.ToneMarks(results[j])
+					}
+					lyrics.Lines[idx].Romanization = results[j]
+					updated++
+				}
+			}
+		}
+	}
+
+	if updated > 0 {
+		if err := s.lyricService.SaveLyrics(fileID, lyrics); err != nil {
+			return 0, 0, err
+		}
+	}
+	return updated, missing, nil
+}
+
+// romanizeChineseLines 以本地字表為中文歌詞行產生拼音（不需要翻譯模型，已有羅馬拼音的行保留）
+// 多音字先用最常見的讀音，開啟羅馬拼音設定時處理流程會再請翻譯模型確認
+func romanizeChineseLines(lines []models.LyricLine, detected string) {
+	for i := range lines {
+		line := &lines[i]
+		if line.Romanization != "" || !romanize.HasHan(line.Original) || romanizeLanguage(line.Original, detected) != "zh" {
+			continue
+		}
+		line.Romanization, _, _ = romanize.Pinyin(line.Original)
+	}
+}
+
+// romanizeLanguage 判斷含漢字的行要用哪種轉寫：有假名就是日文，否則依檔案語言，預設中文
+func romanizeLanguage(text, detected string) string {
+	if romanize.HasKana(text) {
+		return "ja"
+	}
+	if detected == "ja" {
+		return "ja"
+	}
+	return "zh"
+}
@@ -0,0 +1,432 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"multilang-learner/internal/models"
+)
+
+// 練習紀錄的預設值與上限
+const (
+	DefaultUserID      = "default"
+	DefaultStatsDays   = 30
+	maxStatsDays       = 365
+	maxEventsPerBatch  = 500
+	maxWeakSegments    = 10
+	practiceDateLayout = "2006-01-02"
+)
+
+var (
+	ErrInvalidUserID    = errors.New("使用者 ID 只能包含英數字、- 與 _（最多 64 字）")
+	ErrSessionNotFound  = errors.New("練習紀錄不存在")
+	ErrSessionEnded     = errors.New("練習已結束")
+	ErrInvalidEvent     = errors.New("無效的練習事件")
+	ErrInvalidStatsDays = errors.New("統計天數必須介於 1 到 365")
+)
+
+var userIDRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidateUserID 檢查使用者 ID（會用作目錄名稱）
+func ValidateUserID(userID string) error {
+	if !userIDRe.MatchString(userID) {
+		return ErrInvalidUserID
+	}
+	return nil
+}
+
+// SessionService 練習紀錄與統計
+// 紀錄依使用者存於 data/users/{user_id}/sessions.json
+type SessionService struct {
+	dataDir        string
+	fileService    *FileService
+	processService *ProcessService
+	sessions       map[string][]*models.PracticeSession // userID -> 練習紀錄（依開始時間排序）
+	mu             sync.RWMutex
+}
+
+// NewSessionService 建立練習紀錄服務
+func NewSessionService(dataDir string, fileService *FileService, processService *ProcessService) *SessionService {
+	s := &SessionService{
+		dataDir:        dataDir,
+		fileService:    fileService,
+		processService: processService,
+		sessions:       make(map[string][]*models.PracticeSession),
+	}
+	s.load()
+	return s
+}
+
+// load 載入所有使用者的練習紀錄
+func (s *SessionService) load() {
+	entries, err := os.ReadDir(filepath.Join(s.dataDir, "users"))
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dataDir, "users", entry.Name(), "sessions.json"))
+		if err != nil {
+			continue
+		}
+		var list []*models.PracticeSession
+		if json.Unmarshal(data, &list) != nil {
+			continue
+		}
+		s.sessions[entry.Name()] = list
+	}
+}
+
+// save 儲存使用者的練習紀錄（呼叫者需持有寫鎖）
+func (s *SessionService) save(userID string) error {
+	dir := filepath.Join(s.dataDir, "users", userID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.sessions[userID], "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "sessions.json"), data, 0644)
+}
+
+// find 找出使用者的練習紀錄（呼叫者需持有鎖）
+func (s *SessionService) find(userID, sessionID string) *models.PracticeSession {
+	for _, session := range s.sessions[userID] {
+		if session.ID == sessionID {
+			return session
+		}
+	}
+	return nil
+}
+
+// copySession 複製練習紀錄，避免呼叫者讀到之後的修改
+func copySession(session *models.PracticeSession) *models.PracticeSession {
+	copied := *session
+	copied.Events = append([]models.PracticeEvent{}, session.Events...)
+	return &copied
+}
+
+// Start 開始一次練習
+func (s *SessionService) Start(userID, mode string) (*models.PracticeSession, error) {
+	if err := ValidateUserID(userID); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := &models.PracticeSession{
+		ID:        generateID(),
+		UserID:    userID,
+		Mode:      mode,
+		StartedAt: time.Now(),
+		Events:    []models.PracticeEvent{},
+	}
+	s.sessions[userID] = append(s.sessions[userID], session)
+	if err := s.save(userID); err != nil {
+		return nil, err
+	}
+	return copySession(session), nil
+}
+
+// AddEvents 記錄練習事件，沒有時間的事件以目前時間記錄
+func (s *SessionService) AddEvents(userID, sessionID string, events []models.PracticeEvent) (*models.PracticeSession, error) {
+	if err := ValidateUserID(userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkEvents(events); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.find(userID, sessionID)
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.EndedAt != nil {
+		return nil, ErrSessionEnded
+	}
+	s.append(session, events)
+	if err := s.save(userID); err != nil {
+		return nil, err
+	}
+	return copySession(session), nil
+}
+
+// End 結束練習，可一併送出最後的事件
+func (s *SessionService) End(userID, sessionID string, events []models.PracticeEvent) (*models.PracticeSession, error) {
+	if err := ValidateUserID(userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkEvents(events); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.find(userID, sessionID)
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.EndedAt != nil {
+		return nil, ErrSessionEnded
+	}
+	s.append(session, events)
+	now := time.Now()
+	session.EndedAt = &now
+	if err := s.save(userID); err != nil {
+		return nil, err
+	}
+	return copySession(session), nil
+}
+
+// checkEvents 檢查事件類型、評分與檔案
+func (s *SessionService) checkEvents(events []models.PracticeEvent) error {
+	if len(events) > maxEventsPerBatch {
+		return ErrInvalidEvent
+	}
+	for _, e := range events {
+		switch e.Type {
+		case models.EventPlay, models.EventRepeat, models.EventSkip:
+		case models.EventGrade:
+			if e.Grade == nil || *e.Grade < 0 || *e.Grade > models.MaxReviewGrade {
+				return ErrInvalidGrade
+			}
+		default:
+			return ErrInvalidEvent
+		}
+		if e.SegmentIndex < 0 {
+			return ErrInvalidEvent
+		}
+		if _, err := s.fileService.GetFile(e.FileID); err != nil {
+			return fmt.Errorf("%w: 檔案 %s 不存在", ErrInvalidEvent, e.FileID)
+		}
+	}
+	return nil
+}
+
+// append 加入事件並補上時間（呼叫者需持有寫鎖）
+func (s *SessionService) append(session *models.PracticeSession, events []models.PracticeEvent) {
+	now := time.Now()
+	for _, e := range events {
+		if e.At.IsZero() || e.At.After(now) || e.At.Before(session.StartedAt) {
+			e.At = now
+		}
+		session.Events = append(session.Events, e)
+	}
+}
+
+// List 列出使用者最近的練習紀錄（新的在前）
+func (s *SessionService) List(userID string, limit int) ([]*models.PracticeSession, error) {
+	if err := ValidateUserID(userID); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sessions := s.sessions[userID]
+	list := []*models.PracticeSession{}
+	for i := len(sessions) - 1; i >= 0 && (limit <= 0 || len(list) < limit); i-- {
+		list = append(list, copySession(sessions[i]))
+	}
+	return list, nil
+}
+
+// segmentKey 段落在統計中的識別
+type segmentKey struct {
+	fileID string
+	index  int
+}
+
+// Stats 統計最近 days 天（含今天）的練習時間、涵蓋的歌曲與段落、評分最低的段落，以及連續練習天數
+// 一次練習的時間與事件都計入開始的那一天，跨過午夜的練習不會把段落數分到隔天
+func (s *SessionService) Stats(userID string, days int) (*models.PracticeStats, error) {
+	if err := ValidateUserID(userID); err != nil {
+		return nil, err
+	}
+	if days < 1 || days > maxStatsDays {
+		return nil, ErrInvalidStatsDays
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from := today.AddDate(0, 0, -(days - 1))
+
+	stats := &models.PracticeStats{
+		UserID:  userID,
+		From:    from.Format(practiceDateLayout),
+		To:      today.Format(practiceDateLayout),
+		Days:    make([]models.DailyPractice, days),
+		Weakest: []models.WeakSegment{},
+	}
+	for i := range stats.Days {
+		stats.Days[i].Date = from.AddDate(0, 0, i).Format(practiceDateLayout)
+	}
+
+	s.mu.RLock()
+	sessions := s.sessions[userID]
+	practiced := make(map[string]bool) // 有練習的日期（全部紀錄，用於連續天數）
+	songs := make(map[string]bool)
+	segments := make(map[segmentKey]*models.WeakSegment)
+	daySegments := make([]map[segmentKey]bool, days)
+	for _, session := range sessions {
+		started := session.StartedAt.In(now.Location())
+		if len(session.Events) > 0 || session.Duration() > 0 {
+			practiced[started.Format(practiceDateLayout)] = true
+		}
+		if started.Before(from) {
+			continue
+		}
+		dayIdx := dayIndex(from, started, days)
+		day := &stats.Days[dayIdx]
+		day.Sessions++
+		day.Seconds += session.Duration().Seconds()
+		stats.Sessions++
+		stats.TotalSeconds += session.Duration().Seconds()
+
+		for _, e := range session.Events {
+			key := segmentKey{e.FileID, e.SegmentIndex}
+			seg := segments[key]
+			if seg == nil {
+				seg = &models.WeakSegment{FileID: e.FileID, SegmentIndex: e.SegmentIndex}
+				segments[key] = seg
+			}
+			switch e.Type {
+			case models.EventPlay, models.EventRepeat:
+				if e.Type == models.EventPlay {
+					seg.Plays++
+				} else {
+					seg.Repeats++
+				}
+				songs[e.FileID] = true
+				if daySegments[dayIdx] == nil {
+					daySegments[dayIdx] = make(map[segmentKey]bool)
+				}
+				daySegments[dayIdx][key] = true
+			case models.EventSkip:
+				seg.Skips++
+			case models.EventGrade:
+				seg.AverageGrade += float64(*e.Grade)
+				seg.Grades++
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	for i, keys := range daySegments {
+		stats.Days[i].Segments = len(keys)
+	}
+	stats.SongsCovered = len(songs)
+	for _, seg := range segments {
+		if seg.Plays+seg.Repeats > 0 {
+			stats.SegmentsCovered++
+		}
+	}
+	stats.CurrentStreak, stats.LongestStreak = practiceStreaks(practiced, today)
+	stats.Weakest = s.weakest(segments)
+	return stats, nil
+}
+
+// dayIndex 時間 t 在統計區間中是第幾天（超出範圍時取最近的一端）
+func dayIndex(from, t time.Time, days int) int {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, from.Location())
+	i := int(math.Round(day.Sub(from).Hours() / 24))
+	return min(max(i, 0), days-1)
+}
+
+// practiceStreaks 計算目前與最長的連續練習天數
+// 今天還沒練習時，目前的連續天數從昨天開始算
+func practiceStreaks(practiced map[string]bool, today time.Time) (current, longest int) {
+	day := today
+	if !practiced[day.Format(practiceDateLayout)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for practiced[day.Format(practiceDateLayout)] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	dates := make([]string, 0, len(practiced))
+	for date := range practiced {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	run := 0
+	var prev time.Time
+	for _, date := range dates {
+		d, err := time.ParseInLocation(practiceDateLayout, date, today.Location())
+		if err != nil {
+			continue
+		}
+		if run > 0 && d.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+		prev = d
+	}
+	return current, longest
+}
+
+// weakest 評分最低的段落：依平均評分由低到高，相同時重複與跳過次數多的在前
+// 已刪除的檔案或段落不列入
+func (s *SessionService) weakest(segments map[segmentKey]*models.WeakSegment) []models.WeakSegment {
+	var graded []models.WeakSegment
+	texts := make(map[string]*models.SegmentsData)
+	for key, seg := range segments {
+		if seg.Grades == 0 {
+			continue
+		}
+		file, err := s.fileService.GetFile(key.fileID)
+		if err != nil {
+			continue
+		}
+		data, ok := texts[key.fileID]
+		if !ok {
+			data, _ = s.processService.GetSegmentsData(key.fileID)
+			texts[key.fileID] = data
+		}
+		if data == nil || key.index >= len(data.Segments) {
+			continue
+		}
+
+		weak := *seg
+		weak.Filename = file.Filename
+		weak.Text = data.Segments[key.index].OriginalText
+		weak.AverageGrade = math.Round(seg.AverageGrade/float64(seg.Grades)*100) / 100
+		graded = append(graded, weak)
+	}
+
+	sort.Slice(graded, func(i, j int) bool {
+		a, b := graded[i], graded[j]
+		if a.AverageGrade != b.AverageGrade {
+			return a.AverageGrade < b.AverageGrade
+		}
+		if a.Repeats+a.Skips != b.Repeats+b.Skips {
+			return a.Repeats+a.Skips > b.Repeats+b.Skips
+		}
+		if a.FileID != b.FileID {
+			return a.FileID < b.FileID
+		}
+		return a.SegmentIndex < b.SegmentIndex
+	})
+	if len(graded) > maxWeakSegments {
+		graded = graded[:maxWeakSegments]
+	}
+	if graded == nil {
+		graded = []models.WeakSegment{}
+	}
+	return graded
+}
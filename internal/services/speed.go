@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"multilang-learner/internal/audio"
+	"multilang-learner/internal/models"
+)
+
+// 播放速度範圍（atempo 單一濾鏡支援 0.5~2.0）
+const (
+	minPlaybackSpeed = 0.5
+	maxPlaybackSpeed = 2.0
+)
+
+// 段落音訊種類
+const (
+	ClipOriginal = "original"
+	ClipTTS      = "tts"
+	ClipKaraoke  = "karaoke" // 人聲減弱的原曲段落
+)
+
+// 正在產生的衍生音檔路徑，產生完畢時關閉 channel
+var (
+	variantMu      sync.Mutex
+	variantPending = make(map[string]chan struct{})
+)
+
+// 段落音訊相關錯誤
+var (
+	ErrInvalidSpeed         = errors.New("播放速度必須介於 0.5 到 2.0")
+	ErrSegmentAudioNotFound = errors.New("段落音訊不存在")
+)
+
+// validateSpeed 檢查播放速度，0 代表未設定
+func validateSpeed(speed float64) error {
+	if speed == 0 {
+		return nil
+	}
+	if speed < minPlaybackSpeed || speed > maxPlaybackSpeed {
+		return ErrInvalidSpeed
+	}
+	return nil
+}
+
+// effectiveSpeed 依序採用段落設定、檔案設定，都未設定時為 1.0
+func effectiveSpeed(speeds ...float64) float64 {
+	for _, s := range speeds {
+		if s > 0 {
+			return s
+		}
+	}
+	return 1
+}
+
+// clipSpeed 計算段落音訊實際使用的速度
+func clipSpeed(file *models.MusicFile, seg *models.Segment, kind string) float64 {
+	if kind == ClipTTS {
+		return effectiveSpeed(file.Settings.TTSSpeed)
+	}
+	return effectiveSpeed(seg.Speed, file.Settings.PlaybackSpeed)
+}
+
+// speedVariant 取得變速版本的路徑，不存在時以 atempo 產生
+// 變速檔與原檔放在同一目錄，例如 segment_003_x0.75.mp3
+func speedVariant(path string, speed float64) (string, error) {
+	if speed == 1 {
+		return path, nil
+	}
+	ext := filepath.Ext(path)
+	variant := fmt.Sprintf("%s_x%.2f%s", strings.TrimSuffix(path, ext), speed, ext)
+	err := renderVariant(variant, func(tmp string) error {
+		return audio.NewProcessor(false).TimeStretch(path, tmp, speed)
+	})
+	if err != nil {
+		return "", err
+	}
+	return variant, nil
+}
+
+// karaokeVariant 取得人聲減弱的伴奏版本，不存在時產生
+// 伴奏檔與原檔放在同一目錄，例如 segment_003_karaoke.mp3
+func karaokeVariant(path string) (string, error) {
+	ext := filepath.Ext(path)
+	variant := strings.TrimSuffix(path, ext) + "_karaoke" + ext
+	err := renderVariant(variant, func(tmp string) error {
+		return audio.NewProcessor(false).RemoveVocals(path, tmp)
+	})
+	if err != nil {
+		return "", err
+	}
+	return variant, nil
+}
+
+// renderVariant 衍生音檔不存在時以 render 產生
+// 同一路徑同時只產生一次；先寫到同目錄的暫存檔再改名，寫到一半的檔案不會被讀到或留在快取中
+func renderVariant(variant string, render func(tmp string) error) error {
+	release, exists := reserveVariant(variant)
+	if exists {
+		return nil
+	}
+	defer release()
+
+	ext := filepath.Ext(variant)
+	tmp := strings.TrimSuffix(variant, ext) + ".tmp" + ext
+	if err := render(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, variant); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// reserveVariant 檔案已存在時回傳 exists；否則等候其他請求產生完畢，仍不存在時登記由呼叫者產生
+func reserveVariant(variant string) (release func(), exists bool) {
+	variantMu.Lock()
+	defer variantMu.Unlock()
+	for {
+		if _, err := os.Stat(variant); err == nil {
+			return nil, true
+		}
+		done, pending := variantPending[variant]
+		if !pending {
+			break
+		}
+		variantMu.Unlock()
+		<-done
+		variantMu.Lock()
+	}
+	done := make(chan struct{})
+	variantPending[variant] = done
+	return func() {
+		variantMu.Lock()
+		delete(variantPending, variant)
+		variantMu.Unlock()
+		close(done)
+	}, false
+}
+
+// removeVariants 刪除音檔的所有衍生版本（變速、伴奏），原檔重新產生時呼叫
+func removeVariants(path string) {
+	ext := filepath.Ext(path)
+	matches, _ := filepath.Glob(strings.TrimSuffix(path, ext) + "_*" + ext)
+	for _, m := range matches {
+		os.Remove(m)
+	}
+}
+
+// SegmentAudio 取得段落音訊（原曲、TTS 或伴奏），speed 為 0 時依段落與檔案設定決定速度
+func (s *ProcessService) SegmentAudio(fileID string, segIdx int, kind string, speed float64) (string, error) {
+	if err := validateSpeed(speed); err != nil {
+		return "", err
+	}
+	file, err := s.fileService.GetFile(fileID)
+	if err != nil {
+		return "", err
+	}
+	segments, err := s.GetSegmentsData(fileID)
+	if err != nil {
+		return "", err
+	}
+	if segIdx < 0 || segIdx >= len(segments.Segments) {
+		return "", fmt.Errorf("無效的段落索引: %d", segIdx)
+	}
+	seg := &segments.Segments[segIdx]
+
+	path := seg.AudioPath
+	if kind == ClipTTS {
+		path = seg.TTSPath
+	}
+	if path == "" {
+		return "", ErrSegmentAudioNotFound
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrSegmentAudioNotFound
+	}
+	if kind == ClipKaraoke {
+		if path, err = karaokeVariant(path); err != nil {
+			return "", err
+		}
+	}
+
+	if speed == 0 {
+		speed = clipSpeed(file, seg, kind)
+	}
+	return speedVariant(path, speed)
+}
+
+// SetSegmentSpeed 設定段落專屬播放速度，0 表示沿用檔案設定
+func (s *ProcessService) SetSegmentSpeed(fileID string, segIdx int, speed float64) error {
+	if err := validateSpeed(speed); err != nil {
+		return err
+	}
+	segments, err := s.GetSegmentsData(fileID)
+	if err != nil {
+		return err
+	}
+	if segIdx < 0 || segIdx >= len(segments.Segments) {
+		return fmt.Errorf("無效的段落索引: %d", segIdx)
+	}
+	segments.Segments[segIdx].Speed = speed
+	return s.saveSegments(fileID, segments)
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"multilang-learner/internal/exercise"
+	"multilang-learner/internal/models"
+	"multilang-learner/internal/translator"
+	"multilang-learner/internal/vocab"
+)
+
+// 詞彙表查詢的預設值與上限
+const (
+	DefaultVocabLimit = 100
+	MaxVocabLimit     = 1000
+	vocabBatchLines   = 15 // 每次請求模型分析的歌詞行數
+)
+
+var (
+	ErrVocabularyUnavailable = errors.New("GEMINI_API_KEY 未設定，無法擷取詞彙")
+	ErrVocabularyBusy        = errors.New("詞彙擷取進行中")
+	ErrNoVocabLines          = errors.New("沒有可以擷取詞彙的歌詞")
+	ErrGlossaryNotFound      = errors.New("尚未擷取詞彙")
+	ErrWordNotFound          = errors.New("找不到這個詞")
+	ErrInvalidVocabLimit     = errors.New("數量必須介於 1 到 1000")
+	ErrVocabularyFailed      = errors.New("詞彙分析失敗")
+)
+
+// VocabularyService 擷取歌詞詞彙並維護全域詞彙表
+// 每首歌的詞彙表存於 data/{file_id}/glossary.json；
+// 全域詞彙表由所有詞彙表合併而成，存於 data/vocabulary.json
+type VocabularyService struct {
+	dataDir      string
+	fileService  *FileService
+	lyricService *LyricService
+	apiKey       string
+	glossaries   map[string]*models.Glossary // fileID -> 詞彙表
+	words        []*models.VocabWord         // 全域詞彙表（依出現次數排序）
+	extracting   map[string]bool             // 正在擷取的檔案
+	mu           sync.RWMutex
+}
+
+// NewVocabularyService 建立詞彙服務
+func NewVocabularyService(dataDir string, fileService *FileService, lyricService *LyricService) *VocabularyService {
+	s := &VocabularyService{
+		dataDir:      dataDir,
+		fileService:  fileService,
+		lyricService: lyricService,
+		apiKey:       os.Getenv("GEMINI_API_KEY"),
+		glossaries:   make(map[string]*models.Glossary),
+		extracting:   make(map[string]bool),
+	}
+	s.load()
+	s.rebuild()
+	fileService.OnReset(s.RemoveFile)
+	return s
+}
+
+// load 載入各檔案的詞彙表
+func (s *VocabularyService) load() {
+	entries, err := os.ReadDir(s.dataDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dataDir, entry.Name(), "glossary.json"))
+		if err != nil {
+			continue
+		}
+		var glossary models.Glossary
+		if json.Unmarshal(data, &glossary) != nil {
+			continue
+		}
+		s.glossaries[entry.Name()] = &glossary
+	}
+}
+
+// Available 是否能擷取詞彙（需要 Gemini API key）
+func (s *VocabularyService) Available() bool {
+	return s.apiKey != ""
+}
+
+// Extract 擷取一首歌的詞彙：切詞後請模型標註原形、詞性與釋義，
+// 釋義語言依檔案的主要語言，完成後更新全域詞彙表
+func (s *VocabularyService) Extract(ctx context.Context, fileID string) (*models.Glossary, error) {
+	if s.apiKey == "" {
+		return nil, ErrVocabularyUnavailable
+	}
+	file, err := s.fileService.GetFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	lyrics, err := s.lyricService.GetLyricsData(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.extracting[fileID] {
+		s.mu.Unlock()
+		return nil, ErrVocabularyBusy
+	}
+	s.extracting[fileID] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.extracting, fileID)
+		s.mu.Unlock()
+	}()
+
+	var lines []models.LyricLine
+	var requests []translator.VocabLine
+	for _, line := range lyrics.GetActiveLyrics() {
+		tokens := vocab.Segment(line.Original)
+		if len(tokens) == 0 {
+			continue
+		}
+		lines = append(lines, line)
+		requests = append(requests, translator.VocabLine{Text: line.Original, Tokens: tokens})
+	}
+	if len(lines) == 0 {
+		return nil, ErrNoVocabLines
+	}
+
+	trans, err := translator.NewGeminiTranslator(s.apiKey, false)
+	if err != nil {
+		return nil, fmt.Errorf("建立翻譯器失敗: %w", err)
+	}
+	glossLang := "English"
+	if file.Settings.PrimaryLanguage == "zh" {
+		glossLang = "Traditional Chinese (繁體中文)"
+	}
+
+	glossary := &models.Glossary{
+		FileID:        fileID,
+		Language:      lyrics.DetectedLang,
+		GlossLanguage: file.Settings.PrimaryLanguage,
+		Lines:         make([]models.GlossaryLine, 0, len(lines)),
+	}
+	if glossary.Language == "" {
+		glossary.Language = file.Metadata.Language
+	}
+	for from := 0; from < len(lines); from += vocabBatchLines {
+		to := min(from+vocabBatchLines, len(lines))
+		results, err := trans.AnalyzeVocabulary(ctx, requests[from:to], glossLang)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrVocabularyFailed, err)
+		}
+		for i, words := range results {
+			line := lines[from+i]
+			entries := make([]models.VocabEntry, 0, len(words))
+			for _, w := range words {
+				entries = append(entries, models.VocabEntry{Surface: w.Surface, Lemma: w.Lemma, POS: w.POS, Gloss: w.Gloss})
+			}
+			glossary.Lines = append(glossary.Lines, models.GlossaryLine{LineIndex: line.Index, Original: line.Original, Words: entries})
+		}
+		// 稍微延遲避免 API 限流
+		time.Sleep(100 * time.Millisecond)
+	}
+	glossary.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(glossary, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(s.dataDir, fileID, "glossary.json"), data, 0644); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.glossaries[fileID] = glossary
+	s.mu.Unlock()
+	s.rebuild()
+	return glossary, nil
+}
+
+// Glossary 獲取一首歌的詞彙表
+func (s *VocabularyService) Glossary(fileID string) (*models.Glossary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	glossary, ok := s.glossaries[fileID]
+	if !ok {
+		return nil, ErrGlossaryNotFound
+	}
+	return glossary, nil
+}
+
+// List 列出全域詞彙表（依出現次數排序，不含出處）
+// lang 篩選歌詞語言，query 比對原形、出現形式與釋義；回傳符合的總數
+func (s *VocabularyService) List(lang, query string, limit int) ([]models.VocabWord, int, error) {
+	if limit < 1 || limit > MaxVocabLimit {
+		return nil, 0, ErrInvalidVocabLimit
+	}
+	query = exercise.Normalize(query)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := []models.VocabWord{}
+	total := 0
+	for _, w := range s.words {
+		if lang != "" && w.Language != lang {
+			continue
+		}
+		if query != "" && !vocabContains(w, query) {
+			continue
+		}
+		total++
+		if len(result) < limit {
+			word := *w
+			word.Occurrences = nil
+			result = append(result, word)
+		}
+	}
+	return result, total, nil
+}
+
+// Lookup 依原形或出現形式查詢一個詞在所有歌曲中的出處（可能有多種語言的同形詞）
+func (s *VocabularyService) Lookup(word string) ([]models.VocabWord, error) {
+	key := exercise.Normalize(word)
+	if key == "" {
+		return nil, ErrWordNotFound
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []models.VocabWord
+	for _, w := range s.words {
+		if exercise.Normalize(w.Lemma) == key || slices.ContainsFunc(w.Forms, func(f string) bool {
+			return exercise.Normalize(f) == key
+		}) {
+			result = append(result, *w)
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrWordNotFound
+	}
+	return result, nil
+}
+
+// RemoveFile 刪除檔案的詞彙表並更新全域詞彙表（刪除或取代檔案時呼叫）
+func (s *VocabularyService) RemoveFile(fileID string) {
+	s.mu.Lock()
+	_, ok := s.glossaries[fileID]
+	delete(s.glossaries, fileID)
+	s.mu.Unlock()
+	if ok {
+		s.rebuild()
+	}
+}
+
+// rebuild 由所有詞彙表重建全域詞彙表並寫入 data/vocabulary.json
+// 同語言且原形正規化後相同的詞合併為一筆
+func (s *VocabularyService) rebuild() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fileIDs := make([]string, 0, len(s.glossaries))
+	for id := range s.glossaries {
+		fileIDs = append(fileIDs, id)
+	}
+	sort.Strings(fileIDs)
+
+	index := make(map[string]*models.VocabWord)
+	var words []*models.VocabWord
+	for _, fileID := range fileIDs {
+		glossary := s.glossaries[fileID]
+		title := ""
+		if file, err := s.fileService.GetFile(fileID); err == nil {
+			title = file.Metadata.Title
+			if title == "" {
+				title = file.Filename
+			}
+		}
+		for _, line := range glossary.Lines {
+			for _, entry := range line.Words {
+				lemma := exercise.Normalize(entry.Lemma)
+				if lemma == "" {
+					continue
+				}
+				key := glossary.Language + "\x00" + lemma
+				w, ok := index[key]
+				if !ok {
+					w = &models.VocabWord{Lemma: entry.Lemma, Language: glossary.Language}
+					index[key] = w
+					words = append(words, w)
+				}
+				w.POS = appendUnique(w.POS, entry.POS)
+				w.Glosses = appendUnique(w.Glosses, entry.Gloss)
+				w.Forms = appendUnique(w.Forms, entry.Surface)
+				w.Count++
+				if n := len(w.Occurrences); n == 0 || w.Occurrences[n-1].FileID != fileID {
+					w.SongCount++
+				}
+				w.Occurrences = append(w.Occurrences, models.VocabOccurrence{
+					FileID:    fileID,
+					Title:     title,
+					LineIndex: line.LineIndex,
+					Surface:   entry.Surface,
+					Line:      line.Original,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		if words[i].SongCount != words[j].SongCount {
+			return words[i].SongCount > words[j].SongCount
+		}
+		return words[i].Lemma < words[j].Lemma
+	})
+	s.words = words
+
+	if data, err := json.MarshalIndent(words, "", "  "); err == nil {
+		os.WriteFile(filepath.Join(s.dataDir, "vocabulary.json"), data, 0644)
+	}
+}
+
+// vocabContains 詞的原形、出現形式或釋義是否包含 query（已正規化）
+func vocabContains(w *models.VocabWord, query string) bool {
+	if strings.Contains(exercise.Normalize(w.Lemma), query) {
+		return true
+	}
+	for _, s := range append(slices.Clone(w.Forms), w.Glosses...) {
+		if strings.Contains(exercise.Normalize(s), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique 加入非空且尚未出現的字串
+func appendUnique(list []string, s string) []string {
+	if s == "" || slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
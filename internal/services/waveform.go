@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"multilang-learner/internal/audio"
+	"multilang-learner/internal/models"
+)
+
+// 波形峰值的取樣率與點數
+const (
+	waveformSampleRate    = 8000
+	defaultWaveformPoints = 1000
+	maxWaveformPoints     = 10000
+)
+
+// ErrInvalidPoints 波形點數超出範圍
+var ErrInvalidPoints = errors.New("波形點數必須介於 1 到 10000")
+
+// waveformCache 快取的波形峰值，來源檔案的大小或修改時間改變時重新計算
+type waveformCache struct {
+	SourceSize    int64     `json:"sourceSize"`
+	SourceModTime time.Time `json:"sourceModTime"`
+	Duration      float64   `json:"duration"`
+	Peaks         []float32 `json:"peaks"`
+}
+
+// Waveform 取得整首原曲（segIdx < 0）或段落音訊的波形峰值
+// 峰值計算一次後快取在 data/{file_id}/waveform/；整首原曲另外附上段落邊界與歌詞標記
+func (s *ProcessService) Waveform(fileID string, segIdx int, kind string, points int) (*models.Waveform, error) {
+	if points == 0 {
+		points = defaultWaveformPoints
+	}
+	if points < 1 || points > maxWaveformPoints {
+		return nil, ErrInvalidPoints
+	}
+
+	path, err := s.analysisPath(fileID, segIdx, kind)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := s.waveformPeaks(fileID, path, points)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.Waveform{
+		Duration: cache.Duration,
+		Points:   len(cache.Peaks) / 2,
+		Peaks:    cache.Peaks,
+	}
+	if segIdx < 0 {
+		if segments, err := s.GetSegmentsData(fileID); err == nil {
+			for _, seg := range segments.Segments {
+				result.Segments = append(result.Segments, models.TimeRange{Start: seg.StartTime, End: seg.EndTime})
+			}
+		}
+		if lyrics, err := s.lyricService.GetLyricsData(fileID); err == nil {
+			for _, line := range lyrics.Lines {
+				if !line.IsMeaningful {
+					continue
+				}
+				result.Markers = append(result.Markers, models.LyricMarker{
+					Index:   line.Index,
+					Time:    line.StartTime,
+					Text:    line.Original,
+					Skipped: line.IsSkipped,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// waveformPeaks 讀取快取的峰值，沒有或已過期時重新計算
+func (s *ProcessService) waveformPeaks(fileID, path string, points int) (*waveformCache, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, ErrSegmentAudioNotFound
+	}
+
+	cacheDir := filepath.Join(s.dataDir, fileID, "waveform")
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	cachePath := filepath.Join(cacheDir, fmt.Sprintf("%s_%d.json", name, points))
+
+	var cache waveformCache
+	if data, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(data, &cache) == nil &&
+		cache.SourceSize == info.Size() && cache.SourceModTime.Equal(info.ModTime()) {
+		return &cache, nil
+	}
+
+	buf, err := audio.NewProcessor(false).DecodePCM(context.Background(), path, waveformSampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	cache = waveformCache{
+		SourceSize:    info.Size(),
+		SourceModTime: info.ModTime(),
+		Duration:      buf.Duration().Seconds(),
+		Peaks:         []float32{},
+	}
+	for _, b := range buf.Waveform(points) {
+		cache.Peaks = append(cache.Peaks, roundPeak(b.Min), roundPeak(b.Max))
+	}
+
+	// 先寫暫存檔再改名，避免同時請求讀到寫一半的快取
+	if err := os.MkdirAll(cacheDir, 0755); err == nil {
+		if data, err := json.Marshal(cache); err == nil {
+			tmpPath := cachePath + ".tmp"
+			if os.WriteFile(tmpPath, data, 0644) == nil {
+				os.Rename(tmpPath, cachePath)
+			}
+		}
+	}
+	return &cache, nil
+}
+
+// roundPeak 峰值只保留三位小數，縮小 JSON
+func roundPeak(v float32) float32 {
+	return float32(math.Round(float64(v)*1000) / 1000)
+}
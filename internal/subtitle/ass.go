@@ -0,0 +1,52 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GenerateASS 產生 ASS 字幕，原文顯示在下方較大的字，翻譯顯示在原文上方
+// width、height 為影片解析度
+func GenerateASS(title string, lines []Line, width, height int) string {
+	var sb strings.Builder
+	sb.WriteString("[Script Info]\n")
+	sb.WriteString(fmt.Sprintf("Title: %s\n", assEscape(title)))
+	sb.WriteString("ScriptType: v4.00+\n")
+	sb.WriteString("WrapStyle: 0\n")
+	sb.WriteString(fmt.Sprintf("PlayResX: %d\nPlayResY: %d\n\n", width, height))
+
+	originalSize := height / 15
+	translationSize := height / 20
+	sb.WriteString("[V4+ Styles]\n")
+	sb.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	sb.WriteString(fmt.Sprintf("Style: Original,Noto Sans CJK TC,%d,&H00FFFFFF,&H00FFFFFF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,3,1,2,60,60,%d,1\n", originalSize, height/12))
+	sb.WriteString(fmt.Sprintf("Style: Translation,Noto Sans CJK TC,%d,&H0000D7FF,&H0000D7FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,1,2,60,60,%d,1\n\n", translationSize, height/12+originalSize*2))
+
+	sb.WriteString("[Events]\n")
+	sb.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, line := range lines {
+		start, end := formatASSTime(line.StartTime), formatASSTime(line.EndTime)
+		if line.Text != "" {
+			sb.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Original,,0,0,0,,%s\n", start, end, assEscape(line.Text)))
+		}
+		if line.Translation != "" {
+			sb.WriteString(fmt.Sprintf("Dialogue: 0,%s,%s,Translation,,0,0,0,,%s\n", start, end, assEscape(line.Translation)))
+		}
+	}
+	return sb.String()
+}
+
+// formatASSTime 格式化為 ASS 時間 h:mm:ss.cc
+func formatASSTime(d time.Duration) string {
+	cs := d.Milliseconds() / 10
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
+
+// assEscape 將換行轉為 ASS 換行符號，並避免大括號被當成覆寫標籤
+func assEscape(s string) string {
+	s = strings.ReplaceAll(s, "{", "(")
+	s = strings.ReplaceAll(s, "}", ")")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", `\N`)
+}
@@ -15,13 +15,37 @@ type Line struct {
 	EndTime     time.Duration
 	Text        string
 	Translation string
+	Extra       []string // 同一時間戳的第三行以後（例如羅馬拼音）
+}
+
+// Texts 回傳同一時間戳的所有文字（依出現順序）
+func (l Line) Texts() []string {
+	texts := []string{l.Text}
+	if l.Translation != "" {
+		texts = append(texts, l.Translation)
+	}
+	return append(texts, l.Extra...)
+}
+
+// Warning 解析警告（不影響解析結果，但代表某些內容被忽略或修正）
+type Warning struct {
+	Line    int    // 原始內容的行號（1-based）
+	Content string // 原始行內容
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("line %d: %s (%q)", w.Line, w.Message, w.Content)
 }
 
 type Lyrics struct {
-	Title  string
-	Artist string
-	Album  string
-	Lines  []Line
+	Title    string
+	Artist   string
+	Album    string
+	By       string
+	Offset   time.Duration // [offset:] 標籤的值，已套用到所有時間戳
+	Lines    []Line
+	Warnings []Warning
 }
 
 type Parser struct{}
@@ -30,15 +54,18 @@ func NewParser() *Parser {
 	return &Parser{}
 }
 
+var (
+	tagRe  = regexp.MustCompile(`^\[([^\[\]]*)\]`)
+	timeRe = regexp.MustCompile(`^(\d{1,3}):(\d{1,2})(?:[\.:](\d{1,3}))?$`)
+	metaRe = regexp.MustCompile(`^([A-Za-z]+):(.*)$`)
+)
+
 func (p *Parser) Parse(content string) (*Lyrics, error) {
 	// 預處理：如果內容包含 | 分隔的多個時間戳，先展開
 	content = p.expandPipeSeparatedLyrics(content)
+	content = strings.TrimPrefix(content, "\ufeff")
 
 	result := &Lyrics{}
-	lines := strings.Split(content, "\n")
-
-	metaRe := regexp.MustCompile(`^\[(\w+):(.+)\]$`)
-	timeRe := regexp.MustCompile(`^\[(\d{1,2}):(\d{2})(?:[\.:](\d{2,3}))?\](.*)$`)
 
 	type rawLine struct {
 		time  time.Duration
@@ -46,42 +73,85 @@ func (p *Parser) Parse(content string) (*Lyrics, error) {
 	}
 	var rawLines []rawLine
 	timeMap := make(map[time.Duration]int)
+	// 空白時間戳（例如 [01:02.00] 後面沒有文字）只用來標記上一句的結束時間
+	var breaks []time.Duration
+	var offsetMs int
+
+	warn := func(lineNo int, line, msg string) {
+		result.Warnings = append(result.Warnings, Warning{Line: lineNo, Content: line, Message: msg})
+	}
 
-	for _, line := range lines {
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
 
-		if metaMatch := metaRe.FindStringSubmatch(line); metaMatch != nil {
-			tag := strings.ToLower(metaMatch[1])
-			value := strings.TrimSpace(metaMatch[2])
-			switch tag {
-			case "ti", "title":
-				result.Title = value
-			case "ar", "artist":
-				result.Artist = value
-			case "al", "album":
-				result.Album = value
+		// 取出行首所有 [...] 標籤，支援 [00:01.00][00:30.00]副歌 這種多時間戳寫法；
+		// 時間戳後的第一個非時間戳標籤起都算歌詞，例如 [00:12.00][chorus]text
+		var times []time.Duration
+		rest := line
+		isMeta, invalid := false, false
+		for {
+			m := tagRe.FindStringSubmatch(rest)
+			if m == nil {
+				break
 			}
+			tag := strings.TrimSpace(m[1])
+			if t, ok := parseTimeTag(tag); ok {
+				times = append(times, t)
+				rest = strings.TrimSpace(rest[len(m[0]):])
+				continue
+			}
+			if len(times) > 0 {
+				break
+			}
+			if meta := metaRe.FindStringSubmatch(tag); meta != nil {
+				isMeta = true
+				if strings.TrimSpace(rest[len(m[0]):]) != "" {
+					warn(lineNo, line, "metadata tag followed by text, text ignored")
+				}
+				key := strings.ToLower(meta[1])
+				value := strings.TrimSpace(meta[2])
+				switch key {
+				case "ti", "title":
+					result.Title = value
+				case "ar", "artist":
+					result.Artist = value
+				case "al", "album":
+					result.Album = value
+				case "by":
+					result.By = value
+				case "offset":
+					ms, err := strconv.Atoi(strings.TrimPrefix(value, "+"))
+					if err != nil {
+						warn(lineNo, line, "invalid offset value")
+					} else {
+						offsetMs = ms
+					}
+				}
+				break
+			}
+			warn(lineNo, line, fmt.Sprintf("unrecognized tag [%s]", tag))
+			invalid = true
+			break
+		}
+
+		if isMeta || invalid {
+			continue
+		}
+		if len(times) == 0 {
+			warn(lineNo, line, "line has no timestamp, ignored")
 			continue
 		}
 
-		if timeMatch := timeRe.FindStringSubmatch(line); timeMatch != nil {
-			min, _ := strconv.Atoi(timeMatch[1])
-			sec, _ := strconv.Atoi(timeMatch[2])
-			ms := 0
-			if timeMatch[3] != "" {
-				ms, _ = strconv.Atoi(timeMatch[3])
-				if len(timeMatch[3]) == 2 {
-					ms *= 10
-				}
-			}
-			t := time.Duration(min)*time.Minute + time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond
-			text := strings.TrimSpace(timeMatch[4])
-			if text == "" || text == "//" {
-				continue
-			}
+		text := rest
+		if text == "" || text == "//" {
+			breaks = append(breaks, times...)
+			continue
+		}
+		for _, t := range times {
 			if idx, exists := timeMap[t]; exists {
 				rawLines[idx].texts = append(rawLines[idx].texts, text)
 			} else {
@@ -91,26 +161,73 @@ func (p *Parser) Parse(content string) (*Lyrics, error) {
 		}
 	}
 
-	sort.Slice(rawLines, func(i, j int) bool { return rawLines[i].time < rawLines[j].time })
+	// 套用 [offset:]：正值代表歌詞提前顯示
+	if offsetMs != 0 {
+		result.Offset = time.Duration(offsetMs) * time.Millisecond
+		shift := func(t time.Duration) time.Duration {
+			t -= result.Offset
+			if t < 0 {
+				return 0
+			}
+			return t
+		}
+		for i := range rawLines {
+			rawLines[i].time = shift(rawLines[i].time)
+		}
+		for i := range breaks {
+			breaks[i] = shift(breaks[i])
+		}
+	}
+
+	sort.SliceStable(rawLines, func(i, j int) bool { return rawLines[i].time < rawLines[j].time })
+	sort.Slice(breaks, func(i, j int) bool { return breaks[i] < breaks[j] })
 
 	for i, raw := range rawLines {
-		l := Line{StartTime: raw.time}
-		if len(raw.texts) > 0 {
-			l.Text = raw.texts[0]
-		}
+		l := Line{StartTime: raw.time, Text: raw.texts[0]}
 		if len(raw.texts) > 1 {
 			l.Translation = raw.texts[1]
 		}
+		if len(raw.texts) > 2 {
+			l.Extra = raw.texts[2:]
+		}
 		if i < len(rawLines)-1 {
 			l.EndTime = rawLines[i+1].time
 		} else {
 			l.EndTime = raw.time + 5*time.Second
 		}
+		// 如果中間有空白時間戳，提早結束
+		if b := sort.Search(len(breaks), func(k int) bool { return breaks[k] > raw.time }); b < len(breaks) && breaks[b] < l.EndTime {
+			l.EndTime = breaks[b]
+		}
 		result.Lines = append(result.Lines, l)
 	}
 	return result, nil
 }
 
+// parseTimeTag 解析 mm:ss、mm:ss.xx、mm:ss.xxx 與 mm:ss:xx 格式的時間標籤
+func parseTimeTag(tag string) (time.Duration, bool) {
+	m := timeRe.FindStringSubmatch(tag)
+	if m == nil {
+		return 0, false
+	}
+	min, _ := strconv.Atoi(m[1])
+	sec, _ := strconv.Atoi(m[2])
+	if sec >= 60 {
+		return 0, false
+	}
+	ms := 0
+	if m[3] != "" {
+		ms, _ = strconv.Atoi(m[3])
+		switch len(m[3]) {
+		case 1:
+			ms *= 100
+		case 2:
+			ms *= 10
+		}
+	}
+	return time.Duration(min)*time.Minute + time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond, true
+}
+
 // expandPipeSeparatedLyrics 展開用 | 分隔的歌詞格式
 // 例如: "[00:00.000] 作词 : xxx|[00:01.000] 作曲 : xxx|[00:02.243]歌詞內容"
 // 會被展開成多行
@@ -153,13 +270,22 @@ func (p *Parser) GenerateEnhancedLRC(lyrics *Lyrics) string {
 	if lyrics.Artist != "" {
 		sb.WriteString(fmt.Sprintf("[ar:%s]\n", lyrics.Artist))
 	}
+	if lyrics.Album != "" {
+		sb.WriteString(fmt.Sprintf("[al:%s]\n", lyrics.Album))
+	}
+	if lyrics.By != "" {
+		sb.WriteString(fmt.Sprintf("[by:%s]\n", lyrics.By))
+	}
 	sb.WriteString("\n")
 	for _, line := range lyrics.Lines {
-		ts := formatTime(line.StartTime)
+		ts := FormatTime(line.StartTime)
 		sb.WriteString(fmt.Sprintf("[%s]%s\n", ts, line.Text))
 		if line.Translation != "" {
 			sb.WriteString(fmt.Sprintf("[%s]%s\n", ts, line.Translation))
 		}
+		for _, extra := range line.Extra {
+			sb.WriteString(fmt.Sprintf("[%s]%s\n", ts, extra))
+		}
 	}
 	return sb.String()
 }
@@ -172,7 +298,8 @@ func (p *Parser) ParseFile(path string) (*Lyrics, error) {
 	return p.Parse(string(data))
 }
 
-func formatTime(d time.Duration) string {
+// FormatTime 格式化為 LRC 時間戳 mm:ss.xx
+func FormatTime(d time.Duration) string {
 	min := int(d.Minutes())
 	sec := int(d.Seconds()) % 60
 	ms := int(d.Milliseconds()) % 1000 / 10
@@ -0,0 +1,167 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ms(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		offset   time.Duration
+		lines    []Line
+		warnings []string // Warning.String()
+	}{
+		{
+			name:    "metadata and end times",
+			content: "\ufeff[ti:Song]\n[ar:Singer]\n[00:01.00]one\n[00:03.50]two\n",
+			lines: []Line{
+				{StartTime: ms(1000), EndTime: ms(3500), Text: "one"},
+				{StartTime: ms(3500), EndTime: ms(8500), Text: "two"},
+			},
+		},
+		{
+			name:    "timestamp formats",
+			content: "[00:01]a\n[00:02.5]b\n[00:03.25]c\n[00:04.125]d\n[00:05:50]e\n[1:06.00]f\n",
+			lines: []Line{
+				{StartTime: ms(1000), EndTime: ms(2500), Text: "a"},
+				{StartTime: ms(2500), EndTime: ms(3250), Text: "b"},
+				{StartTime: ms(3250), EndTime: ms(4125), Text: "c"},
+				{StartTime: ms(4125), EndTime: ms(5500), Text: "d"},
+				{StartTime: ms(5500), EndTime: ms(66000), Text: "e"},
+				{StartTime: ms(66000), EndTime: ms(71000), Text: "f"},
+			},
+		},
+		{
+			name:    "positive offset shows lyrics earlier and clamps at zero",
+			content: "[offset:+500]\n[00:00.20]a\n[00:02.00]b\n",
+			offset:  ms(500),
+			lines: []Line{
+				{StartTime: 0, EndTime: ms(1500), Text: "a"},
+				{StartTime: ms(1500), EndTime: ms(6500), Text: "b"},
+			},
+		},
+		{
+			name:    "negative offset shows lyrics later",
+			content: "[offset:-250]\n[00:01.00]a\n",
+			offset:  ms(-250),
+			lines: []Line{
+				{StartTime: ms(1250), EndTime: ms(6250), Text: "a"},
+			},
+		},
+		{
+			name:    "multiple timestamps on one line",
+			content: "[00:01.00][00:10.00]chorus\n[00:05.00]verse\n",
+			lines: []Line{
+				{StartTime: ms(1000), EndTime: ms(5000), Text: "chorus"},
+				{StartTime: ms(5000), EndTime: ms(10000), Text: "verse"},
+				{StartTime: ms(10000), EndTime: ms(15000), Text: "chorus"},
+			},
+		},
+		{
+			name:    "lines sharing a timestamp",
+			content: "[00:01.00]こんにちは\n[00:01.00]你好\n[00:01.00]konnichiwa\n[00:01.00]extra\n",
+			lines: []Line{
+				{StartTime: ms(1000), EndTime: ms(6000), Text: "こんにちは", Translation: "你好", Extra: []string{"konnichiwa", "extra"}},
+			},
+		},
+		{
+			name:    "empty timestamp ends the previous line",
+			content: "[00:01.00]a\n[00:02.00]\n[00:05.00]b\n",
+			lines: []Line{
+				{StartTime: ms(1000), EndTime: ms(2000), Text: "a"},
+				{StartTime: ms(5000), EndTime: ms(10000), Text: "b"},
+			},
+		},
+		{
+			name:    "pipe separated lyrics",
+			content: "[00:00.000] 作词 : A|[00:01.000] 作曲 : B|[00:02.000]歌詞",
+			lines: []Line{
+				{StartTime: 0, EndTime: ms(1000), Text: "作词 : A"},
+				{StartTime: ms(1000), EndTime: ms(2000), Text: "作曲 : B"},
+				{StartTime: ms(2000), EndTime: ms(7000), Text: "歌詞"},
+			},
+		},
+		{
+			name:    "bracket after timestamp is lyric text",
+			content: "[00:12.00][chorus]text\n[00:15.00][ar:x] y\n",
+			lines: []Line{
+				{StartTime: ms(12000), EndTime: ms(15000), Text: "[chorus]text"},
+				{StartTime: ms(15000), EndTime: ms(20000), Text: "[ar:x] y"},
+			},
+		},
+		{
+			name:    "warnings",
+			content: "[ti:Song] extra\n[offset:abc]\n[foo]bar\nno timestamp\n[00:01.00]a\n",
+			lines: []Line{
+				{StartTime: ms(1000), EndTime: ms(6000), Text: "a"},
+			},
+			warnings: []string{
+				`line 1: metadata tag followed by text, text ignored ("[ti:Song] extra")`,
+				`line 2: invalid offset value ("[offset:abc]")`,
+				`line 3: unrecognized tag [foo] ("[foo]bar")`,
+				`line 4: line has no timestamp, ignored ("no timestamp")`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewParser().Parse(tt.content)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got.Offset != tt.offset {
+				t.Errorf("Offset = %v, want %v", got.Offset, tt.offset)
+			}
+			if !reflect.DeepEqual(got.Lines, tt.lines) {
+				t.Errorf("Lines =\n%+v\nwant\n%+v", got.Lines, tt.lines)
+			}
+			var warnings []string
+			for _, w := range got.Warnings {
+				warnings = append(warnings, w.String())
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("Warnings =\n%q\nwant\n%q", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	got, err := NewParser().Parse("[ti:Song]\n[ar:Singer]\n[al:Album]\n[by:Me]\n[00:01.00]a\n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got.Title != "Song" || got.Artist != "Singer" || got.Album != "Album" || got.By != "Me" {
+		t.Errorf("metadata = %q %q %q %q", got.Title, got.Artist, got.Album, got.By)
+	}
+}
+
+func TestParseTimeTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want time.Duration
+		ok   bool
+	}{
+		{"00:01", ms(1000), true},
+		{"01:02.3", ms(62300), true},
+		{"01:02.34", ms(62340), true},
+		{"01:02.345", ms(62345), true},
+		{"01:02:34", ms(62340), true},
+		{"100:00.00", 100 * time.Minute, true},
+		{"00:60.00", 0, false},
+		{"chorus", 0, false},
+		{"ar:x", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTimeTag(tt.tag)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseTimeTag(%q) = %v, %v; want %v, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// romanizeInstructions 各語言的轉寫規則
+var romanizeInstructions = map[string]string{
+	"ja": "Use Hepburn romaji. Separate words with spaces and write the particles は, を, へ as wa, o, e.",
+	"zh": "Use Hanyu Pinyin with tone marks (nǐ hǎo), one space between syllables.",
+	"ko": "Use the Revised Romanization of Korean, keeping the original word spacing.",
+	"ru": "Use a simple Latin transliteration without diacritics (Я тебя люблю → Ya tebya lyublyu).",
+}
+
+// Romanize 將歌詞原文轉寫成拉丁字母（羅馬拼音），結果與 texts 一一對應
+// lang 為原文語言，決定使用的轉寫系統
+func (g *GeminiTranslator) Romanize(ctx context.Context, texts []string, lang string) ([]string, error) {
+	if len(texts) == 0 {
+		return []string{}, nil
+	}
+	var sb strings.Builder
+	for i, t := range texts {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, t))
+	}
+	instruction, ok := romanizeInstructions[lang]
+	if !ok {
+		instruction = "Use the standard Latin transliteration for the language."
+	}
+	prompt := fmt.Sprintf(`Romanize each song lyric line (write its pronunciation in Latin letters; do NOT translate).
+%s Keep punctuation. Output only the romanization in the same numbered format:
+%s`, instruction, sb.String())
+	url := fmt.Sprintf("%s/models/gemini-2.0-flash:generateContent?key=%s", g.baseURL, g.apiKey)
+	req := transReq{Contents: []content{{Parts: []part{{Text: prompt}}}}, GenCfg: genConfig{Temperature: 0.1, MaxTokens: len(texts) * 150}}
+	jsonData, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	var transR transResp
+	if err := json.Unmarshal(body, &transR); err != nil {
+		return nil, err
+	}
+	if transR.Error != nil {
+		return nil, fmt.Errorf("API error: %s", transR.Error.Message)
+	}
+	if len(transR.Candidates) > 0 && len(transR.Candidates[0].Content.Parts) > 0 {
+		return parseNumbered(transR.Candidates[0].Content.Parts[0].Text, len(texts))
+	}
+	return nil, fmt.Errorf("no romanization")
+}
@@ -0,0 +1,125 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WordInfo 單字的原形、詞性與簡短釋義
+type WordInfo struct {
+	Surface string `json:"surface"` // 歌詞中出現的形式
+	Lemma   string `json:"lemma"`   // 辭典形（原形）
+	POS     string `json:"pos"`     // 詞性
+	Gloss   string `json:"gloss"`   // 釋義
+}
+
+// VocabLine 待分析的一句歌詞與預先切好的詞
+type VocabLine struct {
+	Text   string
+	Tokens []string
+}
+
+// AnalyzeVocabulary 為每句歌詞的詞查詢原形、詞性與釋義，結果與 lines 一一對應
+// 預先切好的詞若包含多個單字（例如未分詞的中文），模型會再拆開
+func (g *GeminiTranslator) AnalyzeVocabulary(ctx context.Context, lines []VocabLine, glossLang string) ([][]WordInfo, error) {
+	if len(lines) == 0 {
+		return [][]WordInfo{}, nil
+	}
+
+	var sb strings.Builder
+	for i, line := range lines {
+		tokens, _ := json.Marshal(line.Tokens)
+		sb.WriteString(fmt.Sprintf("%d. %s\n   tokens: %s\n", i+1, line.Text, tokens))
+	}
+	prompt := fmt.Sprintf(`You are a linguist building a vocabulary list from song lyrics.
+
+For each numbered line, analyze the given tokens in order.
+
+Rules:
+1. Return one entry per word; if a token contains several words (e.g. unsegmented Chinese), split it into words
+2. "surface" is the word exactly as it appears in the line
+3. "lemma" is the dictionary form (infinitive, singular, plain form)
+4. "pos" is one of: noun, verb, adjective, adverb, pronoun, particle, preposition, conjunction, auxiliary, determiner, numeral, interjection, other
+5. "gloss" is a short meaning in %s (a few words at most)
+6. Skip punctuation and vocal sounds such as "oh", "la la"
+7. Output ONLY JSON in this format:
+{"lines":[{"n":1,"words":[{"surface":"...","lemma":"...","pos":"...","gloss":"..."}]}]}
+
+Lines:
+%s`, glossLang, sb.String())
+
+	url := fmt.Sprintf("%s/models/gemini-2.0-flash:generateContent?key=%s", g.baseURL, g.apiKey)
+	req := transReq{
+		Contents: []content{{Parts: []part{{Text: prompt}}}},
+		GenCfg:   genConfig{Temperature: 0.2, MaxTokens: len(lines) * 400},
+	}
+
+	jsonData, _ := json.Marshal(req)
+	httpReq, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	var transR transResp
+	if err := json.Unmarshal(body, &transR); err != nil {
+		return nil, err
+	}
+	if transR.Error != nil {
+		return nil, fmt.Errorf("API error: %s", transR.Error.Message)
+	}
+	if len(transR.Candidates) == 0 || len(transR.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no vocabulary")
+	}
+
+	return parseVocabulary(transR.Candidates[0].Content.Parts[0].Text, len(lines))
+}
+
+// parseVocabulary 解析模型回傳的 JSON（可能包在 markdown 區塊中）
+func parseVocabulary(response string, count int) ([][]WordInfo, error) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("no JSON in vocabulary response")
+	}
+
+	var parsed struct {
+		Lines []struct {
+			N     int        `json:"n"`
+			Words []WordInfo `json:"words"`
+		} `json:"lines"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("parse vocabulary response: %w", err)
+	}
+
+	results := make([][]WordInfo, count)
+	for _, line := range parsed.Lines {
+		if line.N < 1 || line.N > count {
+			continue
+		}
+		for _, w := range line.Words {
+			w.Surface = strings.TrimSpace(w.Surface)
+			w.Lemma = strings.TrimSpace(w.Lemma)
+			w.POS = strings.ToLower(strings.TrimSpace(w.POS))
+			w.Gloss = strings.TrimSpace(w.Gloss)
+			if w.Surface == "" {
+				continue
+			}
+			if w.Lemma == "" {
+				w.Lemma = w.Surface
+			}
+			results[line.N-1] = append(results[line.N-1], w)
+		}
+	}
+	return results, nil
+}
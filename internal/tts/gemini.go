@@ -10,8 +10,9 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+
+	"multilang-learner/internal/audio"
 )
 
 type GeminiTTS struct {
@@ -74,29 +75,36 @@ type inlineData struct {
 
 // GenerateSpeech generates speech and saves as MP3
 func (g *GeminiTTS) GenerateSpeech(ctx context.Context, text string, outputPath string) error {
-	pcmData, err := g.generatePCM(ctx, text)
-	if err != nil {
-		return err
-	}
-
-	os.MkdirAll(filepath.Dir(outputPath), 0755)
-
 	// Save as WAV first
 	wavPath := outputPath + ".wav"
-	if err := writeWAV(wavPath, pcmData, 1, 24000, 16); err != nil {
-		return fmt.Errorf("write WAV: %w", err)
+	if err := g.GenerateWAV(ctx, text, wavPath); err != nil {
+		return err
 	}
 	defer os.Remove(wavPath)
 
 	// Convert to MP3
-	cmd := exec.Command("ffmpeg", "-y", "-i", wavPath, "-acodec", "libmp3lame", "-ar", "44100", "-ac", "2", "-b:a", "192k", outputPath)
-	if err := cmd.Run(); err != nil {
+	if err := audio.NewRunner(g.verbose).FFmpeg(ctx, "-y", "-i", wavPath, "-acodec", "libmp3lame", "-ar", "44100", "-ac", "2", "-b:a", "192k", outputPath); err != nil {
 		return fmt.Errorf("ffmpeg convert: %w", err)
 	}
 
 	return nil
 }
 
+// GenerateWAV generates speech and saves the raw 24 kHz mono output as WAV,
+// so callers that analyze or re-encode it can skip one MP3 round trip
+func (g *GeminiTTS) GenerateWAV(ctx context.Context, text string, outputPath string) error {
+	pcmData, err := g.generatePCM(ctx, text)
+	if err != nil {
+		return err
+	}
+
+	os.MkdirAll(filepath.Dir(outputPath), 0755)
+	if err := writeWAV(outputPath, pcmData, 1, 24000, 16); err != nil {
+		return fmt.Errorf("write WAV: %w", err)
+	}
+	return nil
+}
+
 func (g *GeminiTTS) generatePCM(ctx context.Context, text string) ([]byte, error) {
 	url := fmt.Sprintf("%s/models/gemini-2.5-flash-preview-tts:generateContent?key=%s", g.baseURL, g.apiKey)
 	req := ttsReq{
@@ -0,0 +1,129 @@
+// Package vocab 將歌詞切成詞，供詞彙表查詢原形與釋義
+package vocab
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"multilang-learner/internal/exercise"
+)
+
+// leadingParticles 出現在平假名段開頭時切開的助詞（長的在前）
+var leadingParticles = []string{"から", "まで", "より", "は", "が", "を", "に", "で", "と", "へ", "も", "の"}
+
+// okuriganaStops 漢字後的送假名遇到這些助詞就結束
+var okuriganaStops = []string{"から", "まで", "より", "けど", "ので", "のに", "は", "が", "を", "に", "で", "と", "へ", "も", "の"}
+
+// verbSuffixes 接在動詞連用形後的語尾，前面的 に 是活用的一部分（死にたい、生きにくい）而不是助詞
+var verbSuffixes = []string{"たい", "たく", "たかっ", "ます", "まし", "ません", "ながら", "そう", "にくい"}
+
+// Segment 將一句歌詞切成詞（不含標點，依出現順序）
+//   - 以空白分詞的語言直接依空白與標點切開
+//   - 日文（含假名）將漢字與後面的送假名合成一詞，並切出常見助詞
+//   - 中文（只有漢字）以內建詞表正向最長比對切詞，詞表沒有的字各自成詞
+func Segment(text string) []string {
+	tokens := exercise.Tokenize(text)
+
+	japanese := false
+	for _, t := range tokens {
+		if t.Script == exercise.ScriptHiragana || t.Script == exercise.ScriptKatakana {
+			japanese = true
+			break
+		}
+	}
+
+	var words []string
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if !t.Word {
+			continue
+		}
+		if !japanese {
+			if t.Script == exercise.ScriptHan {
+				words = append(words, exercise.SegmentChinese(t.Text)...)
+			} else {
+				words = append(words, t.Text)
+			}
+			continue
+		}
+
+		switch t.Script {
+		case exercise.ScriptHan:
+			word := t.Text
+			// 緊接在後的平假名：前段是送假名，其餘當成獨立的平假名段
+			if i+1 < len(tokens) && tokens[i+1].Script == exercise.ScriptHiragana {
+				i++
+				okurigana, rest := splitOkurigana(tokens[i].Text)
+				word += okurigana
+				words = append(words, word)
+				words = append(words, splitParticles(rest)...)
+				continue
+			}
+			words = append(words, word)
+		case exercise.ScriptHiragana:
+			words = append(words, splitParticles(t.Text)...)
+		default:
+			words = append(words, t.Text)
+		}
+	}
+	return words
+}
+
+// splitOkurigana 在第一個助詞前切開
+func splitOkurigana(hiragana string) (okurigana, rest string) {
+	for i := range hiragana {
+		for _, p := range okuriganaStops {
+			if strings.HasPrefix(hiragana[i:], p) && isParticle(hiragana[:i], p, hiragana[i+len(p):]) {
+				return hiragana[:i], hiragana[i:]
+			}
+		}
+	}
+	return hiragana, ""
+}
+
+// isParticle 判斷 before 與 after 之間的 p 是否為助詞
+// で、に、と 也會出現在動詞活用中：ん／い 後的 で 是て形（死んで、泳いで），っ 後的 と 是副詞（ずっと），
+// 接 たい、ます 等語尾的 に 是動詞連用形（死にたい）；助詞後面也不會緊接 っ、ん 或拗音（もっと、がんばる）
+func isParticle(before, p, after string) bool {
+	if next, _ := utf8.DecodeRuneInString(after); strings.ContainsRune("っんゃゅょ", next) {
+		return false
+	}
+	prev, _ := utf8.DecodeLastRuneInString(before)
+	switch p {
+	case "で":
+		return prev != 'ん' && prev != 'い'
+	case "と":
+		return prev != 'っ' && prev != 'ん'
+	case "に":
+		if prev == 'ん' || prev == 'っ' {
+			return false
+		}
+		for _, suffix := range verbSuffixes {
+			if strings.HasPrefix(after, suffix) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitParticles 切出平假名段開頭的助詞，剩下的部分當成一個詞
+func splitParticles(hiragana string) []string {
+	var words []string
+	for hiragana != "" {
+		matched := false
+		for _, p := range leadingParticles {
+			if strings.HasPrefix(hiragana, p) && isParticle("", p, hiragana[len(p):]) {
+				words = append(words, p)
+				hiragana = hiragana[len(p):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			words = append(words, hiragana)
+			break
+		}
+	}
+	return words
+}
@@ -4,6 +4,7 @@ const state = {
     currentFile: null,
     lyrics: null,
     segments: null,
+    waveform: null,
     playlist: [],
     playlistIndex: 0,
     isPlaying: false,
@@ -15,6 +16,7 @@ const state = {
         ttsRepeat: 2,
         slowMode: false,
         showChinese: false,
+        singAlong: false,   // TTS 之後播放人聲減弱的伴奏，讓使用者自己唱
         shuffleMode: 'off'  // 'off' | 'playlist' | 'super'
     },
     practicePlaylist: [],    // 練習模式播放清單
@@ -23,7 +25,11 @@ const state = {
     _practiceReturnFileId: null, // 進入練習時的首頁選歌（離開練習要還原）
     // 歌單隨機模式的歌曲佇列
     shuffleQueue: [],        // 打亂後的歌曲 ID 列表
-    shuffleQueueIndex: 0     // 目前在佇列中的位置
+    shuffleQueueIndex: 0,    // 目前在佇列中的位置
+    // 練習紀錄
+    practiceSessionId: null, // 目前的練習紀錄 ID
+    practiceEvents: [],      // 尚未送出的練習事件
+    lastPlayedSegment: null  // 上一次播放的原曲段落（判斷是否為重複播放）
 };
 
 // ===== 預設設定（儲存在 localStorage）=====
@@ -32,7 +38,8 @@ const defaultSettings = {
     shuffleMode: 'playlist',  // 'off' | 'playlist' | 'super'
     ttsRepeat: 1,
     ttsVolumeMultiplier: 10,  // 10 = 1.0x, 40 = 4.0x (實際倍數 = 值/10)
-    showChinese: false
+    showChinese: false,
+    singAlong: false
 };
 
 // 從 localStorage 載入設定
@@ -70,8 +77,11 @@ const elements = {
     fileStatus: document.getElementById('fileStatus'),
     fileDuration: document.getElementById('fileDuration'),
     fileLyricCount: document.getElementById('fileLyricCount'),
+    waveformCanvas: document.getElementById('waveformCanvas'),
     languageSelect: document.getElementById('languageSelect'),
     showChinese: document.getElementById('showChinese'),
+    extractVocabulary: document.getElementById('extractVocabulary'),
+    romanize: document.getElementById('romanize'),
     autoDetectBtn: document.getElementById('autoDetectBtn'),
     lyricsContainer: document.getElementById('lyricsContainer'),
     processBtn: document.getElementById('processBtn'),
@@ -105,6 +115,7 @@ const elements = {
     practiceNextBtn: document.getElementById('practiceNextBtn'),
     practiceLoop: document.getElementById('practiceLoop'),
     practiceShowChinese: document.getElementById('practiceShowChinese'),
+    practiceSingAlong: document.getElementById('practiceSingAlong'),
     practiceShuffleMode: document.getElementById('practiceShuffleMode'),
     retranslateBtn: document.getElementById('retranslateBtn'),
     // 隨機模式資訊
@@ -135,10 +146,11 @@ const api = {
         return data.files || [];
     },
 
-    async uploadFile(file) {
+    async uploadFile(file, onDuplicate = '') {
         const formData = new FormData();
         formData.append('file', file);
-        const res = await fetch('/api/files/upload', {
+        const query = onDuplicate ? `?onDuplicate=${onDuplicate}` : '';
+        const res = await fetch(`/api/files/upload${query}`, {
             method: 'POST',
             body: formData
         });
@@ -186,10 +198,33 @@ const api = {
         return await res.json();
     },
 
+    async getWaveform(id, points) {
+        const res = await fetch(`/api/files/${id}/waveform?points=${points}`);
+        if (!res.ok) throw new Error('無法載入波形');
+        return await res.json();
+    },
+
     async exportFile(id) {
         await fetch(`/api/files/${id}/export`, { method: 'POST' });
     },
 
+    async startSession(mode) {
+        const res = await fetch('/api/sessions', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ mode })
+        });
+        return await res.json();
+    },
+
+    async addSessionEvents(sessionId, events) {
+        await fetch(`/api/sessions/${sessionId}/events`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ events })
+        });
+    },
+
     async retranslateSegment(id, segmentIndex, userInput) {
         const res = await fetch(`/api/files/${id}/segments/${segmentIndex}/retranslate`, { 
             method: 'POST',
@@ -288,6 +323,8 @@ function renderLyrics() {
                 <span class="lyric-timestamp">[${line.timestamp}]</span>
                 <div class="lyric-content">
                     <div class="lyric-original">${line.original || '♪'}</div>
+                    ${line.romanization ?
+                        `<div class="lyric-romanization">${line.romanization}</div>` : ''}
                     ${zhTranslation ? 
                         `<div class="lyric-translation lyric-zh">📝 ${zhTranslation}</div>` : ''}
                     ${enTranslation ? 
@@ -336,6 +373,7 @@ function renderCurrentLyric(lyricData) {
 
     elements.currentLyric.innerHTML = `
         <div class="lyric-original">${lyricData.original || '♪'}</div>
+        ${lyricData.romanization ? `<div class="lyric-romanization">${lyricData.romanization}</div>` : ''}
         <div class="lyric-translation">${translation || '--'}</div>
         ${chineseHtml}
     `;
@@ -389,11 +427,14 @@ async function selectFile(id) {
         elements.languageSelect.value = file.settings.primaryLanguage || 'en';
         elements.repeatCount.value = file.settings.ttsRepeatCount || 2;
         elements.showChinese.checked = file.settings.showChineseTranslation !== false;
+        elements.extractVocabulary.checked = !!file.settings.extractVocabulary;
+        elements.romanize.checked = !!file.settings.romanize;
         state.startLineIndex = file.settings.startLineIndex || 0;
     }
 
-    // 載入歌詞
+    // 載入歌詞與波形
     await loadLyrics(id);
+    loadWaveform(id);
 
     // 更新按鈕狀態
     elements.practiceBtn.disabled = file.status !== 'ready';
@@ -413,6 +454,83 @@ async function loadFile(id) {
     }
 }
 
+// ===== 波形 =====
+async function loadWaveform(id) {
+    const canvas = elements.waveformCanvas;
+    if (!canvas) return;
+    state.waveform = null;
+    drawWaveform();
+    try {
+        // 每個實體像素一個點
+        const points = Math.min(Math.round(canvas.clientWidth * (window.devicePixelRatio || 1)), 10000);
+        const waveform = await api.getWaveform(id, points || 1000);
+        if (state.currentFile?.id === id) {
+            state.waveform = waveform;
+            drawWaveform();
+        }
+    } catch (e) {
+        console.error('Failed to load waveform:', e);
+    }
+}
+
+function drawWaveform() {
+    const canvas = elements.waveformCanvas;
+    if (!canvas) return;
+    const ratio = window.devicePixelRatio || 1;
+    const width = canvas.clientWidth * ratio;
+    const height = canvas.clientHeight * ratio;
+    canvas.width = width;
+    canvas.height = height;
+    const ctx = canvas.getContext('2d');
+    ctx.clearRect(0, 0, width, height);
+
+    const waveform = state.waveform;
+    if (!waveform || !waveform.points || !waveform.duration) return;
+    const x = time => time / waveform.duration * width;
+
+    // 段落範圍
+    ctx.fillStyle = 'rgba(99, 102, 241, 0.15)';
+    (waveform.segments || []).forEach((seg, i) => {
+        if (i % 2 === 0) ctx.fillRect(x(seg.start), 0, x(seg.end) - x(seg.start), height);
+    });
+
+    // 峰值
+    const mid = height / 2;
+    const step = width / waveform.points;
+    ctx.fillStyle = '#94a3b8';
+    for (let i = 0; i < waveform.points; i++) {
+        const lo = waveform.peaks[i * 2];
+        const hi = waveform.peaks[i * 2 + 1];
+        ctx.fillRect(i * step, mid - hi * mid, Math.max(step, 1), Math.max((hi - lo) * mid, 1));
+    }
+
+    // 歌詞標記（起始行之前的用淡色）
+    (waveform.markers || []).forEach(marker => {
+        ctx.fillStyle = marker.skipped ? 'rgba(245, 158, 11, 0.3)' : '#f59e0b';
+        ctx.fillRect(x(marker.time), 0, ratio, 6 * ratio);
+    });
+
+    // 播放位置
+    const player = elements.audioPlayer;
+    if (player.src.endsWith(`/api/files/${state.currentFile?.id}/audio`)) {
+        ctx.fillStyle = '#f8fafc';
+        ctx.fillRect(x(player.currentTime), 0, ratio, height);
+    }
+}
+
+function seekWaveform(event) {
+    const waveform = state.waveform;
+    if (!waveform || !state.currentFile) return;
+    const rect = elements.waveformCanvas.getBoundingClientRect();
+    const time = (event.clientX - rect.left) / rect.width * waveform.duration;
+    const src = `/api/files/${state.currentFile.id}/audio`;
+    if (!elements.audioPlayer.src.endsWith(src)) {
+        elements.audioPlayer.src = src;
+    }
+    elements.audioPlayer.currentTime = time;
+    elements.audioPlayer.play();
+}
+
 async function loadLyrics(id) {
     try {
         state.lyrics = await api.getLyrics(id);
@@ -433,9 +551,33 @@ async function setStartLine(index) {
     }
 }
 
-async function handleUpload(file) {
-    const result = await api.uploadFile(file);
-    state.files.push(result);
+async function handleUpload(file, onDuplicate = '') {
+    const result = await api.uploadFile(file, onDuplicate);
+    if (result.error) {
+        if (result.existingId) {
+            const existing = result.existing || {};
+            const name = existing.title || existing.filename || result.existingId;
+            const choice = prompt(
+                `${result.error}\n既有檔案: ${name}\n\n` +
+                '請選擇處理方式：\n1 = 使用既有檔案\n2 = 取代既有檔案\n3 = 兩者都保留',
+                '1'
+            );
+            const policy = { '1': 'link', '2': 'replace', '3': 'keep' }[(choice || '').trim()];
+            if (policy) {
+                await handleUpload(file, policy);
+            }
+            return;
+        }
+        alert(`上傳失敗: ${result.error}`);
+        return;
+    }
+
+    const index = state.files.findIndex(f => f.id === result.id);
+    if (index >= 0) {
+        state.files[index] = result;
+    } else {
+        state.files.push(result);
+    }
     renderFileList();
     selectFile(result.id);
 }
@@ -478,6 +620,8 @@ async function handleProcess() {
         primaryLanguage: elements.languageSelect.value,
         ttsRepeatCount: 2, // 預設
         showChineseTranslation: elements.showChinese.checked,
+        extractVocabulary: elements.extractVocabulary.checked,
+        romanize: elements.romanize.checked,
         startLineIndex: state.startLineIndex
     });
 
@@ -492,6 +636,9 @@ async function handleProcess() {
             
             if (progress.status !== 'done' && progress.status !== 'error') {
                 setTimeout(pollProgress, 1000);
+            } else if (progress.status === 'done') {
+                // 重新載入波形以顯示新的段落邊界
+                loadWaveform(state.currentFile.id);
             }
         } catch (e) {
             console.error('Error polling progress:', e);
@@ -685,6 +832,58 @@ async function playNextPracticeQueueSong(autoplay) {
     await loadPracticeSong(nextId, autoplay);
 }
 
+// ===== 練習紀錄 =====
+// 事件先存在前端，累積一定數量或離開練習時再一起送出
+const PRACTICE_EVENT_BATCH = 20;
+
+async function startPracticeSession(mode) {
+    state.practiceSessionId = null;
+    state.practiceEvents = [];
+    state.lastPlayedSegment = null;
+    try {
+        const session = await api.startSession(mode);
+        state.practiceSessionId = session.id || null;
+    } catch (e) {
+        console.error('Failed to start practice session:', e);
+    }
+}
+
+function recordPracticeEvent(type, item) {
+    if (!state.practiceMode || !item || !state.currentFile) return;
+    state.practiceEvents.push({
+        type,
+        fileId: state.currentFile.id,
+        segmentIndex: item.segment?.index ?? item.segmentIndex,
+        at: new Date().toISOString()
+    });
+    if (state.practiceEvents.length >= PRACTICE_EVENT_BATCH) {
+        flushPracticeEvents();
+    }
+}
+
+function flushPracticeEvents() {
+    if (!state.practiceSessionId || state.practiceEvents.length === 0) return;
+    const events = state.practiceEvents;
+    state.practiceEvents = [];
+    api.addSessionEvents(state.practiceSessionId, events).catch(e => {
+        console.error('Failed to record practice events:', e);
+    });
+}
+
+// 結束練習紀錄；頁面關閉時改用 sendBeacon 才送得出去
+function endPracticeSession() {
+    if (!state.practiceSessionId) return;
+    const url = `/api/sessions/${state.practiceSessionId}/end`;
+    const body = JSON.stringify({ events: state.practiceEvents });
+    state.practiceSessionId = null;
+    state.practiceEvents = [];
+    if (navigator.sendBeacon) {
+        navigator.sendBeacon(url, body);
+    } else {
+        fetch(url, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body, keepalive: true });
+    }
+}
+
 async function enterPracticeMode() {
     if (!state.currentFile) return;
 
@@ -715,6 +914,7 @@ async function enterPracticeMode() {
     
     // 切換到練習模式
     state.practiceMode = true;
+    startPracticeSession(defaultSettings.shuffleMode);
     elements.editMode.style.display = 'none';
     elements.practiceMode.style.display = 'flex';
     elements.backToEditBtn.style.display = 'block';
@@ -733,12 +933,14 @@ async function enterPracticeMode() {
     state.practiceSettings.ttsRepeat = defaultSettings.ttsRepeat;
     state.practiceSettings.slowMode = false;
     state.practiceSettings.showChinese = defaultSettings.showChinese;
+    state.practiceSettings.singAlong = defaultSettings.singAlong;
     state.practiceSettings.shuffleMode = defaultSettings.shuffleMode;
     
     // 套用預設設定到 UI
     if (elements.practiceLoop) elements.practiceLoop.checked = defaultSettings.loop;
     if (elements.practiceShuffleMode) elements.practiceShuffleMode.value = defaultSettings.shuffleMode;
     if (elements.practiceShowChinese) elements.practiceShowChinese.checked = defaultSettings.showChinese;
+    if (elements.practiceSingAlong) elements.practiceSingAlong.checked = defaultSettings.singAlong;
     // TTS 音量倍數（slider 存的是 10=1.0x, 40=4.0x）
     if (elements.ttsVolume) elements.ttsVolume.value = defaultSettings.ttsVolumeMultiplier;
     if (elements.volumeValue) elements.volumeValue.textContent = formatVolumeMultiplier(defaultSettings.ttsVolumeMultiplier);
@@ -785,6 +987,7 @@ async function enterPracticeMode() {
 }
 
 async function exitPracticeMode() {
+    endPracticeSession();
     state.practiceMode = false;
     state.shuffleMode = false; // 重置隨機模式（舊狀態）
     state.shuffleQueue = [];
@@ -822,6 +1025,7 @@ async function startPractice() {
     state.practiceSettings.ttsRepeat = parseInt(ttsRepeatRadio?.value || 2);
     state.practiceSettings.slowMode = slowModeRadio?.value === 'slow';
     state.practiceSettings.showChinese = elements.practiceShowChinese?.checked || false;
+    state.practiceSettings.singAlong = elements.practiceSingAlong?.checked || false;
     state.practiceSettings.shuffleMode = elements.practiceShuffleMode?.value || 'off';
     
     // 切換到播放器
@@ -885,6 +1089,9 @@ function buildPracticePlaylist(singleSegmentIndex = null) {
         
         // 取得中文翻譯 (從歌詞資料)
         const textZh = segmentLyrics.map(l => l.translations?.zh || l.translations?.embedded || '').filter(Boolean).join(' ');
+
+        // 原文的羅馬拼音（播放原曲時顯示在原文下方）
+        const textRoman = segmentLyrics.map(l => l.romanization || '').filter(Boolean).join(' ');
         
         // 1. 原曲段落
         state.practicePlaylist.push({
@@ -895,7 +1102,8 @@ function buildPracticePlaylist(singleSegmentIndex = null) {
             label: '🎵 原曲',
             textJa: segment.originalText || '',  // 使用 segments.json 的 originalText
             textEn: segment.ttsText || '',       // 使用 segments.json 的 ttsText (英文翻譯)
-            textZh: textZh
+            textZh: textZh,
+            textRoman: textRoman
         });
         
         // 2. TTS 第一次 (原速)
@@ -908,7 +1116,8 @@ function buildPracticePlaylist(singleSegmentIndex = null) {
             label: '🗣️ TTS 英文',
             textJa: segment.originalText || '',
             textEn: segment.ttsText || '',
-            textZh: textZh
+            textZh: textZh,
+            textRoman: textRoman
         });
         
         // 3. TTS 第二次 (如果設定為 2 次)
@@ -922,7 +1131,23 @@ function buildPracticePlaylist(singleSegmentIndex = null) {
                 label: state.practiceSettings.slowMode ? '🗣️ TTS (0.75x)' : '🗣️ TTS (重複)',
                 textJa: segment.originalText || '',
                 textEn: segment.ttsText || '',
-                textZh: textZh
+                textZh: textZh,
+                textRoman: textRoman
+            });
+        }
+
+        // 4. 跟唱：人聲減弱的原曲段落
+        if (state.practiceSettings.singAlong) {
+            state.practicePlaylist.push({
+                type: 'karaoke',
+                segmentIndex: segmentIndex,
+                segment: segment,
+                url: `/api/files/${state.currentFile.id}/segments/${segment.index}/karaoke`,
+                label: '🎤 跟唱',
+                textJa: segment.originalText || '',
+                textEn: segment.ttsText || '',
+                textZh: textZh,
+                textRoman: textRoman
             });
         }
     });
@@ -962,18 +1187,20 @@ function updatePracticeDisplay() {
     // 更新播放類型標籤
     elements.subtitleType.textContent = item.label;
     elements.subtitleType.className = 'subtitle-type';
-    if (item.type === 'original') {
+    // 原曲與伴奏都是歌曲本身：用原曲播放器並顯示原文
+    const isSong = item.type === 'original' || item.type === 'karaoke';
+    if (isSong) {
         elements.subtitleType.classList.add('type-original');
     } else {
         elements.subtitleType.classList.add('type-tts');
     }
     
     // 更新字幕
-    if (item.type === 'original') {
+    if (isSong) {
         // 播放原曲時顯示日文
         elements.subtitleMain.textContent = item.textJa || '--';
         elements.subtitleMain.className = 'subtitle-main lang-ja';
-        elements.subtitleSecondary.textContent = '';
+        elements.subtitleSecondary.textContent = item.textRoman || '';
     } else {
         // 播放 TTS 時顯示英文
         elements.subtitleMain.textContent = item.textEn || '--';
@@ -998,10 +1225,18 @@ function playCurrentPracticeItem() {
     if (!item) return;
     
     updatePracticeDisplay();
+
+    // 練習紀錄：每次播放原曲段落記一次，連續播放同一段落算重複
+    if (item.type === 'original') {
+        const key = `${state.currentFile?.id}:${item.segmentIndex}`;
+        recordPracticeEvent(state.lastPlayedSegment === key ? 'repeat' : 'play', item);
+        state.lastPlayedSegment = key;
+    }
     
     // 選擇播放器
-    const player = item.type === 'original' ? elements.audioPlayer : elements.ttsPlayer;
-    const otherPlayer = item.type === 'original' ? elements.ttsPlayer : elements.audioPlayer;
+    const isSong = item.type === 'original' || item.type === 'karaoke';
+    const player = isSong ? elements.audioPlayer : elements.ttsPlayer;
+    const otherPlayer = isSong ? elements.ttsPlayer : elements.audioPlayer;
     
     // 停止另一個播放器
     otherPlayer.pause();
@@ -1455,9 +1690,23 @@ elements.backToEditBtn?.addEventListener('click', exitPracticeMode);
 elements.startPracticeBtn?.addEventListener('click', startPractice);
 elements.practicePlayBtn?.addEventListener('click', togglePracticePlay);
 elements.practicePrevBtn?.addEventListener('click', practicePrev);
-elements.practiceNextBtn?.addEventListener('click', practiceNext);
+elements.practiceNextBtn?.addEventListener('click', () => {
+    // 播放中按下一個：記為跳過目前段落
+    if (state.isPlaying) {
+        recordPracticeEvent('skip', state.practicePlaylist[state.practiceIndex]);
+    }
+    practiceNext();
+});
+window.addEventListener('pagehide', () => {
+    if (state.practiceMode) endPracticeSession();
+});
 elements.retranslateBtn?.addEventListener('click', handleRetranslate);
 
+// 波形：點擊跳轉、播放時更新播放位置
+elements.waveformCanvas?.addEventListener('click', seekWaveform);
+elements.audioPlayer.addEventListener('timeupdate', drawWaveform);
+window.addEventListener('resize', drawWaveform);
+
 // 隨機練習模式按鈕
 elements.shufflePracticeBtn?.addEventListener('click', startShufflePractice);
 
@@ -1477,6 +1726,13 @@ elements.practiceShowChinese?.addEventListener('change', () => {
     updatePracticeDisplay();
 });
 
+// 跟唱切換（下一次建立播放清單時生效）
+elements.practiceSingAlong?.addEventListener('change', () => {
+    state.practiceSettings.singAlong = elements.practiceSingAlong.checked;
+    defaultSettings.singAlong = state.practiceSettings.singAlong;
+    saveSettings();
+});
+
 elements.languageSelect.addEventListener('change', () => {
     if (state.currentFile) {
         api.updateSettings(state.currentFile.id, {